@@ -0,0 +1,361 @@
+package tupleconv
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/tarantool/go-tarantool/v2/decimal"
+)
+
+// maxExactFloat is the maximal absolute value of integers, that are represented
+// by float64 exactly.
+const maxExactFloat = 1 << 53
+
+// AnyToTTConvFactory is a TTConvFactory for loosely typed values, decoded from
+// JSON or YAML: nil, bool, float64, json.Number, int, int64, uint64, string,
+// time.Time, []any, map[string]any and map[any]any.
+//
+// Integral numbers are converted to uint64, if they are not negative, or to int64.
+// Numbers, that can't be converted without precision loss, and numbers out of
+// the range of the field type are errors. Strings are parsed by the converters of
+// StringToTTConvFactory for fields of non-string types. Nested numbers of arrays
+// and maps are converted the same way as numbers of number fields.
+type AnyToTTConvFactory struct {
+	// strFac is the factory of the converters of strings.
+	strFac StringToTTConvFactory
+}
+
+// MakeAnyToTTConvFactory creates AnyToTTConvFactory, that parses strings by
+// the converters of the string factory.
+func MakeAnyToTTConvFactory(strFac StringToTTConvFactory) AnyToTTConvFactory {
+	return AnyToTTConvFactory{strFac: strFac}
+}
+
+var _ TTConvFactory[any] = (*AnyToTTConvFactory)(nil)
+
+// errNegativeUnsigned is returned for negative values of unsigned fields.
+var errNegativeUnsigned = errors.New("negative value for unsigned")
+
+// integerFromFloat converts the integral float64 to uint64, if it is not
+// negative, or to int64.
+func integerFromFloat(value float64) (any, error) {
+	if math.IsInf(value, 0) || math.IsNaN(value) || value != math.Trunc(value) {
+		return nil, fmt.Errorf("%v is not an integer", value)
+	}
+	if math.Abs(value) > maxExactFloat {
+		return nil, fmt.Errorf("%v can't be converted to integer without precision loss",
+			value)
+	}
+	if value >= 0 {
+		return uint64(value), nil
+	}
+	return int64(value), nil
+}
+
+// integerFromNumber converts the integral JSON number to uint64, if it is not
+// negative, or to int64.
+func integerFromNumber(value json.Number) (any, error) {
+	if result, err := strconv.ParseUint(string(value), 10, 64); err == nil {
+		return result, nil
+	} else if errors.Is(err, strconv.ErrRange) {
+		return nil, fmt.Errorf("%s is out of range", value)
+	}
+	if result, err := strconv.ParseInt(string(value), 10, 64); err == nil {
+		return result, nil
+	} else if errors.Is(err, strconv.ErrRange) {
+		return nil, fmt.Errorf("%s is out of range", value)
+	}
+	float, err := strconv.ParseFloat(string(value), 64)
+	if err != nil {
+		return nil, err
+	}
+	return integerFromFloat(float)
+}
+
+// toInteger converts the integral number to uint64, if it is not negative, or
+// to int64.
+func toInteger(value any, typ TypeName) (any, error) {
+	switch value := value.(type) {
+	case float64:
+		return integerFromFloat(value)
+	case json.Number:
+		return integerFromNumber(value)
+	case int:
+		return toInteger(int64(value), typ)
+	case int64:
+		if value >= 0 {
+			return uint64(value), nil
+		}
+		return value, nil
+	case uint64:
+		return value, nil
+	}
+	return nil, unexpectedValueError(value, typ)
+}
+
+// toDouble converts the number to float64.
+func toDouble(value any) (any, error) {
+	switch value := value.(type) {
+	case float64:
+		return value, nil
+	case json.Number:
+		return strconv.ParseFloat(string(value), 64)
+	case int:
+		return toDouble(int64(value))
+	case int64:
+		if value > maxExactFloat || value < -maxExactFloat {
+			return nil, fmt.Errorf("%d can't be converted to double without precision loss",
+				value)
+		}
+		return float64(value), nil
+	case uint64:
+		if value > maxExactFloat {
+			return nil, fmt.Errorf("%d can't be converted to double without precision loss",
+				value)
+		}
+		return float64(value), nil
+	}
+	return nil, unexpectedValueError(value, TypeDouble)
+}
+
+// toNumber converts the number to uint64 or int64, if it is integral and can be
+// converted without precision loss, or to float64.
+func toNumber(value any) (any, error) {
+	switch value := value.(type) {
+	case float64:
+		if result, err := integerFromFloat(value); err == nil {
+			return result, nil
+		}
+		return value, nil
+	case json.Number:
+		if result, err := integerFromNumber(value); err == nil {
+			return result, nil
+		}
+		float, err := strconv.ParseFloat(string(value), 64)
+		if err != nil {
+			return nil, err
+		}
+		return toNumber(float)
+	case int, int64, uint64:
+		return toInteger(value, TypeNumber)
+	}
+	return nil, unexpectedValueError(value, TypeNumber)
+}
+
+// normalizeNested converts numbers of the array or the map recursively, the same
+// way as numbers of number fields.
+func normalizeNested(value any) (any, error) {
+	var err error
+	switch value := value.(type) {
+	case float64, json.Number, int, int64, uint64:
+		return toNumber(value)
+	case []any:
+		result := make([]any, len(value))
+		for i, item := range value {
+			if result[i], err = normalizeNested(item); err != nil {
+				return nil, err
+			}
+		}
+		return result, nil
+	case map[string]any:
+		result := make(map[string]any, len(value))
+		for key, item := range value {
+			if result[key], err = normalizeNested(item); err != nil {
+				return nil, err
+			}
+		}
+		return result, nil
+	case map[any]any:
+		result := make(map[any]any, len(value))
+		for key, item := range value {
+			if result[key], err = normalizeNested(item); err != nil {
+				return nil, err
+			}
+		}
+		return result, nil
+	}
+	return value, nil
+}
+
+// makeAnyConverter makes a converter from any, that converts strings with
+// the string converter and other values with the function.
+func makeAnyConverter(strConv Converter[string, any],
+	convFunc func(any) (any, error)) Converter[any, any] {
+	return MakeFuncConverter(func(value any) (any, error) {
+		if str, ok := value.(string); ok {
+			return strConv.Convert(str)
+		}
+		return convFunc(value)
+	})
+}
+
+func (fac AnyToTTConvFactory) GetBooleanConverter() Converter[any, any] {
+	return makeAnyConverter(fac.strFac.GetBooleanConverter(), func(value any) (any, error) {
+		if boolean, ok := value.(bool); ok {
+			return boolean, nil
+		}
+		return nil, unexpectedValueError(value, TypeBoolean)
+	})
+}
+
+func (AnyToTTConvFactory) GetStringConverter() Converter[any, any] {
+	return makeAnyConverter(MakeIdentityConverter[string](), func(value any) (any, error) {
+		return nil, unexpectedValueError(value, TypeString)
+	})
+}
+
+func (fac AnyToTTConvFactory) GetUnsignedConverter() Converter[any, any] {
+	return makeAnyConverter(fac.strFac.GetUnsignedConverter(), func(value any) (any, error) {
+		result, err := toInteger(value, TypeUnsigned)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := result.(int64); ok {
+			return nil, errNegativeUnsigned
+		}
+		return result, nil
+	})
+}
+
+func (fac AnyToTTConvFactory) GetDatetimeConverter() Converter[any, any] {
+	return makeAnyConverter(fac.strFac.GetDatetimeConverter(), func(value any) (any, error) {
+		if tm, ok := value.(time.Time); ok {
+			return makeDatetime(tm)
+		}
+		return nil, unexpectedValueError(value, TypeDatetime)
+	})
+}
+
+func (fac AnyToTTConvFactory) GetUUIDConverter() Converter[any, any] {
+	return makeAnyConverter(fac.strFac.GetUUIDConverter(), func(value any) (any, error) {
+		return nil, unexpectedValueError(value, TypeUUID)
+	})
+}
+
+func (fac AnyToTTConvFactory) GetMapConverter() Converter[any, any] {
+	return MakeFuncConverter(func(value any) (any, error) {
+		if str, ok := value.(string); ok {
+			var err error
+			if value, err = fac.strFac.GetMapConverter().Convert(str); err != nil {
+				return nil, err
+			}
+		}
+		switch value.(type) {
+		case map[string]any, map[any]any:
+			return normalizeNested(value)
+		}
+		return nil, unexpectedValueError(value, TypeMap)
+	})
+}
+
+func (fac AnyToTTConvFactory) GetArrayConverter() Converter[any, any] {
+	return MakeFuncConverter(func(value any) (any, error) {
+		if str, ok := value.(string); ok {
+			var err error
+			if value, err = fac.strFac.GetArrayConverter().Convert(str); err != nil {
+				return nil, err
+			}
+		}
+		if _, ok := value.([]any); ok {
+			return normalizeNested(value)
+		}
+		return nil, unexpectedValueError(value, TypeArray)
+	})
+}
+
+func (AnyToTTConvFactory) GetVarbinaryConverter() Converter[any, any] {
+	return makeAnyConverter(MakeStringToBinaryConverter(), func(value any) (any, error) {
+		if bytes, ok := value.([]byte); ok {
+			return bytes, nil
+		}
+		return nil, unexpectedValueError(value, TypeVarbinary)
+	})
+}
+
+func (fac AnyToTTConvFactory) GetDoubleConverter() Converter[any, any] {
+	return makeAnyConverter(fac.strFac.GetDoubleConverter(), toDouble)
+}
+
+func (fac AnyToTTConvFactory) GetDecimalConverter() Converter[any, any] {
+	return makeAnyConverter(fac.strFac.GetDecimalConverter(), func(value any) (any, error) {
+		switch value := value.(type) {
+		case float64:
+			if math.IsInf(value, 0) || math.IsNaN(value) {
+				return nil, fmt.Errorf("%v can't be converted to decimal", value)
+			}
+			return decimal.MakeDecimalFromString(strconv.FormatFloat(value, 'g', -1, 64))
+		case json.Number:
+			return decimal.MakeDecimalFromString(string(value))
+		case int, int64, uint64:
+			return decimal.MakeDecimalFromString(fmt.Sprint(value))
+		}
+		return nil, unexpectedValueError(value, TypeDecimal)
+	})
+}
+
+func (fac AnyToTTConvFactory) GetIntegerConverter() Converter[any, any] {
+	return makeAnyConverter(fac.strFac.GetIntegerConverter(), func(value any) (any, error) {
+		return toInteger(value, TypeInteger)
+	})
+}
+
+func (fac AnyToTTConvFactory) GetNumberConverter() Converter[any, any] {
+	return makeAnyConverter(fac.strFac.GetNumberConverter(), toNumber)
+}
+
+func (fac AnyToTTConvFactory) GetIntervalConverter() Converter[any, any] {
+	return makeAnyConverter(fac.strFac.GetIntervalConverter(), func(value any) (any, error) {
+		return nil, unexpectedValueError(value, TypeInterval)
+	})
+}
+
+// convertScalar converts the scalar value by its dynamic type. Strings are not
+// parsed.
+func (AnyToTTConvFactory) convertScalar(value any, typ TypeName) (any, error) {
+	switch value := value.(type) {
+	case bool, string, []byte:
+		return value, nil
+	case float64, json.Number, int, int64, uint64:
+		return toNumber(value)
+	case time.Time:
+		return makeDatetime(value)
+	}
+	return nil, unexpectedValueError(value, typ)
+}
+
+// GetAnyConverter returns a converter from any to any. Arrays and maps are
+// converted like array and map fields, other values like scalar fields.
+func (fac AnyToTTConvFactory) GetAnyConverter() Converter[any, any] {
+	return MakeFuncConverter(func(value any) (any, error) {
+		switch value.(type) {
+		case []any, map[string]any, map[any]any:
+			return normalizeNested(value)
+		}
+		return fac.convertScalar(value, TypeAny)
+	})
+}
+
+// GetScalarConverter returns a converter from any to scalar. Strings are kept as
+// strings, numbers are converted like numbers of number fields.
+func (fac AnyToTTConvFactory) GetScalarConverter() Converter[any, any] {
+	return MakeFuncConverter(func(value any) (any, error) {
+		return fac.convertScalar(value, TypeScalar)
+	})
+}
+
+// MakeNullableConverter extends the converter to a converter, that converts nil
+// to nil. Null values of the string factory are not used.
+func (AnyToTTConvFactory) MakeNullableConverter(
+	converter Converter[any, any]) Converter[any, any] {
+	return MakeFuncContextConverter(func(ctx context.Context, value any) (any, error) {
+		if value == nil {
+			return nil, nil
+		}
+		return convertContext(ctx, converter, value)
+	})
+}
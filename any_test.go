@@ -0,0 +1,262 @@
+package tupleconv_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tarantool/go-tarantool/v2/datetime"
+	"github.com/tarantool/go-tarantool/v2/decimal"
+	"gopkg.in/yaml.v3"
+
+	"github.com/tarantool/go-tupleconv"
+)
+
+func TestAnyToTTConvFactory(t *testing.T) {
+	fac := tupleconv.MakeAnyToTTConvFactory(
+		tupleconv.MakeStringToTTConvFactory().WithDecimalSeparators(","))
+	dec, err := decimal.MakeDecimalFromString("1.25")
+	require.NoError(t, err)
+	bigDec, err := decimal.MakeDecimalFromString("123456789012345678901234567890")
+	require.NoError(t, err)
+	tm := time.Date(2023, 8, 30, 12, 6, 5, 0, time.UTC)
+	dt, err := datetime.MakeDatetime(tm)
+	require.NoError(t, err)
+
+	tests := []struct {
+		typ   tupleconv.TypeName
+		cases []convCase[any, any]
+	}{
+		{
+			typ: tupleconv.TypeBoolean,
+			cases: []convCase[any, any]{
+				{value: true, expected: true},
+				{value: "false", expected: false},
+				{value: float64(1), isErr: true},
+			},
+		},
+		{
+			typ: tupleconv.TypeString,
+			cases: []convCase[any, any]{
+				{value: "123", expected: "123"},
+				{value: float64(123), isErr: true},
+				{value: true, isErr: true},
+			},
+		},
+		{
+			typ: tupleconv.TypeUnsigned,
+			cases: []convCase[any, any]{
+				{value: float64(1), expected: uint64(1)},
+				{value: float64(1 << 53), expected: uint64(1 << 53)},
+				{value: json.Number("18446744073709551615"), expected: uint64(1<<64 - 1)},
+				{value: json.Number("1e3"), expected: uint64(1000)},
+				{value: 5, expected: uint64(5)},
+				{value: uint64(1 << 60), expected: uint64(1 << 60)},
+				{value: "42", expected: uint64(42)},
+				{value: float64(-1), isErr: true},
+				{value: float64(1.5), isErr: true},
+				{value: float64(1<<53 + 2), isErr: true}, // Precision loss.
+				{value: json.Number("18446744073709551616"), isErr: true},
+				{value: -5, isErr: true},
+				{value: true, isErr: true},
+			},
+		},
+		{
+			typ: tupleconv.TypeInteger,
+			cases: []convCase[any, any]{
+				{value: float64(-1), expected: int64(-1)},
+				{value: float64(1), expected: uint64(1)},
+				{value: json.Number("-9223372036854775808"), expected: int64(-1 << 63)},
+				{value: int64(-7), expected: int64(-7)},
+				{value: "-1", expected: int64(-1)},
+				{value: json.Number("-9223372036854775809"), isErr: true},
+				{value: float64(-1 << 60), isErr: true},
+				{value: json.Number("0.5"), isErr: true},
+			},
+		},
+		{
+			typ: tupleconv.TypeDouble,
+			cases: []convCase[any, any]{
+				{value: float64(1), expected: float64(1)},
+				{value: json.Number("1.5"), expected: 1.5},
+				{value: 3, expected: float64(3)},
+				{value: "2,5", expected: 2.5},
+				{value: uint64(1<<53 + 1), isErr: true},
+				{value: int64(-1<<53 - 1), isErr: true},
+			},
+		},
+		{
+			typ: tupleconv.TypeNumber,
+			cases: []convCase[any, any]{
+				{value: float64(1), expected: uint64(1)},
+				{value: float64(-1), expected: int64(-1)},
+				{value: 1.5, expected: 1.5},
+				{value: 1e300, expected: 1e300},
+				{value: json.Number("2"), expected: uint64(2)},
+				{value: json.Number("2.5"), expected: 2.5},
+				{value: json.Number("1e20"), expected: 1e20},
+				{value: "3", expected: uint64(3)},
+				{value: true, isErr: true},
+			},
+		},
+		{
+			typ: tupleconv.TypeDecimal,
+			cases: []convCase[any, any]{
+				{value: 1.25, expected: dec},
+				{value: "1,25", expected: dec},
+				{value: json.Number("123456789012345678901234567890"), expected: bigDec},
+				{value: true, isErr: true},
+			},
+		},
+		{
+			typ: tupleconv.TypeDatetime,
+			cases: []convCase[any, any]{
+				{value: tm, expected: dt},
+				{value: "2023", isErr: true},
+				{value: float64(1), isErr: true},
+			},
+		},
+		{
+			typ: tupleconv.TypeUUID,
+			cases: []convCase[any, any]{
+				{
+					value:    "09b56913-11f0-4fa4-b5d0-901b5efa532a",
+					expected: uuid.MustParse("09b56913-11f0-4fa4-b5d0-901b5efa532a"),
+				},
+				{value: float64(1), isErr: true},
+			},
+		},
+		{
+			typ: tupleconv.TypeInterval,
+			cases: []convCase[any, any]{
+				{value: "1,0,0,0,0,0,0,0,0", expected: datetime.Interval{Year: 1}},
+				{value: float64(1), isErr: true},
+			},
+		},
+		{
+			typ: tupleconv.TypeArray,
+			cases: []convCase[any, any]{
+				{value: []any{float64(1), 1.5, "a"}, expected: []any{uint64(1), 1.5, "a"}},
+				{value: `[1, [2]]`, expected: []any{uint64(1), []any{uint64(2)}}},
+				{value: map[string]any{}, isErr: true},
+				{value: `{}`, isErr: true},
+			},
+		},
+		{
+			typ: tupleconv.TypeMap,
+			cases: []convCase[any, any]{
+				{
+					value:    map[string]any{"a": []any{float64(-1)}},
+					expected: map[string]any{"a": []any{int64(-1)}},
+				},
+				{
+					value:    map[any]any{1: json.Number("2")},
+					expected: map[any]any{1: uint64(2)},
+				},
+				{value: `{"a": 1}`, expected: map[string]any{"a": uint64(1)}},
+				{value: []any{}, isErr: true},
+			},
+		},
+		{
+			typ: tupleconv.TypeVarbinary,
+			cases: []convCase[any, any]{
+				{value: "bin", expected: []byte("bin")},
+				{value: []byte{0}, expected: []byte{0}},
+				{value: float64(1), isErr: true},
+			},
+		},
+		{
+			typ: tupleconv.TypeScalar,
+			cases: []convCase[any, any]{
+				{value: "123", expected: "123"},
+				{value: float64(123), expected: uint64(123)},
+				{value: false, expected: false},
+				{value: tm, expected: dt},
+				{value: []any{}, isErr: true},
+			},
+		},
+		{
+			typ: tupleconv.TypeAny,
+			cases: []convCase[any, any]{
+				{value: "123", expected: "123"},
+				{value: float64(-2), expected: int64(-2)},
+				{value: []any{float64(1)}, expected: []any{uint64(1)}},
+				{value: struct{}{}, isErr: true},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(string(test.typ), func(t *testing.T) {
+			conv, err := tupleconv.GetConverterByType[any](fac, test.typ)
+			require.NoError(t, err)
+			HelperTestConverter(t, conv, test.cases)
+		})
+	}
+}
+
+func TestAnyToTTConvFactory_errors(t *testing.T) {
+	fac := tupleconv.MakeAnyToTTConvFactory(tupleconv.MakeStringToTTConvFactory())
+
+	_, err := fac.GetUnsignedConverter().Convert(float64(1<<53 + 2))
+	assert.EqualError(t, err,
+		"9.007199254740994e+15 can't be converted to integer without precision loss")
+	_, err = fac.GetUnsignedConverter().Convert(float64(-1))
+	assert.EqualError(t, err, "negative value for unsigned")
+	_, err = fac.GetIntegerConverter().Convert(json.Number("18446744073709551616"))
+	assert.EqualError(t, err, "18446744073709551616 is out of range")
+	_, err = fac.GetIntegerConverter().Convert(1.5)
+	assert.EqualError(t, err, "1.5 is not an integer")
+	_, err = fac.GetStringConverter().Convert(float64(1))
+	assert.EqualError(t, err, "unexpected value 1 of type float64 for string")
+}
+
+func TestAnyToTTConvFactory_mapper(t *testing.T) {
+	spaceFmt := []tupleconv.SpaceField{
+		{Name: "id", Type: tupleconv.TypeUnsigned},
+		{Name: "name", Type: tupleconv.TypeString},
+		{Name: "balance", Type: tupleconv.TypeDecimal, IsNullable: true},
+		{Name: "created", Type: tupleconv.TypeDatetime},
+		{Name: "tags", Type: tupleconv.TypeArray},
+	}
+	fac := tupleconv.MakeAnyToTTConvFactory(tupleconv.MakeStringToTTConvFactory())
+	converters, err := tupleconv.MakeTypeToTTConverters[any](fac, spaceFmt)
+	require.NoError(t, err)
+	mapper, err := tupleconv.MakeNamedMapper(tupleconv.MakeMapper(converters), spaceFmt)
+	require.NoError(t, err)
+
+	var row map[string]any
+	decoder := json.NewDecoder(bytes.NewBufferString(`{"id": 1, "name": "Alice",
+		"balance": 10.5, "created": "2023-08-30T12:06:05.000-0000", "tags": [1, "a"]}`))
+	decoder.UseNumber()
+	require.NoError(t, decoder.Decode(&row))
+	tuple, err := mapper.MapNamed(row)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), tuple[0])
+	assert.Equal(t, "Alice", tuple[1])
+	assert.IsType(t, decimal.Decimal{}, tuple[2])
+	assert.IsType(t, datetime.Datetime{}, tuple[3])
+	assert.Equal(t, []any{uint64(1), "a"}, tuple[4])
+
+	row = nil
+	require.NoError(t, yaml.Unmarshal([]byte(`
+id: 2
+name: Bob
+balance: null
+created: 2023-08-30T12:06:05Z
+tags: []
+`), &row))
+	tuple, err = mapper.MapNamed(row)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), tuple[0])
+	assert.Nil(t, tuple[2])
+	assert.IsType(t, datetime.Datetime{}, tuple[3])
+
+	row["id"] = -2
+	_, err = mapper.MapNamed(row)
+	assert.EqualError(t, err, `field "id": unexpected value -2 for type "unsigned"`)
+}
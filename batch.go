@@ -0,0 +1,220 @@
+package tupleconv
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// RowError is an error of mapping a tuple in a batch. Errors of reading records
+// from inputs are RecordError.
+type RowError struct {
+	// Row is the index of the tuple in the batch, starting from 0.
+	Row int
+	// Err is the mapping error.
+	Err error
+}
+
+// Error is the implementation of error for RowError.
+func (err *RowError) Error() string {
+	return fmt.Sprintf("row %d: %s", err.Row, err.Err)
+}
+
+// Unwrap returns the mapping error.
+func (err *RowError) Unwrap() error {
+	return err.Err
+}
+
+// RecordError is an error of reading a record from an input, for example, a row
+// of SQL rows or a line of PostgreSQL COPY data.
+type RecordError struct {
+	// Record is the number of the record in the input, starting from 1.
+	Record int
+	// Err is the reading or conversion error.
+	Err error
+}
+
+// Error is the implementation of error for RecordError.
+func (err *RecordError) Error() string {
+	return fmt.Sprintf("record %d: %s", err.Record, err.Err)
+}
+
+// Unwrap returns the reading or conversion error.
+func (err *RecordError) Unwrap() error {
+	return err.Err
+}
+
+// BatchError is a list of errors of mapping tuples in a batch, sorted by rows.
+type BatchError struct {
+	// Errors are errors of the failed rows.
+	Errors []*RowError
+}
+
+// Error is the implementation of error for BatchError.
+func (err *BatchError) Error() string {
+	messages := make([]string, 0, len(err.Errors))
+	for _, rowErr := range err.Errors {
+		messages = append(messages, rowErr.Error())
+	}
+	return fmt.Sprintf("%d rows failed: %s", len(err.Errors), strings.Join(messages, "; "))
+}
+
+// BatchMapper maps batches of tuples with a pool of workers and keeps the order
+// of the tuples in the result. The converters of the mapper are called
+// concurrently, so they must be safe for concurrent use. All converters of the
+// package are.
+type BatchMapper[S any, T any] struct {
+	mapper        Mapper[S, T]
+	workers       int
+	collectErrors bool
+}
+
+// MakeBatchMapper creates BatchMapper with runtime.GOMAXPROCS(0) workers, that
+// stops on the first error.
+func MakeBatchMapper[S any, T any](mapper Mapper[S, T]) BatchMapper[S, T] {
+	return BatchMapper[S, T]{mapper: mapper, workers: runtime.GOMAXPROCS(0)}
+}
+
+// WithWorkers sets the number of workers. Tuples are mapped in the calling
+// goroutine if it is less than 2.
+func (mapper BatchMapper[S, T]) WithWorkers(workers int) BatchMapper[S, T] {
+	mapper.workers = workers
+	return mapper
+}
+
+// WithCollectErrors sets whether to map all tuples and collect the errors of the
+// failed ones to BatchError instead of stopping on the first error.
+func (mapper BatchMapper[S, T]) WithCollectErrors(collect bool) BatchMapper[S, T] {
+	mapper.collectErrors = collect
+	return mapper
+}
+
+// MapBatch maps the tuples. The i-th result is the mapped i-th tuple.
+//
+// If the errors are not collected, the error of the first failed tuple in the
+// batch order is returned as *RowError, the same as with the sequential mapping.
+// Otherwise, the results of the failed tuples are nil, and their errors are
+// returned as *BatchError along with the results.
+//
+// The context is passed to the converters, that implement ContextConverter,
+// with the index of the tuple, returned by BatchRowFromContext. Mapping stops
+// when the context is done, the context error is returned then.
+func (mapper BatchMapper[S, T]) MapBatch(ctx context.Context, tuples [][]S) ([][]T, error) {
+	results := make([][]T, len(tuples))
+	state := batchState{failedRow: int64(len(tuples)), size: len(tuples)}
+
+	workers := mapper.workers
+	if workers > len(tuples) {
+		workers = len(tuples)
+	}
+	if workers < 2 {
+		mapper.mapRows(ctx, tuples, results, &state)
+	} else {
+		var wg sync.WaitGroup
+		wg.Add(workers)
+		for i := 0; i < workers; i++ {
+			go func() {
+				defer wg.Done()
+				mapper.mapRows(ctx, tuples, results, &state)
+			}()
+		}
+		wg.Wait()
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if len(state.errors) == 0 {
+		return results, nil
+	}
+	sort.Slice(state.errors, func(i, j int) bool {
+		return state.errors[i].Row < state.errors[j].Row
+	})
+	if !mapper.collectErrors {
+		return nil, state.errors[0]
+	}
+	return results, &BatchError{Errors: state.errors}
+}
+
+// batchState is the state of MapBatch, shared by the workers.
+type batchState struct {
+	// nextRow is the index of the next tuple to map.
+	nextRow int64
+	// failedRow is the minimal index of the failed tuples, if errors
+	// are not collected.
+	failedRow int64
+	// size is the number of tuples in the batch.
+	size int
+
+	mutex  sync.Mutex
+	errors []*RowError
+	// ranges are the first values of the ranges, reserved for the batch by keys.
+	ranges map[any]uint64
+}
+
+// reserveRange returns the first value of the range, reserved for the batch by
+// the key. The range is reserved by the function once per batch.
+func (state *batchState) reserveRange(key any, reserve func(size uint64) uint64) uint64 {
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+	first, ok := state.ranges[key]
+	if !ok {
+		if state.ranges == nil {
+			state.ranges = make(map[any]uint64)
+		}
+		first = reserve(uint64(state.size))
+		state.ranges[key] = first
+	}
+	return first
+}
+
+// batchRowKey is the context key of batchRow.
+type batchRowKey struct{}
+
+// batchRow is the tuple of the batch, that is being mapped.
+type batchRow struct {
+	state *batchState
+	row   int
+}
+
+// BatchRowFromContext returns the index of the tuple in the batch, starting from
+// 0, if the context is passed to the converters by BatchMapper.
+func BatchRowFromContext(ctx context.Context) (int, bool) {
+	row, ok := ctx.Value(batchRowKey{}).(batchRow)
+	return row.row, ok
+}
+
+// mapRows maps tuples until there are no tuples to map.
+func (mapper BatchMapper[S, T]) mapRows(ctx context.Context, tuples [][]S, results [][]T,
+	state *batchState) {
+	for {
+		row := atomic.AddInt64(&state.nextRow, 1) - 1
+		// Tuples before the failed one are mapped anyway to return the first error.
+		if row >= atomic.LoadInt64(&state.failedRow) || ctx.Err() != nil {
+			return
+		}
+		rowCtx := context.WithValue(ctx, batchRowKey{}, batchRow{state: state, row: int(row)})
+		result, err := mapper.mapper.MapContext(rowCtx, tuples[row])
+		if err == nil {
+			results[row] = result
+			continue
+		}
+
+		state.mutex.Lock()
+		state.errors = append(state.errors, &RowError{Row: int(row), Err: err})
+		state.mutex.Unlock()
+		if mapper.collectErrors {
+			continue
+		}
+		for {
+			failedRow := atomic.LoadInt64(&state.failedRow)
+			if row >= failedRow || atomic.CompareAndSwapInt64(&state.failedRow, failedRow, row) {
+				break
+			}
+		}
+	}
+}
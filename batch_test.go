@@ -0,0 +1,173 @@
+package tupleconv_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tarantool/go-tupleconv"
+)
+
+// makeBatchTestTuples makes tuples of msgpackTestFormat, that differ in the first
+// two fields.
+func makeBatchTestTuples(count int) [][]string {
+	tuples := make([][]string, count)
+	for i := range tuples {
+		tuple := append([]string{}, msgpackTestTuple...)
+		tuple[0] = fmt.Sprint(i)
+		tuple[1] = fmt.Sprint(-i)
+		tuples[i] = tuple
+	}
+	return tuples
+}
+
+func TestBatchMapper_MapBatch(t *testing.T) {
+	mapper := makeMsgpackTestMapper(t)
+	tuples := makeBatchTestTuples(1000)
+	expected := make([][]any, len(tuples))
+	for i, tuple := range tuples {
+		var err error
+		expected[i], err = mapper.Map(tuple)
+		require.NoError(t, err)
+	}
+
+	for _, workers := range []int{0, 1, 4, 16, 2000} {
+		t.Run(fmt.Sprintf("workers=%d", workers), func(t *testing.T) {
+			batchMapper := tupleconv.MakeBatchMapper(mapper).WithWorkers(workers)
+			result, err := batchMapper.MapBatch(context.Background(), tuples)
+			require.NoError(t, err)
+			assert.Equal(t, expected, result)
+		})
+	}
+
+	result, err := tupleconv.MakeBatchMapper(mapper).MapBatch(context.Background(), nil)
+	assert.NoError(t, err)
+	assert.Empty(t, result)
+}
+
+func TestBatchMapper_factoryOptions(t *testing.T) {
+	spaceFmt := []tupleconv.SpaceField{
+		{Name: "id", Type: tupleconv.TypeUnsigned},
+		{Name: "name", Type: tupleconv.TypeString, Collation: "unicode_ci"},
+		{Name: "amount", Type: tupleconv.TypeDecimal, IsNullable: true},
+		{Name: "created", Type: tupleconv.TypeDatetime, IsNullable: true},
+	}
+	fac := tupleconv.MakeStringToTTConvFactory().
+		WithThousandSeparators(" ").
+		WithDecimalSeparators(",").
+		WithNullValues("", "NULL").
+		WithDatetimeLayouts("02.01.2006").
+		WithNormalization(tupleconv.NormalizeOptions{Trim: true, FoldCase: true})
+	converters, err := tupleconv.MakeTypeToTTConverters[string](fac, spaceFmt)
+	require.NoError(t, err)
+	mapper := tupleconv.MakeMapper(converters)
+
+	tuples := make([][]string, 500)
+	for i := range tuples {
+		tuples[i] = []string{fmt.Sprintf("1 %03d", i), " NAME ", "1 000,5", "30.08.2023"}
+		if i%2 == 0 {
+			tuples[i][2], tuples[i][3] = "NULL", ""
+		}
+	}
+
+	result, err := tupleconv.MakeBatchMapper(mapper).WithWorkers(8).
+		MapBatch(context.Background(), tuples)
+	require.NoError(t, err)
+	for i, tuple := range result {
+		assert.Equal(t, uint64(1000+i), tuple[0])
+		assert.Equal(t, "name", tuple[1])
+		assert.Equal(t, i%2 == 0, tuple[2] == nil)
+		assert.Equal(t, i%2 == 0, tuple[3] == nil)
+	}
+}
+
+func TestBatchMapper_firstError(t *testing.T) {
+	tuples := makeBatchTestTuples(1000)
+	tuples[700][0] = "bad"
+	tuples[300][1] = "bad"
+
+	batchMapper := tupleconv.MakeBatchMapper(makeMsgpackTestMapper(t)).WithWorkers(8)
+	for i := 0; i < 10; i++ {
+		result, err := batchMapper.MapBatch(context.Background(), tuples)
+		assert.Nil(t, result)
+		var rowErr *tupleconv.RowError
+		require.True(t, errors.As(err, &rowErr))
+		assert.Equal(t, 300, rowErr.Row)
+		assert.EqualError(t, err, `row 300: unexpected value bad for type "integer"`)
+	}
+}
+
+func TestBatchMapper_collectErrors(t *testing.T) {
+	tuples := makeBatchTestTuples(100)
+	tuples[70][0] = "bad"
+	tuples[30][1] = "bad"
+
+	batchMapper := tupleconv.MakeBatchMapper(makeMsgpackTestMapper(t)).
+		WithWorkers(4).
+		WithCollectErrors(true)
+	result, err := batchMapper.MapBatch(context.Background(), tuples)
+	require.Len(t, result, len(tuples))
+	for i, tuple := range result {
+		assert.Equal(t, i == 30 || i == 70, tuple == nil)
+	}
+
+	var batchErr *tupleconv.BatchError
+	require.True(t, errors.As(err, &batchErr))
+	require.Len(t, batchErr.Errors, 2)
+	assert.Equal(t, 30, batchErr.Errors[0].Row)
+	assert.Equal(t, 70, batchErr.Errors[1].Row)
+	assert.EqualError(t, err, `2 rows failed: `+
+		`row 30: unexpected value bad for type "integer"; `+
+		`row 70: unexpected value bad for type "unsigned"`)
+}
+
+func TestBatchMapper_cancel(t *testing.T) {
+	var cancel context.CancelFunc
+	var converted int64
+	mapper := tupleconv.MakeMapper([]tupleconv.Converter[string, string]{
+		tupleconv.MakeFuncConverter(func(src string) (string, error) {
+			if atomic.AddInt64(&converted, 1) == 10 {
+				cancel()
+			}
+			return src, nil
+		}),
+	})
+	tuples := make([][]string, 1000)
+	for i := range tuples {
+		tuples[i] = []string{"a"}
+	}
+
+	for _, workers := range []int{1, 4} {
+		atomic.StoreInt64(&converted, 0)
+		var ctx context.Context
+		ctx, cancel = context.WithCancel(context.Background())
+		result, err := tupleconv.MakeBatchMapper(mapper).WithWorkers(workers).
+			MapBatch(ctx, tuples)
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Less(t, atomic.LoadInt64(&converted), int64(len(tuples)))
+		cancel()
+	}
+}
+
+func TestBatchRowFromContext(t *testing.T) {
+	_, ok := tupleconv.BatchRowFromContext(context.Background())
+	assert.False(t, ok)
+
+	mapper := tupleconv.MakeMapper([]tupleconv.Converter[string, any]{
+		tupleconv.MakeFuncContextConverter(func(ctx context.Context, _ string) (any, error) {
+			row, ok := tupleconv.BatchRowFromContext(ctx)
+			require.True(t, ok)
+			return row, nil
+		}),
+	})
+	results, err := tupleconv.MakeBatchMapper(mapper).WithWorkers(4).MapBatch(
+		context.Background(), [][]string{{"a"}, {"b"}, {"c"}})
+	require.NoError(t, err)
+	assert.Equal(t, [][]any{{0}, {1}, {2}}, results)
+}
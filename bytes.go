@@ -0,0 +1,220 @@
+package tupleconv
+
+import (
+	"context"
+	"encoding/json"
+	"unsafe"
+
+	"github.com/google/uuid"
+)
+
+// BytesToTTConvFactory is a TTConvFactory for byte slices. It is configured by
+// StringToTTConvFactory and converts values the same way, but parses byte slices
+// directly without copying them to strings, where it is possible.
+//
+// Varbinary values keep the input bytes by default, so the input must not be
+// modified after the conversion. Use WithCopyBinary, if the input buffers are
+// reused.
+type BytesToTTConvFactory struct {
+	// strFac is the configuration of the factory.
+	strFac StringToTTConvFactory
+	// copyBinary is true, if varbinary values are copied.
+	copyBinary bool
+}
+
+// MakeBytesToTTConvFactory creates BytesToTTConvFactory with the configuration of
+// the string factory: separators, null values, datetime layouts and normalization.
+func MakeBytesToTTConvFactory(strFac StringToTTConvFactory) BytesToTTConvFactory {
+	return BytesToTTConvFactory{strFac: strFac}
+}
+
+var (
+	_ TTConvFactory[[]byte]    = (*BytesToTTConvFactory)(nil)
+	_ fieldConvFactory[[]byte] = (*BytesToTTConvFactory)(nil)
+	_ anyConvFactory           = (*BytesToTTConvFactory)(nil)
+)
+
+// anyFactory is the implementation of anyConvFactory for BytesToTTConvFactory.
+func (fac BytesToTTConvFactory) anyFactory() TTConvFactory[any] {
+	return MakeAnyToTTConvFactory(fac.strFac)
+}
+
+// WithCopyBinary sets whether varbinary values are copied from the input.
+func (fac BytesToTTConvFactory) WithCopyBinary(copyBinary bool) BytesToTTConvFactory {
+	fac.copyBinary = copyBinary
+	return fac
+}
+
+// makeBytesConverter adapts the converter from string to a converter from []byte.
+// The bytes are copied to a string, so the converter may retain it.
+func makeBytesConverter(converter Converter[string, any]) Converter[[]byte, any] {
+	return MakeFuncContextConverter(func(ctx context.Context, src []byte) (any, error) {
+		return convertContext(ctx, converter, string(src))
+	})
+}
+
+// bytesView returns the string, that shares the memory with the bytes.
+func bytesView(src []byte) string {
+	return *(*string)(unsafe.Pointer(&src))
+}
+
+// makeBytesViewConverter adapts the converter from string to a converter from
+// []byte without copying the bytes. The converter must not retain the string in
+// its result, for example, parsers of numbers. On errors the converter is called
+// again with a copy, so errors don't refer to the input.
+func makeBytesViewConverter(converter Converter[string, any]) Converter[[]byte, any] {
+	return MakeFuncConverter(func(src []byte) (any, error) {
+		result, err := converter.Convert(bytesView(src))
+		if err != nil {
+			return converter.Convert(string(src))
+		}
+		return result, nil
+	})
+}
+
+func (BytesToTTConvFactory) GetBooleanConverter() Converter[[]byte, any] {
+	return makeBytesViewConverter(MakeStringToBoolConverter())
+}
+
+func (BytesToTTConvFactory) GetStringConverter() Converter[[]byte, any] {
+	return MakeFuncConverter(func(src []byte) (any, error) {
+		return string(src), nil
+	})
+}
+
+func (fac BytesToTTConvFactory) GetUnsignedConverter() Converter[[]byte, any] {
+	return makeBytesViewConverter(MakeStringToUIntConverter(fac.strFac.thousandSeparators))
+}
+
+// GetDatetimeConverter returns the converter, that copies the bytes, because time
+// zone names of the parsed datetime may refer to the input.
+func (fac BytesToTTConvFactory) GetDatetimeConverter() Converter[[]byte, any] {
+	return makeBytesConverter(fac.strFac.GetDatetimeConverter())
+}
+
+func (BytesToTTConvFactory) GetUUIDConverter() Converter[[]byte, any] {
+	return MakeFuncConverter(func(src []byte) (any, error) {
+		return uuid.ParseBytes(src)
+	})
+}
+
+// unmarshalJSON decodes the JSON value.
+func unmarshalJSON(src []byte) (any, error) {
+	var result any
+	if err := json.Unmarshal(src, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (BytesToTTConvFactory) GetMapConverter() Converter[[]byte, any] {
+	return MakeFuncConverter(unmarshalJSON)
+}
+
+func (BytesToTTConvFactory) GetArrayConverter() Converter[[]byte, any] {
+	return MakeFuncConverter(unmarshalJSON)
+}
+
+func (fac BytesToTTConvFactory) GetVarbinaryConverter() Converter[[]byte, any] {
+	copyBinary := fac.copyBinary
+	return MakeFuncConverter(func(src []byte) (any, error) {
+		if copyBinary {
+			return append([]byte{}, src...), nil
+		}
+		return src, nil
+	})
+}
+
+func (fac BytesToTTConvFactory) GetDoubleConverter() Converter[[]byte, any] {
+	return makeBytesViewConverter(MakeStringToFloatConverter(fac.strFac.thousandSeparators,
+		fac.strFac.decimalSeparators))
+}
+
+func (fac BytesToTTConvFactory) GetDecimalConverter() Converter[[]byte, any] {
+	return makeBytesViewConverter(MakeStringToDecimalConverter(fac.strFac.thousandSeparators,
+		fac.strFac.decimalSeparators))
+}
+
+func (fac BytesToTTConvFactory) GetIntegerConverter() Converter[[]byte, any] {
+	return makeIntegerConverter(fac.GetUnsignedConverter(), fac.getSignedConverter(),
+		fac.strFac.thousandSeparators)
+}
+
+// getSignedConverter returns a converter from []byte to int64.
+func (fac BytesToTTConvFactory) getSignedConverter() Converter[[]byte, any] {
+	return makeBytesViewConverter(MakeStringToIntConverter(fac.strFac.thousandSeparators))
+}
+
+func (fac BytesToTTConvFactory) GetNumberConverter() Converter[[]byte, any] {
+	return MakeSequenceConverter([]Converter[[]byte, any]{
+		fac.GetIntegerConverter(),
+		fac.GetDoubleConverter(),
+	})
+}
+
+func (BytesToTTConvFactory) GetIntervalConverter() Converter[[]byte, any] {
+	return makeBytesViewConverter(MakeStringToIntervalConverter())
+}
+
+func (fac BytesToTTConvFactory) GetAnyConverter() Converter[[]byte, any] {
+	return fac.GetScalarConverter()
+}
+
+func (fac BytesToTTConvFactory) GetScalarConverter() Converter[[]byte, any] {
+	return MakeSequenceConverter([]Converter[[]byte, any]{
+		fac.GetNumberConverter(),
+		fac.GetDecimalConverter(),
+		fac.GetBooleanConverter(),
+		fac.GetDatetimeConverter(),
+		fac.GetUUIDConverter(),
+		fac.GetIntervalConverter(),
+		fac.GetStringConverter(),
+	})
+}
+
+func (fac BytesToTTConvFactory) MakeNullableConverter(
+	converter Converter[[]byte, any]) Converter[[]byte, any] {
+	return makeBytesToNullableConverter(fac.strFac.nullValues, converter)
+}
+
+// makeBytesToNullableConverter extends the converter to a nullable converter
+// with the null values.
+func makeBytesToNullableConverter(
+	nullValues []string, converter Converter[[]byte, any]) Converter[[]byte, any] {
+	return MakeFuncContextConverter(func(ctx context.Context, src []byte) (any, error) {
+		for _, nullValue := range nullValues {
+			if string(src) == nullValue {
+				return nil, nil
+			}
+		}
+		return convertContext(ctx, converter, src)
+	})
+}
+
+// makeFieldConverter is the implementation of fieldConvFactory[[]byte] for
+// BytesToTTConvFactory. It handles per-type and per-field null values and
+// normalization the same way as StringToTTConvFactory.
+func (fac BytesToTTConvFactory) makeFieldConverter(field SpaceField,
+	converter Converter[[]byte, any], outer TTConvFactory[[]byte]) Converter[[]byte, any] {
+	if field.IsNullable {
+		switch outer.(type) {
+		case BytesToTTConvFactory, *BytesToTTConvFactory:
+			converter = makeBytesToNullableConverter(fac.strFac.getFieldNullValues(field),
+				converter)
+		default:
+			converter = outer.MakeNullableConverter(converter)
+		}
+	}
+	opts := fac.strFac.getFieldNormalization(field)
+	if opts.isEmpty() {
+		return converter
+	}
+	normalizer := MakeStringNormalizer(opts)
+	return MakeFuncContextConverter(func(ctx context.Context, src []byte) (any, error) {
+		normalized, err := normalizer.Convert(string(src))
+		if err != nil {
+			return nil, err
+		}
+		return convertContext(ctx, converter, []byte(normalized))
+	})
+}
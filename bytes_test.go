@@ -0,0 +1,238 @@
+package tupleconv_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tarantool/go-tupleconv"
+)
+
+// factoryCase is a case of the conversion by a field converter, that is shared
+// between the string and the bytes factories.
+type factoryCase struct {
+	field tupleconv.SpaceField
+	value string
+}
+
+var factoryCases = []factoryCase{
+	{field: tupleconv.SpaceField{Type: tupleconv.TypeBoolean}, value: "true"},
+	{field: tupleconv.SpaceField{Type: tupleconv.TypeBoolean}, value: "yes"},
+	{field: tupleconv.SpaceField{Type: tupleconv.TypeString}, value: "abc"},
+	{field: tupleconv.SpaceField{Type: tupleconv.TypeUnsigned}, value: "1 000 000"},
+	{field: tupleconv.SpaceField{Type: tupleconv.TypeUnsigned}, value: "18446744073709551616"},
+	{field: tupleconv.SpaceField{Type: tupleconv.TypeUnsigned}, value: "-1"},
+	{field: tupleconv.SpaceField{Type: tupleconv.TypeInteger}, value: "-1 000"},
+	{field: tupleconv.SpaceField{Type: tupleconv.TypeInteger}, value: "18446744073709551615"},
+	{field: tupleconv.SpaceField{Type: tupleconv.TypeInteger}, value: "1.5"},
+	{field: tupleconv.SpaceField{Type: tupleconv.TypeDouble}, value: "1 000,25"},
+	{field: tupleconv.SpaceField{Type: tupleconv.TypeDouble}, value: "1e-3"},
+	{field: tupleconv.SpaceField{Type: tupleconv.TypeDouble}, value: "x"},
+	{field: tupleconv.SpaceField{Type: tupleconv.TypeNumber}, value: "-5"},
+	{field: tupleconv.SpaceField{Type: tupleconv.TypeNumber}, value: "5,5"},
+	{field: tupleconv.SpaceField{Type: tupleconv.TypeDecimal}, value: "12 345,678"},
+	{field: tupleconv.SpaceField{Type: tupleconv.TypeDecimal}, value: "1e"},
+	{
+		field: tupleconv.SpaceField{Type: tupleconv.TypeUUID},
+		value: "09b56913-11f0-4fa4-b5d0-901b5efa532a",
+	},
+	{field: tupleconv.SpaceField{Type: tupleconv.TypeUUID}, value: "09b56913"},
+	{
+		field: tupleconv.SpaceField{Type: tupleconv.TypeDatetime},
+		value: "2023-08-30T12:06:05.120-0300",
+	},
+	{field: tupleconv.SpaceField{Type: tupleconv.TypeDatetime}, value: "30.08.2023"},
+	{field: tupleconv.SpaceField{Type: tupleconv.TypeDatetime}, value: "2023"},
+	{field: tupleconv.SpaceField{Type: tupleconv.TypeInterval}, value: "1,2,3,4,5,6,7,8,1"},
+	{field: tupleconv.SpaceField{Type: tupleconv.TypeInterval}, value: "1,2"},
+	{field: tupleconv.SpaceField{Type: tupleconv.TypeArray}, value: `[1,"a",{"b":[]}]`},
+	{field: tupleconv.SpaceField{Type: tupleconv.TypeMap}, value: `{"a":{"b":null}}`},
+	{field: tupleconv.SpaceField{Type: tupleconv.TypeMap}, value: `{"a":`},
+	{field: tupleconv.SpaceField{Type: tupleconv.TypeVarbinary}, value: "\x00\xff"},
+	{field: tupleconv.SpaceField{Type: tupleconv.TypeScalar}, value: "1 000,5"},
+	{field: tupleconv.SpaceField{Type: tupleconv.TypeScalar}, value: "false"},
+	{field: tupleconv.SpaceField{Type: tupleconv.TypeAny}, value: "30.08.2023"},
+	{field: tupleconv.SpaceField{Type: tupleconv.TypeAny}, value: "text"},
+	{
+		field: tupleconv.SpaceField{Type: tupleconv.TypeString, IsNullable: true},
+		value: "NULL",
+	},
+	{
+		field: tupleconv.SpaceField{Type: tupleconv.TypeString, IsNullable: true},
+		value: "",
+	},
+	{
+		field: tupleconv.SpaceField{Type: tupleconv.TypeInteger, IsNullable: true},
+		value: "-",
+	},
+	{
+		field: tupleconv.SpaceField{Type: tupleconv.TypeInteger, IsNullable: true},
+		value: "NULL",
+	},
+	{
+		field: tupleconv.SpaceField{Name: "code", Type: tupleconv.TypeString, IsNullable: true},
+		value: "n/a",
+	},
+	{
+		field: tupleconv.SpaceField{Name: "code", Type: tupleconv.TypeString, IsNullable: true},
+		value: "NULL",
+	},
+	{
+		field: tupleconv.SpaceField{Type: tupleconv.TypeString, Collation: "unicode_ci"},
+		value: "  Mixed   Case ",
+	},
+	{field: tupleconv.SpaceField{Type: tupleconv.TypeUnsigned}, value: " 42 "},
+}
+
+func makeFactoryTestStringFactory() tupleconv.StringToTTConvFactory {
+	return tupleconv.MakeStringToTTConvFactory().
+		WithThousandSeparators(" ").
+		WithDecimalSeparators(",").
+		WithNullValues("", "NULL").
+		WithTypeNullValues(tupleconv.TypeInteger, "-").
+		WithFieldNullValues("code", "n/a").
+		WithDatetimeLayouts("02.01.2006").
+		WithNormalization(tupleconv.NormalizeOptions{Trim: true, FoldCase: true})
+}
+
+func TestBytesToTTConvFactory_matchesStringFactory(t *testing.T) {
+	strFac := makeFactoryTestStringFactory()
+	bytesFac := tupleconv.MakeBytesToTTConvFactory(strFac)
+
+	for _, tc := range factoryCases {
+		t.Run(string(tc.field.Type)+"/"+tc.value, func(t *testing.T) {
+			spaceFmt := []tupleconv.SpaceField{tc.field}
+			strConverters, err := tupleconv.MakeTypeToTTConverters[string](strFac, spaceFmt)
+			require.NoError(t, err)
+			bytesConverters, err := tupleconv.MakeTypeToTTConverters[[]byte](bytesFac, spaceFmt)
+			require.NoError(t, err)
+
+			expected, expectedErr := strConverters[0].Convert(tc.value)
+			actual, err := bytesConverters[0].Convert([]byte(tc.value))
+			if expectedErr != nil {
+				assert.EqualError(t, err, expectedErr.Error())
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, expected, actual)
+		})
+	}
+}
+
+func TestBytesToTTConvFactory_varbinary(t *testing.T) {
+	src := []byte("data")
+
+	result, err := tupleconv.MakeBytesToTTConvFactory(tupleconv.MakeStringToTTConvFactory()).
+		GetVarbinaryConverter().Convert(src)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("data"), result)
+	assert.Same(t, &src[0], &result.([]byte)[0])
+
+	result, err = tupleconv.MakeBytesToTTConvFactory(tupleconv.MakeStringToTTConvFactory()).
+		WithCopyBinary(true).
+		GetVarbinaryConverter().Convert(src)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("data"), result)
+	assert.NotSame(t, &src[0], &result.([]byte)[0])
+}
+
+func TestBytesToTTConvFactory_mapper(t *testing.T) {
+	bytesFac := tupleconv.MakeBytesToTTConvFactory(makeFactoryTestStringFactory())
+	converters, err := tupleconv.MakeTypeToTTConverters[[]byte](bytesFac, msgpackTestFormat)
+	require.NoError(t, err)
+	strConverters, err := tupleconv.MakeTypeToTTConverters[string](
+		makeFactoryTestStringFactory(), msgpackTestFormat)
+	require.NoError(t, err)
+
+	strTuple := append([]string{}, msgpackTestTuple...)
+	strTuple[3] = "1,5"
+	tuple := make([][]byte, len(strTuple))
+	for i, value := range strTuple {
+		tuple[i] = []byte(value)
+	}
+
+	expected, err := tupleconv.MakeMapper(strConverters).Map(strTuple)
+	require.NoError(t, err)
+	actual, err := tupleconv.MakeMapper(converters).Map(tuple)
+	require.NoError(t, err)
+	assert.Equal(t, expected, actual)
+}
+
+func TestBytesToTTConvFactory_noCopy(t *testing.T) {
+	fac := tupleconv.MakeBytesToTTConvFactory(tupleconv.MakeStringToTTConvFactory())
+	cases := []struct {
+		name string
+		conv tupleconv.Converter[[]byte, any]
+		src  string
+	}{
+		{"unsigned", fac.GetUnsignedConverter(), "100500"},
+		{"integer", fac.GetIntegerConverter(), "-100500"},
+		{"double", fac.GetDoubleConverter(), "1.5"},
+		{"boolean", fac.GetBooleanConverter(), "true"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			src := []byte(tc.src)
+			allocs := testing.AllocsPerRun(100, func() {
+				if _, err := tc.conv.Convert(src); err != nil {
+					t.Fatal(err)
+				}
+			})
+			// The only allocation is the result, boxed into any.
+			assert.LessOrEqual(t, allocs, 1.0)
+		})
+	}
+
+	// Errors don't refer to the input.
+	src := []byte("12x")
+	_, err := fac.GetUnsignedConverter().Convert(src)
+	require.Error(t, err)
+	message := err.Error()
+	copy(src, "abc")
+	assert.Equal(t, message, err.Error())
+}
+
+// BenchmarkBytesToTTConvFactory compares the string and the bytes factories.
+// The bytes factory parses numbers and booleans without copying the input, it
+// allocates only the converted values: 5 allocs/op against 9 allocs/op.
+func BenchmarkBytesToTTConvFactory(b *testing.B) {
+	spaceFmt := msgpackTestFormat[:5]
+	strConverters, err := tupleconv.MakeTypeToTTConverters[string](
+		tupleconv.MakeStringToTTConvFactory(), spaceFmt)
+	require.NoError(b, err)
+	bytesConverters, err := tupleconv.MakeTypeToTTConverters[[]byte](
+		tupleconv.MakeBytesToTTConvFactory(tupleconv.MakeStringToTTConvFactory()), spaceFmt)
+	require.NoError(b, err)
+	strMapper, bytesMapper := tupleconv.MakeMapper(strConverters),
+		tupleconv.MakeMapper(bytesConverters)
+
+	tuple := make([][]byte, len(spaceFmt))
+	for i := range tuple {
+		tuple[i] = []byte(msgpackTestTuple[i])
+	}
+
+	b.Run("string", func(b *testing.B) {
+		b.ReportAllocs()
+		var dst []any
+		strTuple := make([]string, len(tuple))
+		for i := 0; i < b.N; i++ {
+			for j, field := range tuple {
+				strTuple[j] = string(field)
+			}
+			if dst, err = strMapper.MapTo(dst, strTuple); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("bytes", func(b *testing.B) {
+		b.ReportAllocs()
+		var dst []any
+		for i := 0; i < b.N; i++ {
+			if dst, err = bytesMapper.MapTo(dst, tuple); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,366 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/tarantool/go-tupleconv"
+	"gopkg.in/yaml.v3"
+)
+
+// formatField is a space format field in a JSON/YAML file.
+type formatField struct {
+	Name       string             `yaml:"name"`
+	Type       tupleconv.TypeName `yaml:"type"`
+	IsNullable bool               `yaml:"is_nullable"`
+	Collation  string             `yaml:"collation"`
+}
+
+// loadSpaceFormat loads the space format from the file. Files with `.lua`
+// extension contain a Lua `format` table, other files are JSON or YAML.
+func loadSpaceFormat(path string) ([]tupleconv.SpaceField, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if strings.EqualFold(filepath.Ext(path), ".lua") {
+		return parseLuaFormat(data)
+	}
+	return parseFormat(data)
+}
+
+// parseFormat parses the space format from JSON or YAML.
+func parseFormat(data []byte) ([]tupleconv.SpaceField, error) {
+	var fields []formatField
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&fields); err != nil {
+		return nil, fmt.Errorf("can't parse space format: %w", err)
+	}
+	spaceFmt := make([]tupleconv.SpaceField, len(fields))
+	for i, field := range fields {
+		spaceFmt[i] = tupleconv.SpaceField{
+			Name:       field.Name,
+			Type:       field.Type,
+			IsNullable: field.IsNullable,
+			Collation:  field.Collation,
+		}
+	}
+	return spaceFmt, validateFormat(spaceFmt)
+}
+
+// parseLuaFormat parses the space format from a Lua table, like the argument of
+// `space:format()`. Everything before the first `{` is ignored, so
+// `box.space.x:format({...})` and `return {...}` are accepted too.
+func parseLuaFormat(data []byte) ([]tupleconv.SpaceField, error) {
+	src := string(data)
+	parser := luaParser{src: src}
+	parser.skipSpace()
+	for parser.pos < len(src) && src[parser.pos] != '{' {
+		parser.pos++
+		parser.skipSpace()
+	}
+	if parser.pos == len(src) {
+		return nil, fmt.Errorf("can't parse space format: no table found")
+	}
+	value, err := parser.parseValue()
+	if err != nil {
+		return nil, fmt.Errorf("can't parse space format: %w", err)
+	}
+	table := value.(luaTable)
+	if len(table.fields) != 0 {
+		return nil, fmt.Errorf("can't parse space format: format must be an array")
+	}
+
+	spaceFmt := make([]tupleconv.SpaceField, len(table.array))
+	for i, item := range table.array {
+		fieldTable, ok := item.(luaTable)
+		if !ok {
+			return nil, fmt.Errorf("field #%d: table expected", i+1)
+		}
+		if spaceFmt[i], err = fieldTable.spaceField(); err != nil {
+			return nil, fmt.Errorf("field #%d: %w", i+1, err)
+		}
+	}
+	return spaceFmt, validateFormat(spaceFmt)
+}
+
+// validateFormat checks the space format field types.
+func validateFormat(spaceFmt []tupleconv.SpaceField) error {
+	fac := tupleconv.MakeStringToTTConvFactory()
+	for i, field := range spaceFmt {
+		if _, err := tupleconv.GetConverterByType[string](fac, field.Type); err != nil {
+			return fmt.Errorf("field #%d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// luaTable is a parsed Lua table.
+type luaTable struct {
+	array  []any
+	fields map[string]any
+}
+
+// get returns the value by the key or by the position, if there is no such key.
+func (table luaTable) get(key string, pos int) (any, bool) {
+	if value, ok := table.fields[key]; ok {
+		return value, true
+	}
+	if pos >= 0 && pos < len(table.array) {
+		return table.array[pos], true
+	}
+	return nil, false
+}
+
+// getString returns the string value by the key or the position.
+func (table luaTable) getString(key string, pos int) (string, error) {
+	value, ok := table.get(key, pos)
+	if !ok {
+		return "", nil
+	}
+	str, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("%s must be a string", key)
+	}
+	return str, nil
+}
+
+// spaceField converts the table to a space format field.
+func (table luaTable) spaceField() (tupleconv.SpaceField, error) {
+	var field tupleconv.SpaceField
+	var err error
+	if field.Name, err = table.getString("name", 0); err != nil {
+		return field, err
+	}
+	typ, err := table.getString("type", 1)
+	if err != nil {
+		return field, err
+	}
+	field.Type = tupleconv.TypeName(typ)
+	if field.Type == "" {
+		field.Type = tupleconv.TypeAny
+	}
+	if field.Collation, err = table.getString("collation", -1); err != nil {
+		return field, err
+	}
+	if value, ok := table.fields["is_nullable"]; ok {
+		if field.IsNullable, ok = value.(bool); !ok {
+			return field, fmt.Errorf("is_nullable must be a boolean")
+		}
+	}
+	return field, nil
+}
+
+// luaParser is a parser of Lua table constructors with constant values.
+type luaParser struct {
+	src string
+	pos int
+}
+
+// errorf returns an error with the current position.
+func (p *luaParser) errorf(format string, args ...any) error {
+	line := strings.Count(p.src[:p.pos], "\n") + 1
+	return fmt.Errorf("line %d: %s", line, fmt.Sprintf(format, args...))
+}
+
+// skipSpace skips white space and comments.
+func (p *luaParser) skipSpace() {
+	for p.pos < len(p.src) {
+		switch {
+		case strings.ContainsRune(" \t\r\n", rune(p.src[p.pos])):
+			p.pos++
+		case strings.HasPrefix(p.src[p.pos:], "--"):
+			end := strings.IndexByte(p.src[p.pos:], '\n')
+			if end < 0 {
+				p.pos = len(p.src)
+			} else {
+				p.pos += end + 1
+			}
+		default:
+			return
+		}
+	}
+}
+
+// peek returns the next non-space character or 0 at the end of the input.
+func (p *luaParser) peek() byte {
+	p.skipSpace()
+	if p.pos < len(p.src) {
+		return p.src[p.pos]
+	}
+	return 0
+}
+
+// expect consumes the character.
+func (p *luaParser) expect(char byte) error {
+	if p.peek() != char {
+		return p.errorf("%q expected", char)
+	}
+	p.pos++
+	return nil
+}
+
+// parseValue parses a table, a string, a number, a boolean or nil.
+func (p *luaParser) parseValue() (any, error) {
+	switch char := p.peek(); {
+	case char == '{':
+		return p.parseTable()
+	case char == '\'' || char == '"':
+		return p.parseString()
+	case char == '-' || char == '.' || isDigit(char):
+		return p.parseNumber()
+	case isIdentStart(char):
+		switch ident := p.parseIdent(); ident {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		case "nil":
+			return nil, nil
+		default:
+			return nil, p.errorf("unexpected identifier %q", ident)
+		}
+	case char == 0:
+		return nil, p.errorf("unexpected end of input")
+	default:
+		return nil, p.errorf("unexpected character %q", char)
+	}
+}
+
+// parseTable parses a table constructor.
+func (p *luaParser) parseTable() (any, error) {
+	if err := p.expect('{'); err != nil {
+		return nil, err
+	}
+	table := luaTable{fields: map[string]any{}}
+	for p.peek() != '}' {
+		key, hasKey, err := p.parseKey()
+		if err != nil {
+			return nil, err
+		}
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		if hasKey {
+			table.fields[key] = value
+		} else {
+			table.array = append(table.array, value)
+		}
+		if char := p.peek(); char == ',' || char == ';' {
+			p.pos++
+		} else if char != '}' {
+			return nil, p.errorf("'}' expected")
+		}
+	}
+	p.pos++
+	return table, nil
+}
+
+// parseKey parses `name =` and `['name'] =` prefixes of the table field.
+func (p *luaParser) parseKey() (string, bool, error) {
+	start := p.pos
+	if p.peek() == '[' {
+		p.pos++
+		key, err := p.parseValue()
+		if err != nil {
+			return "", false, err
+		}
+		str, ok := key.(string)
+		if !ok {
+			return "", false, p.errorf("only string keys are supported")
+		}
+		if err := p.expect(']'); err != nil {
+			return "", false, err
+		}
+		if err := p.expect('='); err != nil {
+			return "", false, err
+		}
+		return str, true, nil
+	}
+	if isIdentStart(p.peek()) {
+		ident := p.parseIdent()
+		if p.peek() == '=' {
+			p.pos++
+			return ident, true, nil
+		}
+	}
+	p.pos = start
+	return "", false, nil
+}
+
+// parseIdent parses an identifier.
+func (p *luaParser) parseIdent() string {
+	start := p.pos
+	for p.pos < len(p.src) && (isIdentStart(p.src[p.pos]) || isDigit(p.src[p.pos])) {
+		p.pos++
+	}
+	return p.src[start:p.pos]
+}
+
+// parseNumber parses a decimal number.
+func (p *luaParser) parseNumber() (any, error) {
+	start := p.pos
+	for p.pos < len(p.src) && strings.IndexByte("+-.0123456789eE", p.src[p.pos]) >= 0 {
+		p.pos++
+	}
+	number, err := strconv.ParseFloat(p.src[start:p.pos], 64)
+	if err != nil {
+		p.pos = start
+		return nil, p.errorf("invalid number")
+	}
+	return number, nil
+}
+
+// parseString parses a quoted string with the basic escape sequences.
+func (p *luaParser) parseString() (any, error) {
+	quote := p.src[p.pos]
+	p.pos++
+	var builder strings.Builder
+	for p.pos < len(p.src) {
+		char := p.src[p.pos]
+		p.pos++
+		switch char {
+		case quote:
+			return builder.String(), nil
+		case '\n':
+			return nil, p.errorf("unfinished string")
+		case '\\':
+			if p.pos == len(p.src) {
+				return nil, p.errorf("unfinished string")
+			}
+			escaped := p.src[p.pos]
+			p.pos++
+			switch escaped {
+			case 'n':
+				builder.WriteByte('\n')
+			case 't':
+				builder.WriteByte('\t')
+			case 'r':
+				builder.WriteByte('\r')
+			case '\\', '\'', '"':
+				builder.WriteByte(escaped)
+			default:
+				return nil, p.errorf("unsupported escape sequence \\%c", escaped)
+			}
+		default:
+			builder.WriteByte(char)
+		}
+	}
+	return nil, p.errorf("unfinished string")
+}
+
+// isDigit checks if the character is a decimal digit.
+func isDigit(char byte) bool {
+	return char >= '0' && char <= '9'
+}
+
+// isIdentStart checks if the character can start a Lua identifier.
+func isIdentStart(char byte) bool {
+	return char == '_' || (char >= 'a' && char <= 'z') || (char >= 'A' && char <= 'Z')
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tarantool/go-tupleconv"
+)
+
+func TestParseLuaFormat(t *testing.T) {
+	data := `
+-- Comments are skipped.
+return {
+    {name = 'id', type = 'unsigned'};
+    {'name', "string", is_nullable = false},
+    {["name"] = 'tags', ['type'] = 'array', is_nullable = true},
+    {name = 'title', type = 'string', collation = 'unicode_ci'},
+    {name = 'any'},
+}`
+	spaceFmt, err := parseLuaFormat([]byte(data))
+	require.NoError(t, err)
+	assert.Equal(t, []tupleconv.SpaceField{
+		{Name: "id", Type: tupleconv.TypeUnsigned},
+		{Name: "name", Type: tupleconv.TypeString},
+		{Name: "tags", Type: tupleconv.TypeArray, IsNullable: true},
+		{Name: "title", Type: tupleconv.TypeString, Collation: "unicode_ci"},
+		{Name: "any", Type: tupleconv.TypeAny},
+	}, spaceFmt)
+}
+
+func TestParseLuaFormat_errors(t *testing.T) {
+	cases := []struct {
+		data       string
+		errMessage string
+	}{
+		{data: "", errMessage: "no table found"},
+		{data: "{name = 'id'", errMessage: "line 1: '}' expected"},
+		{data: "{{name = 'id', type = 'unsigned'}, 1}", errMessage: "field #2: table expected"},
+		{data: "{{name = 1}}", errMessage: "field #1: name must be a string"},
+		{data: "{{name = 'a', is_nullable = 'yes'}}", errMessage: "is_nullable must be a boolean"},
+		{data: "{{name = 'a', type = 'text'}}", errMessage: "field #1: unexpected type: text"},
+		{data: "{{name = 'a\\q'}}", errMessage: `unsupported escape sequence \q`},
+		{data: "{{name = x}}", errMessage: `unexpected identifier "x"`},
+		{data: "{a = {}}", errMessage: "format must be an array"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.data, func(t *testing.T) {
+			_, err := parseLuaFormat([]byte(tc.data))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.errMessage)
+		})
+	}
+}
+
+func TestParseFormat_errors(t *testing.T) {
+	_, err := parseFormat([]byte("[{name: id, type: unsigned, nullable: true}]"))
+	assert.ErrorContains(t, err, "can't parse space format")
+
+	_, err = parseFormat([]byte(`[{"name": "id", "type": "text"}]`))
+	assert.ErrorContains(t, err, "field #1: unexpected type: text")
+}
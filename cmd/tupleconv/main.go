@@ -0,0 +1,276 @@
+// Command tupleconv converts CSV files to tarantool tuples by a space format.
+//
+// Usage:
+//
+//	tupleconv -format format.yaml [flags] [input.csv]
+//
+// The space format is a JSON/YAML array of fields or a Lua `format` table in
+// a file with `.lua` extension. The input is read from the standard input, if
+// the file isn't set. Output modes are:
+//
+//   - lua: `box.space.<name>:insert{...}` statements;
+//   - jsonl: JSON arrays, one per line;
+//   - msgpack: a stream of MessagePack arrays;
+//   - validate: a report of invalid rows, nothing is converted.
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/tarantool/go-tupleconv"
+)
+
+// Exit codes.
+const (
+	exitOK      = 0
+	exitInvalid = 1
+	exitUsage   = 2
+)
+
+// stringsFlag is a flag, that can be set several times.
+type stringsFlag []string
+
+// String is the implementation of flag.Value for stringsFlag.
+func (values *stringsFlag) String() string {
+	return strings.Join(*values, ",")
+}
+
+// Set is the implementation of flag.Value for stringsFlag.
+func (values *stringsFlag) Set(value string) error {
+	*values = append(*values, value)
+	return nil
+}
+
+// options are command-line options.
+type options struct {
+	formatPath         string
+	outputPath         string
+	mode               string
+	space              string
+	delimiter          string
+	header             bool
+	thousandSeparators string
+	decimalSeparators  string
+	nullValues         stringsFlag
+	datetimeLayouts    stringsFlag
+	inputPath          string
+	isSet              map[string]bool
+}
+
+// parseOptions parses command-line arguments.
+func parseOptions(args []string, stderr io.Writer) (options, error) {
+	var opts options
+	flags := flag.NewFlagSet("tupleconv", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.StringVar(&opts.formatPath, "format", "",
+		"space format file: JSON/YAML array of fields or Lua table (.lua)")
+	flags.StringVar(&opts.outputPath, "output", "", "output file (default stdout)")
+	flags.StringVar(&opts.mode, "mode", modeJSONL,
+		"output mode: lua, jsonl, msgpack or validate")
+	flags.StringVar(&opts.space, "space", "", "space name for lua output")
+	flags.StringVar(&opts.delimiter, "delimiter", ",", "CSV field delimiter")
+	flags.BoolVar(&opts.header, "header", false, "skip the first CSV row")
+	flags.StringVar(&opts.thousandSeparators, "thousand-separators", "",
+		"thousands separators for numeric types")
+	flags.StringVar(&opts.decimalSeparators, "decimal-separators", "",
+		"additional decimal separators for numeric types")
+	flags.Var(&opts.nullValues, "null",
+		"value interpreted as null for nullable fields, can be repeated")
+	flags.Var(&opts.datetimeLayouts, "datetime-layout",
+		"additional Go time layout for datetime fields, can be repeated")
+	if err := flags.Parse(args); err != nil {
+		return opts, err
+	}
+
+	opts.isSet = map[string]bool{}
+	flags.Visit(func(f *flag.Flag) {
+		opts.isSet[f.Name] = true
+	})
+	if opts.formatPath == "" {
+		return opts, fmt.Errorf("-format is required")
+	}
+	switch opts.mode {
+	case modeLua:
+		if opts.space == "" {
+			return opts, fmt.Errorf("-space is required for %s mode", modeLua)
+		}
+	case modeJSONL, modeMsgpack, modeValidate:
+	default:
+		return opts, fmt.Errorf("unexpected mode: %s", opts.mode)
+	}
+	if utf8.RuneCountInString(opts.delimiter) != 1 {
+		return opts, fmt.Errorf("-delimiter must be a single character")
+	}
+	switch flags.NArg() {
+	case 0:
+	case 1:
+		opts.inputPath = flags.Arg(0)
+	default:
+		return opts, fmt.Errorf("too many input files")
+	}
+	return opts, nil
+}
+
+// makeFactory creates StringToTTConvFactory by the options.
+func (opts options) makeFactory() tupleconv.StringToTTConvFactory {
+	fac := tupleconv.MakeStringToTTConvFactory().
+		WithDatetimeLayouts(opts.datetimeLayouts...)
+	if opts.isSet["thousand-separators"] {
+		fac = fac.WithThousandSeparators(opts.thousandSeparators)
+	}
+	if opts.isSet["decimal-separators"] {
+		fac = fac.WithDecimalSeparators(opts.decimalSeparators)
+	}
+	if len(opts.nullValues) != 0 {
+		fac = fac.WithNullValues(opts.nullValues...)
+	}
+	return fac
+}
+
+// openFiles opens the input and the output files.
+func openFiles(opts options, stdin io.Reader, stdout io.Writer) (
+	io.Reader, io.Writer, func(), error) {
+	in, out := stdin, stdout
+	var closers []io.Closer
+	closeAll := func() {
+		for _, closer := range closers {
+			closer.Close()
+		}
+	}
+	if opts.inputPath != "" && opts.inputPath != "-" {
+		file, err := os.Open(opts.inputPath)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		closers = append(closers, file)
+		in = file
+	}
+	if opts.outputPath != "" && opts.outputPath != "-" {
+		file, err := os.Create(opts.outputPath)
+		if err != nil {
+			closeAll()
+			return nil, nil, nil, err
+		}
+		closers = append(closers, file)
+		out = file
+	}
+	return in, out, closeAll, nil
+}
+
+// run runs the command and returns the exit code.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	opts, err := parseOptions(args, stderr)
+	if errors.Is(err, flag.ErrHelp) {
+		return exitOK
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "tupleconv: %s\n", err)
+		return exitUsage
+	}
+
+	spaceFmt, err := loadSpaceFormat(opts.formatPath)
+	if err != nil {
+		fmt.Fprintf(stderr, "tupleconv: %s\n", err)
+		return exitUsage
+	}
+	converters, err := tupleconv.MakeTypeToTTConverters[string](opts.makeFactory(), spaceFmt)
+	if err != nil {
+		fmt.Fprintf(stderr, "tupleconv: %s\n", err)
+		return exitUsage
+	}
+	mapper := tupleconv.MakeMapper(converters)
+
+	in, out, closeFiles, err := openFiles(opts, stdin, stdout)
+	if err != nil {
+		fmt.Fprintf(stderr, "tupleconv: %s\n", err)
+		return exitUsage
+	}
+	defer closeFiles()
+
+	reader := csv.NewReader(in)
+	reader.Comma, _ = utf8.DecodeRuneInString(opts.delimiter)
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = true
+
+	if opts.mode == modeValidate {
+		return validate(reader, mapper, opts.header, out, stderr)
+	}
+	return convert(reader, opts.header,
+		makeRowWriter(opts.mode, opts.space, mapper, out), stderr)
+}
+
+// readRows calls the handler for each CSV row with its line number.
+// It stops on the first handler error.
+func readRows(reader *csv.Reader, skipHeader bool,
+	handler func(line int, row []string) error) error {
+	for first := true; ; first = false {
+		row, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if first && skipHeader {
+			continue
+		}
+		line, _ := reader.FieldPos(0)
+		if err := handler(line, row); err != nil {
+			return err
+		}
+	}
+}
+
+// convert converts rows and writes them until the first error.
+func convert(reader *csv.Reader, skipHeader bool, writer rowWriter, stderr io.Writer) int {
+	err := readRows(reader, skipHeader, func(line int, row []string) error {
+		if err := writer.WriteRow(row); err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+		return nil
+	})
+	if flushErr := writer.Flush(); err == nil {
+		err = flushErr
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "tupleconv: %s\n", err)
+		return exitInvalid
+	}
+	return exitOK
+}
+
+// validate converts rows and reports invalid ones.
+func validate(reader *csv.Reader, mapper tupleconv.Mapper[string, any], skipHeader bool,
+	out io.Writer, stderr io.Writer) int {
+	rows, invalid := 0, 0
+	var tuple []any
+	err := readRows(reader, skipHeader, func(line int, row []string) error {
+		rows++
+		var err error
+		if tuple, err = mapper.MapTo(tuple, row); err != nil {
+			invalid++
+			fmt.Fprintf(out, "line %d: %s\n", line, err)
+		}
+		return nil
+	})
+	if err != nil {
+		fmt.Fprintf(stderr, "tupleconv: %s\n", err)
+		return exitInvalid
+	}
+	fmt.Fprintf(out, "%d rows, %d invalid\n", rows, invalid)
+	if invalid != 0 {
+		return exitInvalid
+	}
+	return exitOK
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// usersArgs are arguments to convert testdata/users.csv.
+var usersArgs = []string{
+	"-delimiter", ";", "-header",
+	"-thousand-separators", " ", "-decimal-separators", ",",
+	"-null", "NULL", "-datetime-layout", "02.01.2006",
+}
+
+func runCommand(t *testing.T, args ...string) (int, string, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	code := run(args, strings.NewReader(""), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestRun_jsonl(t *testing.T) {
+	expected := `[1,"Al \"x\"",2,"1000.5","2023-08-30T00:00:00 UTC"]` + "\n" +
+		`[2,"Bob",null,"3","2023-08-30T12:06:05.12 Europe/Paris"]` + "\n"
+	for _, format := range []string{"format.lua", "format.yaml", "format.json"} {
+		t.Run(format, func(t *testing.T) {
+			args := append([]string{"-format", filepath.Join("testdata", format)},
+				usersArgs...)
+			code, stdout, stderr := runCommand(t,
+				append(args, filepath.Join("testdata", "users.csv"))...)
+			require.Equal(t, exitOK, code, stderr)
+			assert.Equal(t, expected, stdout)
+		})
+	}
+}
+
+func TestRun_lua(t *testing.T) {
+	args := append([]string{"-format", "testdata/format.yaml", "-mode", "lua",
+		"-space", "users"}, usersArgs...)
+	code, stdout, stderr := runCommand(t, append(args, "testdata/users.csv")...)
+	require.Equal(t, exitOK, code, stderr)
+
+	lines := strings.Split(strings.TrimSuffix(stdout, "\n"), "\n")
+	require.Len(t, lines, 2)
+	assert.Equal(t, `box.space.users:insert{1, "Al \"x\"", `+
+		`require('ffi').cast('double', 2), require('decimal').new('1000.5'), `+
+		`require('datetime').new{year = 2023, month = 8, day = 30, `+
+		`hour = 0, min = 0, sec = 0, nsec = 0, tzoffset = 0}}`, lines[0])
+	assert.Contains(t, lines[1], `box.NULL`)
+	assert.Contains(t, lines[1], `tz = "Europe/Paris"`)
+}
+
+func TestRun_msgpack(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "users.msgpack")
+	args := append([]string{"-format", "testdata/format.yaml", "-mode", "msgpack",
+		"-output", output}, usersArgs...)
+	code, _, stderr := runCommand(t, append(args, "testdata/users.csv")...)
+	require.Equal(t, exitOK, code, stderr)
+
+	file, err := os.Open(output)
+	require.NoError(t, err)
+	defer file.Close()
+
+	decoder := msgpack.NewDecoder(file)
+	var tuples [][]any
+	for {
+		var tuple []any
+		if err := decoder.Decode(&tuple); err != nil {
+			break
+		}
+		tuples = append(tuples, tuple)
+	}
+	require.Len(t, tuples, 2)
+	assert.EqualValues(t, 1, tuples[0][0])
+	assert.Equal(t, "Bob", tuples[1][1])
+	assert.Nil(t, tuples[1][2])
+}
+
+func TestRun_validate(t *testing.T) {
+	input := filepath.Join(t.TempDir(), "input.csv")
+	data := "1,a,1.5,1,2023-08-30T12:06:05.120-0000\n" +
+		"x,b,,1,2023-08-30T12:06:05.120-0000\n" +
+		"3,c,y,1,2023-08-30T12:06:05.120-0000\n"
+	require.NoError(t, os.WriteFile(input, []byte(data), 0o600))
+
+	code, stdout, _ := runCommand(t, "-format", "testdata/format.yaml",
+		"-mode", "validate", input)
+	assert.Equal(t, exitInvalid, code)
+	assert.Equal(t, "line 2: unexpected value x for type \"unsigned\"\n"+
+		"line 3: unexpected value y for type \"double\"\n"+
+		"3 rows, 2 invalid\n", stdout)
+}
+
+func TestRun_convertError(t *testing.T) {
+	code, stdout, stderr := runCommand(t, "-format", "testdata/format.yaml",
+		"-delimiter", ";", "testdata/users.csv")
+	assert.Equal(t, exitInvalid, code)
+	assert.Empty(t, stdout)
+	assert.Equal(t, "tupleconv: line 1: unexpected value id for type \"unsigned\"\n", stderr)
+}
+
+func TestRun_usageErrors(t *testing.T) {
+	cases := []struct {
+		name       string
+		args       []string
+		errMessage string
+	}{
+		{
+			name:       "no format",
+			args:       []string{},
+			errMessage: "-format is required",
+		},
+		{
+			name:       "unknown mode",
+			args:       []string{"-format", "testdata/format.yaml", "-mode", "xml"},
+			errMessage: "unexpected mode: xml",
+		},
+		{
+			name:       "no space",
+			args:       []string{"-format", "testdata/format.yaml", "-mode", "lua"},
+			errMessage: "-space is required for lua mode",
+		},
+		{
+			name:       "bad delimiter",
+			args:       []string{"-format", "testdata/format.yaml", "-delimiter", ";;"},
+			errMessage: "-delimiter must be a single character",
+		},
+		{
+			name:       "missing format file",
+			args:       []string{"-format", "testdata/missing.yaml"},
+			errMessage: "no such file or directory",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			code, _, stderr := runCommand(t, tc.args...)
+			assert.Equal(t, exitUsage, code)
+			assert.Contains(t, stderr, tc.errMessage)
+		})
+	}
+}
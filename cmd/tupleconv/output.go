@@ -0,0 +1,408 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/tarantool/go-tarantool/v2/datetime"
+	"github.com/tarantool/go-tarantool/v2/decimal"
+	"github.com/tarantool/go-tupleconv"
+
+	_ "github.com/tarantool/go-tarantool/v2/uuid"
+)
+
+// Output modes.
+const (
+	modeLua      = "lua"
+	modeJSONL    = "jsonl"
+	modeMsgpack  = "msgpack"
+	modeValidate = "validate"
+)
+
+// rowWriter converts rows and writes the results.
+type rowWriter interface {
+	// WriteRow converts the row and writes the result.
+	WriteRow(row []string) error
+	// Flush writes buffered data.
+	Flush() error
+}
+
+// tupleWriter writes converted tuples.
+type tupleWriter interface {
+	// WriteTuple writes the tuple.
+	WriteTuple(tuple []any) error
+	// Flush writes buffered data.
+	Flush() error
+}
+
+// mappedWriter is a rowWriter, that writes tuples, mapped by the mapper.
+type mappedWriter struct {
+	tupleWriter
+	mapper tupleconv.Mapper[string, any]
+	// tuple is reused between rows.
+	tuple []any
+}
+
+// WriteRow is the implementation of rowWriter for mappedWriter.
+func (w *mappedWriter) WriteRow(row []string) error {
+	var err error
+	if w.tuple, err = w.mapper.MapTo(w.tuple, row); err != nil {
+		return err
+	}
+	return w.WriteTuple(w.tuple)
+}
+
+// makeRowWriter creates rowWriter for the output mode, except the validate one.
+func makeRowWriter(mode string, space string, mapper tupleconv.Mapper[string, any],
+	out io.Writer) rowWriter {
+	buffered := bufio.NewWriter(out)
+	switch mode {
+	case modeLua:
+		return &mappedWriter{
+			tupleWriter: &luaWriter{out: buffered, space: luaSpaceRef(space)},
+			mapper:      mapper,
+		}
+	case modeMsgpack:
+		return &msgpackWriter{out: buffered, mapper: tupleconv.MakeMsgpackMapper(mapper)}
+	default:
+		return &mappedWriter{
+			tupleWriter: &jsonlWriter{out: buffered, encoder: json.NewEncoder(buffered)},
+			mapper:      mapper,
+		}
+	}
+}
+
+// luaWriter writes tuples as `box.space.<name>:insert{...}` statements.
+type luaWriter struct {
+	out   *bufio.Writer
+	space string
+	buf   []byte
+}
+
+// luaSpaceRef returns the Lua expression of the space by its name.
+func luaSpaceRef(space string) string {
+	for i := 0; i < len(space); i++ {
+		if !isIdentStart(space[i]) && (i == 0 || !isDigit(space[i])) {
+			return "box.space[" + strconv.Quote(space) + "]"
+		}
+	}
+	return "box.space." + space
+}
+
+// WriteTuple is the implementation of tupleWriter for luaWriter.
+func (w *luaWriter) WriteTuple(tuple []any) error {
+	buf := append(w.buf[:0], w.space...)
+	buf = append(buf, ":insert{"...)
+	var err error
+	for i, value := range tuple {
+		if i > 0 {
+			buf = append(buf, ", "...)
+		}
+		if number, ok := value.(float64); ok && number == math.Trunc(number) &&
+			!math.IsInf(number, 0) {
+			// Integral Lua numbers are encoded as integers, but double fields
+			// accept only MP_DOUBLE.
+			buf = append(buf, "require('ffi').cast('double', "...)
+			buf = strconv.AppendFloat(buf, number, 'g', -1, 64)
+			buf = append(buf, ')')
+			continue
+		}
+		if buf, err = appendLuaValue(buf, value); err != nil {
+			return fmt.Errorf("field #%d: %w", i+1, err)
+		}
+	}
+	buf = append(buf, "}\n"...)
+	w.buf = buf
+	_, err = w.out.Write(buf)
+	return err
+}
+
+// Flush is the implementation of tupleWriter for luaWriter.
+func (w *luaWriter) Flush() error {
+	return w.out.Flush()
+}
+
+// appendLuaInt appends the Lua literal of the integer. Integers, that can't be
+// represented as Lua numbers exactly, are written as LuaJIT 64-bit literals.
+func appendLuaInt(buf []byte, value int64) []byte {
+	buf = strconv.AppendInt(buf, value, 10)
+	if value > 1<<53 || value < -(1<<53) {
+		buf = append(buf, "LL"...)
+	}
+	return buf
+}
+
+// appendLuaUint appends the Lua literal of the unsigned integer.
+func appendLuaUint(buf []byte, value uint64) []byte {
+	buf = strconv.AppendUint(buf, value, 10)
+	if value > 1<<53 {
+		buf = append(buf, "ULL"...)
+	}
+	return buf
+}
+
+// appendLuaString appends the quoted Lua string. Control characters and bytes,
+// that are not valid UTF-8, are escaped.
+func appendLuaString(buf []byte, str string) []byte {
+	buf = append(buf, '"')
+	for i := 0; i < len(str); {
+		char := str[i]
+		size := 1
+		if char >= utf8.RuneSelf {
+			var r rune
+			if r, size = utf8.DecodeRuneInString(str[i:]); r == utf8.RuneError && size == 1 {
+				buf = appendLuaByteEscape(buf, char)
+			} else {
+				buf = append(buf, str[i:i+size]...)
+			}
+			i += size
+			continue
+		}
+		switch {
+		case char == '"' || char == '\\':
+			buf = append(buf, '\\', char)
+		case char == '\n':
+			buf = append(buf, '\\', 'n')
+		case char < ' ' || char == 0x7f:
+			buf = appendLuaByteEscape(buf, char)
+		default:
+			buf = append(buf, char)
+		}
+		i += size
+	}
+	return append(buf, '"')
+}
+
+// appendLuaByteEscape appends the decimal escape sequence of the byte. It always
+// has three digits, so that the following digit isn't a part of the escape.
+func appendLuaByteEscape(buf []byte, char byte) []byte {
+	return append(buf, '\\', '0'+char/100, '0'+char/10%10, '0'+char%10)
+}
+
+// appendLuaValue appends the Lua expression of the converted value.
+func appendLuaValue(buf []byte, value any) ([]byte, error) {
+	var err error
+	switch value := value.(type) {
+	case nil:
+		buf = append(buf, "box.NULL"...)
+	case bool:
+		buf = strconv.AppendBool(buf, value)
+	case int64:
+		buf = appendLuaInt(buf, value)
+	case uint64:
+		buf = appendLuaUint(buf, value)
+	case float64:
+		switch {
+		case math.IsNaN(value):
+			buf = append(buf, "0/0"...)
+		case math.IsInf(value, 1):
+			buf = append(buf, "math.huge"...)
+		case math.IsInf(value, -1):
+			buf = append(buf, "-math.huge"...)
+		default:
+			buf = strconv.AppendFloat(buf, value, 'g', -1, 64)
+		}
+	case string:
+		buf = appendLuaString(buf, value)
+	case []byte:
+		buf = append(buf, "require('varbinary').new("...)
+		buf = appendLuaString(buf, string(value))
+		buf = append(buf, ')')
+	case decimal.Decimal:
+		buf = append(buf, "require('decimal').new('"...)
+		buf = append(buf, value.String()...)
+		buf = append(buf, "')"...)
+	case uuid.UUID:
+		buf = append(buf, "require('uuid').fromstr('"...)
+		buf = append(buf, value.String()...)
+		buf = append(buf, "')"...)
+	case datetime.Datetime:
+		buf = appendLuaDatetime(buf, value.ToTime())
+	case datetime.Interval:
+		buf = appendLuaInterval(buf, value)
+	case []any:
+		buf = append(buf, '{')
+		for i, item := range value {
+			if i > 0 {
+				buf = append(buf, ", "...)
+			}
+			if buf, err = appendLuaValue(buf, item); err != nil {
+				return nil, err
+			}
+		}
+		buf = append(buf, '}')
+	case map[string]any:
+		keys := make([]string, 0, len(value))
+		for key := range value {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		buf = append(buf, '{')
+		for i, key := range keys {
+			if i > 0 {
+				buf = append(buf, ", "...)
+			}
+			buf = append(buf, '[')
+			buf = appendLuaString(buf, key)
+			buf = append(buf, "] = "...)
+			if buf, err = appendLuaValue(buf, value[key]); err != nil {
+				return nil, err
+			}
+		}
+		buf = append(buf, '}')
+	default:
+		return nil, fmt.Errorf("unexpected value type %T", value)
+	}
+	return buf, nil
+}
+
+// appendLuaDatetime appends the `datetime.new{...}` expression.
+func appendLuaDatetime(buf []byte, tm time.Time) []byte {
+	buf = append(buf, fmt.Sprintf(
+		"require('datetime').new{year = %d, month = %d, day = %d, "+
+			"hour = %d, min = %d, sec = %d, nsec = %d",
+		tm.Year(), tm.Month(), tm.Day(), tm.Hour(), tm.Minute(), tm.Second(),
+		tm.Nanosecond())...)
+	if zone := tm.Location().String(); zone != "" && zone != "UTC" {
+		buf = append(buf, ", tz = "...)
+		buf = appendLuaString(buf, zone)
+	} else {
+		_, offset := tm.Zone()
+		buf = append(buf, ", tzoffset = "...)
+		buf = strconv.AppendInt(buf, int64(offset/60), 10)
+	}
+	return append(buf, '}')
+}
+
+// intervalAdjusts are Lua names of datetime.Adjust values.
+var intervalAdjusts = map[datetime.Adjust]string{
+	datetime.ExcessAdjust: "excess",
+	datetime.LastAdjust:   "last",
+}
+
+// appendLuaInterval appends the `datetime.interval.new{...}` expression.
+func appendLuaInterval(buf []byte, interval datetime.Interval) []byte {
+	buf = append(buf, "require('datetime').interval.new{"...)
+	parts := []struct {
+		name  string
+		value int64
+	}{
+		{"year", interval.Year}, {"month", interval.Month}, {"week", interval.Week},
+		{"day", interval.Day}, {"hour", interval.Hour}, {"min", interval.Min},
+		{"sec", interval.Sec}, {"nsec", interval.Nsec},
+	}
+	first := true
+	for _, part := range parts {
+		if part.value == 0 {
+			continue
+		}
+		if !first {
+			buf = append(buf, ", "...)
+		}
+		first = false
+		buf = append(buf, part.name...)
+		buf = append(buf, " = "...)
+		buf = strconv.AppendInt(buf, part.value, 10)
+	}
+	if adjust, ok := intervalAdjusts[interval.Adjust]; ok {
+		if !first {
+			buf = append(buf, ", "...)
+		}
+		buf = append(buf, "adjust = '"...)
+		buf = append(buf, adjust...)
+		buf = append(buf, '\'')
+	}
+	return append(buf, '}')
+}
+
+// jsonlWriter writes tuples as JSON arrays, one per line.
+type jsonlWriter struct {
+	out     *bufio.Writer
+	encoder *json.Encoder
+}
+
+// WriteTuple is the implementation of tupleWriter for jsonlWriter.
+func (w *jsonlWriter) WriteTuple(tuple []any) error {
+	values := make([]any, len(tuple))
+	for i, value := range tuple {
+		var err error
+		if values[i], err = jsonValue(value); err != nil {
+			return fmt.Errorf("field #%d: %w", i+1, err)
+		}
+	}
+	return w.encoder.Encode(values)
+}
+
+// Flush is the implementation of tupleWriter for jsonlWriter.
+func (w *jsonlWriter) Flush() error {
+	return w.out.Flush()
+}
+
+// jsonValue returns the JSON representation of the converted value. Tarantool
+// extension types are represented by strings, accepted by StringToTTConvFactory.
+func jsonValue(value any) (any, error) {
+	switch value := value.(type) {
+	case decimal.Decimal:
+		return value.String(), nil
+	case uuid.UUID:
+		return value.String(), nil
+	case datetime.Datetime:
+		return tupleconv.MakeDatetimeToStringConverter().Convert(value)
+	case datetime.Interval:
+		return tupleconv.MakeIntervalToStringConverter().Convert(value)
+	case float64:
+		if math.IsNaN(value) || math.IsInf(value, 0) {
+			return strconv.FormatFloat(value, 'g', -1, 64), nil
+		}
+		return value, nil
+	case []any:
+		result := make([]any, len(value))
+		for i, item := range value {
+			var err error
+			if result[i], err = jsonValue(item); err != nil {
+				return nil, err
+			}
+		}
+		return result, nil
+	case map[string]any:
+		result := make(map[string]any, len(value))
+		for key, item := range value {
+			var err error
+			if result[key], err = jsonValue(item); err != nil {
+				return nil, err
+			}
+		}
+		return result, nil
+	}
+	return value, nil
+}
+
+// msgpackWriter writes tuples as a stream of MessagePack arrays.
+type msgpackWriter struct {
+	out    *bufio.Writer
+	mapper tupleconv.MsgpackMapper[string]
+	buf    []byte
+}
+
+// WriteRow is the implementation of rowWriter for msgpackWriter.
+func (w *msgpackWriter) WriteRow(row []string) error {
+	var err error
+	if w.buf, err = w.mapper.AppendTuple(w.buf[:0], row); err != nil {
+		return err
+	}
+	_, err = w.out.Write(w.buf)
+	return err
+}
+
+// Flush is the implementation of rowWriter for msgpackWriter.
+func (w *msgpackWriter) Flush() error {
+	return w.out.Flush()
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tarantool/go-tarantool/v2/datetime"
+)
+
+func TestAppendLuaValue(t *testing.T) {
+	someUUID := uuid.MustParse("09b56913-11f0-4fa4-b5d0-901b5efa532a")
+	dt, err := datetime.MakeDatetime(time.Date(2023, 8, 30, 12, 6, 5, 0,
+		time.FixedZone(datetime.NoTimezone, 3*60*60)))
+	require.NoError(t, err)
+
+	cases := []struct {
+		value    any
+		expected string
+	}{
+		{nil, "box.NULL"},
+		{true, "true"},
+		{int64(-5), "-5"},
+		{uint64(1) << 60, "1152921504606846976ULL"},
+		{int64(math.MinInt64), "-9223372036854775808LL"},
+		{1.5, "1.5"},
+		{math.NaN(), "0/0"},
+		{math.Inf(-1), "-math.huge"},
+		{"a\"b\\c\nd\x01\xff2", `"a\"b\\c\nd\001\2552"`},
+		{"юникод", `"юникод"`},
+		{[]byte{0, 'a'}, `require('varbinary').new("\000a")`},
+		{someUUID, "require('uuid').fromstr('09b56913-11f0-4fa4-b5d0-901b5efa532a')"},
+		{dt, "require('datetime').new{year = 2023, month = 8, day = 30, " +
+			"hour = 12, min = 6, sec = 5, nsec = 0, tzoffset = 180}"},
+		{datetime.Interval{Year: 1, Min: -2, Adjust: datetime.LastAdjust},
+			"require('datetime').interval.new{year = 1, min = -2, adjust = 'last'}"},
+		{[]any{1.0, "a"}, `{1, "a"}`},
+		{map[string]any{"b": nil, "a": []any{}}, `{["a"] = {}, ["b"] = box.NULL}`},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.expected, func(t *testing.T) {
+			result, err := appendLuaValue(nil, tc.value)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, string(result))
+		})
+	}
+
+	_, err = appendLuaValue(nil, struct{}{})
+	assert.EqualError(t, err, "unexpected value type struct {}")
+}
+
+func TestLuaSpaceRef(t *testing.T) {
+	assert.Equal(t, "box.space.users_2", luaSpaceRef("users_2"))
+	assert.Equal(t, `box.space["2users"]`, luaSpaceRef("2users"))
+	assert.Equal(t, `box.space["my-space"]`, luaSpaceRef("my-space"))
+}
@@ -0,0 +1,8 @@
+-- Space format of the test space.
+box.space.users:format({
+    {name = 'id', type = 'unsigned'},
+    {'name', 'string'},
+    {name = 'score', type = 'double', is_nullable = true},
+    {name = 'price', type = 'decimal'},
+    {name = 'created', type = 'datetime'},
+})
@@ -0,0 +1,330 @@
+package tupleconv
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"hash/fnv"
+	"math"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/tarantool/go-tarantool/v2/datetime"
+	"github.com/tarantool/go-tarantool/v2/decimal"
+)
+
+// valueClass is the order of values of different types.
+type valueClass int
+
+const (
+	classNil valueClass = iota
+	classBoolean
+	classNumber
+	classString
+	classVarbinary
+	classUUID
+	classDatetime
+	classInterval
+	classArray
+	classMap
+)
+
+// classOf returns the class of the converted value.
+func classOf(value any) (valueClass, error) {
+	if value == nil {
+		return classNil, nil
+	}
+	typ, ok := valueTypeName(value)
+	if !ok {
+		return 0, fmt.Errorf("unexpected value of type %T", value)
+	}
+	switch typ {
+	case TypeBoolean:
+		return classBoolean, nil
+	case TypeString:
+		return classString, nil
+	case TypeVarbinary:
+		return classVarbinary, nil
+	case TypeUUID:
+		return classUUID, nil
+	case TypeDatetime:
+		return classDatetime, nil
+	case TypeInterval:
+		return classInterval, nil
+	case TypeArray:
+		return classArray, nil
+	case TypeMap:
+		return classMap, nil
+	}
+	return classNumber, nil
+}
+
+// compareInts compares the ordered values.
+func compareInts[T int | int64 | uint64](lhs, rhs T) int {
+	switch {
+	case lhs < rhs:
+		return -1
+	case lhs > rhs:
+		return 1
+	}
+	return 0
+}
+
+// Compare compares the converted values in the tarantool order. The result is
+// negative, if lhs is less than rhs, 0, if they are equal, and positive otherwise.
+//
+// Values of different types are ordered: nil, boolean, number, string,
+// varbinary, uuid, datetime, interval, array, map. Within the types:
+//   - false is less than true;
+//   - numbers are compared exactly across unsigned, integer, double and decimal,
+//     NaN is less than other numbers;
+//   - strings and varbinary are compared by bytes, uuids by their binary form;
+//   - datetimes are compared by the instant, regardless of the time zone;
+//   - intervals are compared field by field, from years to nanoseconds;
+//     tarantool doesn't order intervals, so their order is an extension of
+//     the package, that makes Compare total;
+//   - arrays are compared element by element, a prefix is less;
+//   - maps are compared by entries, sorted by keys, the same way.
+func Compare(lhs, rhs any) (int, error) {
+	return compareValues(lhs, rhs, nil)
+}
+
+// compareValues compares the converted values. Strings are compared by
+// the comparator, if it is set.
+func compareValues(lhs, rhs any, compareStrings stringComparator) (int, error) {
+	lhsClass, err := classOf(lhs)
+	if err != nil {
+		return 0, err
+	}
+	rhsClass, err := classOf(rhs)
+	if err != nil {
+		return 0, err
+	}
+	if lhsClass != rhsClass {
+		return compareInts(int(lhsClass), int(rhsClass)), nil
+	}
+	switch lhs := lhs.(type) {
+	case bool:
+		rhs := rhs.(bool)
+		if lhs == rhs {
+			return 0, nil
+		} else if rhs {
+			return -1, nil
+		}
+		return 1, nil
+	case string:
+		if compareStrings != nil {
+			return compareStrings(lhs, rhs.(string)), nil
+		}
+		return strings.Compare(lhs, rhs.(string)), nil
+	case []byte:
+		return bytes.Compare(lhs, rhs.([]byte)), nil
+	case uuid.UUID:
+		rhs := rhs.(uuid.UUID)
+		return bytes.Compare(lhs[:], rhs[:]), nil
+	case datetime.Datetime:
+		rhs := rhs.(datetime.Datetime)
+		lhsTime, rhsTime := lhs.ToTime(), rhs.ToTime()
+		if result := compareInts(lhsTime.Unix(), rhsTime.Unix()); result != 0 {
+			return result, nil
+		}
+		return compareInts(lhsTime.Nanosecond(), rhsTime.Nanosecond()), nil
+	case datetime.Interval:
+		lhsFields, rhsFields := intervalFields(lhs), intervalFields(rhs.(datetime.Interval))
+		for i := range lhsFields {
+			if result := compareInts(lhsFields[i], rhsFields[i]); result != 0 {
+				return result, nil
+			}
+		}
+		return 0, nil
+	case []any:
+		return compareArrays(lhs, rhs.([]any), compareStrings)
+	}
+	if lhsClass == classMap {
+		lhsEntries, err := sortedMapEntries(lhs)
+		if err != nil {
+			return 0, err
+		}
+		rhsEntries, err := sortedMapEntries(rhs)
+		if err != nil {
+			return 0, err
+		}
+		return compareArrays(lhsEntries, rhsEntries, compareStrings)
+	}
+	return compareNumbers(lhs, rhs), nil
+}
+
+// intervalFields returns the fields of the interval in the comparison order.
+func intervalFields(interval datetime.Interval) []int64 {
+	return []int64{interval.Year, interval.Month, interval.Week, interval.Day,
+		interval.Hour, interval.Min, interval.Sec, interval.Nsec, int64(interval.Adjust)}
+}
+
+// compareArrays compares the arrays element by element.
+func compareArrays(lhs, rhs []any, compareStrings stringComparator) (int, error) {
+	for i := 0; i < len(lhs) && i < len(rhs); i++ {
+		result, err := compareValues(lhs[i], rhs[i], compareStrings)
+		if err != nil || result != 0 {
+			return result, err
+		}
+	}
+	return compareInts(len(lhs), len(rhs)), nil
+}
+
+// sortedMapEntries returns keys and values of the map, sorted by keys, as
+// a flat array.
+func sortedMapEntries(value any) ([]any, error) {
+	var keys []any
+	var values []any
+	switch value := value.(type) {
+	case map[string]any:
+		for key, item := range value {
+			keys, values = append(keys, key), append(values, item)
+		}
+	case map[any]any:
+		for key, item := range value {
+			keys, values = append(keys, key), append(values, item)
+		}
+	}
+	order := make([]int, len(keys))
+	for i := range order {
+		order[i] = i
+	}
+	var err error
+	sort.Slice(order, func(i, j int) bool {
+		result, cmpErr := Compare(keys[order[i]], keys[order[j]])
+		if cmpErr != nil && err == nil {
+			err = fmt.Errorf("map key: %w", cmpErr)
+		}
+		return result < 0
+	})
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]any, 0, 2*len(keys))
+	for _, i := range order {
+		entries = append(entries, keys[i], values[i])
+	}
+	return entries, nil
+}
+
+// compareNumbers compares the numbers exactly. NaN is less than other numbers.
+func compareNumbers(lhs, rhs any) int {
+	if lhsInt, ok := toBigInt(lhs); ok {
+		if rhsInt, ok := toBigInt(rhs); ok {
+			return lhsInt.Cmp(rhsInt)
+		}
+	}
+	lhsSpecial, lhsRat := numberRat(lhs)
+	rhsSpecial, rhsRat := numberRat(rhs)
+	if lhsSpecial != 0 || rhsSpecial != 0 {
+		return compareInts(lhsSpecial, rhsSpecial)
+	}
+	return lhsRat.Cmp(rhsRat)
+}
+
+// numberRat converts the number to big.Rat. Special values are ordered by
+// the first result: -2 for NaN, -1 for -Inf, 1 for +Inf and 0 for finite numbers.
+func numberRat(value any) (int, *big.Rat) {
+	switch value := value.(type) {
+	case uint64:
+		return 0, new(big.Rat).SetInt(new(big.Int).SetUint64(value))
+	case int64:
+		return 0, new(big.Rat).SetInt64(value)
+	case float64:
+		switch {
+		case math.IsNaN(value):
+			return -2, nil
+		case math.IsInf(value, -1):
+			return -1, nil
+		case math.IsInf(value, 1):
+			return 1, nil
+		}
+		return 0, new(big.Rat).SetFloat64(value)
+	case decimal.Decimal:
+		return 0, value.Rat()
+	}
+	return 0, new(big.Rat)
+}
+
+// Hash returns the hash of the converted value, that is consistent with
+// Compare: equal values have equal hashes, for example, unsigned 1, double 1
+// and decimal 1.00 or datetimes of the same instant in different time zones.
+func Hash(value any) (uint64, error) {
+	hash := fnv.New64a()
+	if err := hashValue(hash, value); err != nil {
+		return 0, err
+	}
+	return hash.Sum64(), nil
+}
+
+// hashBytes writes the length and the data to the hash.
+func hashBytes(hash hash.Hash64, data []byte) {
+	hash.Write(binary.AppendUvarint(nil, uint64(len(data))))
+	hash.Write(data)
+}
+
+// hashInts writes the integers to the hash.
+func hashInts(hash hash.Hash64, values ...int64) {
+	var buf []byte
+	for _, value := range values {
+		buf = binary.AppendVarint(buf, value)
+	}
+	hash.Write(buf)
+}
+
+// hashValue writes the class and the canonical form of the value to the hash.
+func hashValue(hash hash.Hash64, value any) error {
+	class, err := classOf(value)
+	if err != nil {
+		return err
+	}
+	hash.Write([]byte{byte(class)})
+	switch value := value.(type) {
+	case bool:
+		if value {
+			hash.Write([]byte{1})
+		} else {
+			hash.Write([]byte{0})
+		}
+	case string:
+		hashBytes(hash, []byte(value))
+	case []byte:
+		hashBytes(hash, value)
+	case uuid.UUID:
+		hash.Write(value[:])
+	case datetime.Datetime:
+		tm := value.ToTime()
+		hashInts(hash, tm.Unix(), int64(tm.Nanosecond()))
+	case datetime.Interval:
+		hashInts(hash, intervalFields(value)...)
+	case []any:
+		hashInts(hash, int64(len(value)))
+		for _, item := range value {
+			if err := hashValue(hash, item); err != nil {
+				return err
+			}
+		}
+	default:
+		if class == classMap {
+			entries, err := sortedMapEntries(value)
+			if err != nil {
+				return err
+			}
+			return hashValue(hash, entries)
+		}
+		if class == classNumber {
+			special, rat := numberRat(value)
+			hashInts(hash, int64(special))
+			if rat != nil {
+				hash.Write([]byte{byte(rat.Sign() + 1)})
+				hashBytes(hash, rat.Num().Bytes())
+				hashBytes(hash, rat.Denom().Bytes())
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+package tupleconv_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tarantool/go-tarantool/v2/datetime"
+
+	"github.com/tarantool/go-tupleconv"
+)
+
+func TestCompare(t *testing.T) {
+	utc := mustDatetime(t, time.Date(2023, 8, 30, 12, 6, 5, 0, time.UTC))
+	moscow := mustDatetime(t, time.Date(2023, 8, 30, 15, 6, 5, 0,
+		time.FixedZone("MSK", 3*60*60)))
+	later := mustDatetime(t, time.Date(2023, 8, 30, 12, 6, 5, 1, time.UTC))
+
+	cases := []struct {
+		name     string
+		lhs, rhs any
+		expected int
+	}{
+		// Values of different types are ordered by their types.
+		{"nil < boolean", nil, false, -1},
+		{"boolean < number", true, int64(-100), -1},
+		{"number < string", math.Inf(1), "", -1},
+		{"string < varbinary", "b", []byte("a"), -1},
+		{"varbinary < uuid", []byte{0xff}, uuid.Nil, -1},
+		{"uuid < datetime", uuid.MustParse("ffffffff-ffff-ffff-ffff-ffffffffffff"), utc, -1},
+		// Intervals are not ordered by tarantool, their order is an extension.
+		{"datetime < interval", utc, datetime.Interval{}, -1},
+		{"interval < array", datetime.Interval{Year: 100}, []any{}, -1},
+		{"array < map", []any{"z"}, map[string]any{}, -1},
+		{"map > nil", map[string]any{}, nil, 1},
+
+		{"nil = nil", nil, nil, 0},
+		{"false < true", false, true, -1},
+		{"true = true", true, true, 0},
+
+		// Numbers are compared by their values, regardless of the types.
+		{"unsigned = integer", uint64(1), int64(1), 0},
+		{"unsigned = double", uint64(1), float64(1), 0},
+		{"unsigned = decimal", uint64(1), mustDecimal(t, "1.00"), 0},
+		{"integer < unsigned", int64(-1), uint64(0), -1},
+		{"double < decimal", 0.1, mustDecimal(t, "0.1"), 1},
+		{"large unsigned > double", uint64(math.MaxUint64), float64(1 << 63), 1},
+		{"decimal > unsigned", mustDecimal(t, "18446744073709551616"),
+			uint64(math.MaxUint64), 1},
+		{"-inf < integer", math.Inf(-1), int64(math.MinInt64), -1},
+		{"nan < -inf", math.NaN(), math.Inf(-1), -1},
+		{"nan = nan", math.NaN(), math.NaN(), 0},
+		{"-0 = 0", math.Copysign(0, -1), uint64(0), 0},
+
+		// Strings and varbinary are compared by bytes.
+		{"upper < lower", "Z", "a", -1},
+		{"prefix < string", "ab", "abc", -1},
+		{"binary utf-8", "я", "ё", -1},
+		{"varbinary", []byte{0x01, 0x02}, []byte{0x01}, 1},
+
+		{"uuid", uuid.MustParse("00000000-0000-0000-0000-000000000002"),
+			uuid.MustParse("00000000-0000-0000-0001-000000000001"), -1},
+
+		// Datetimes are compared by the instant.
+		{"datetime time zones", utc, moscow, 0},
+		{"datetime nanoseconds", later, moscow, 1},
+
+		// Intervals are compared field by field, it is an extension of the package.
+		{"interval", datetime.Interval{Month: 1}, datetime.Interval{Day: 40}, 1},
+		{"interval equal", datetime.Interval{Hour: 1}, datetime.Interval{Hour: 1}, 0},
+
+		{"array elements", []any{uint64(1), "b"}, []any{float64(1), "a"}, 1},
+		{"array prefix", []any{uint64(1)}, []any{uint64(1), nil}, -1},
+		{"array equal", []any{uint64(1), []any{"a"}}, []any{int64(1), []any{"a"}}, 0},
+
+		{"map equal", map[string]any{"a": uint64(1), "b": "x"},
+			map[any]any{"b": "x", "a": float64(1)}, 0},
+		{"map values", map[string]any{"a": uint64(1)}, map[string]any{"a": uint64(2)}, -1},
+		{"map keys", map[string]any{"b": uint64(1)}, map[string]any{"a": uint64(2)}, 1},
+		{"map size", map[string]any{"a": nil}, map[string]any{"a": nil, "b": nil}, -1},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := tupleconv.Compare(tc.lhs, tc.rhs)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, result)
+
+			result, err = tupleconv.Compare(tc.rhs, tc.lhs)
+			require.NoError(t, err)
+			assert.Equal(t, -tc.expected, result)
+
+			if tc.expected == 0 {
+				lhsHash, err := tupleconv.Hash(tc.lhs)
+				require.NoError(t, err)
+				rhsHash, err := tupleconv.Hash(tc.rhs)
+				require.NoError(t, err)
+				assert.Equal(t, lhsHash, rhsHash)
+			}
+		})
+	}
+}
+
+func TestHash(t *testing.T) {
+	values := []any{
+		nil,
+		false,
+		true,
+		uint64(0),
+		uint64(1),
+		int64(-1),
+		0.5,
+		math.Inf(1),
+		math.NaN(),
+		mustDecimal(t, "0.25"),
+		"",
+		"1",
+		[]byte{},
+		[]byte("1"),
+		uuid.Nil,
+		mustDatetime(t, time.Date(2023, 8, 30, 12, 6, 5, 0, time.UTC)),
+		datetime.Interval{},
+		datetime.Interval{Sec: 1},
+		[]any{},
+		[]any{nil},
+		[]any{"a", "b"},
+		[]any{"ab"},
+		map[string]any{},
+		map[string]any{"a": "b"},
+		map[string]any{"b": "a"},
+	}
+	hashes := make(map[uint64]any, len(values))
+	for _, value := range values {
+		hash, err := tupleconv.Hash(value)
+		require.NoError(t, err)
+		if prev, ok := hashes[hash]; ok {
+			t.Errorf("hash collision of %v and %v", prev, value)
+		}
+		hashes[hash] = value
+	}
+}
+
+func TestCompare_errors(t *testing.T) {
+	_, err := tupleconv.Compare(1, uint64(1))
+	assert.EqualError(t, err, "unexpected value of type int")
+	_, err = tupleconv.Compare([]any{"a"}, []any{int32(1)})
+	assert.EqualError(t, err, "unexpected value of type int32")
+	_, err = tupleconv.Compare(map[any]any{"a": nil, int8(1): nil}, map[any]any{})
+	assert.EqualError(t, err, "map key: unexpected value of type int8")
+	_, err = tupleconv.Hash(struct{}{})
+	assert.EqualError(t, err, "unexpected value of type struct {}")
+}
@@ -0,0 +1,87 @@
+package tupleconv
+
+import (
+	"context"
+)
+
+// ContextConverter is a converter from S to T, that takes a context. The context
+// allows to cancel the conversion and to pass request-scoped values, such as a
+// tenant or a time zone, to the converters, that call out to lookups or remote
+// dictionaries.
+//
+// The combinators of the package and the converters, made by the factories,
+// implement both Converter and ContextConverter and pass the context to the wrapped
+// converters, that implement ContextConverter. Convert is called with
+// context.Background() then.
+type ContextConverter[S any, T any] interface {
+	ConvertContext(ctx context.Context, src S) (T, error)
+}
+
+var (
+	_ Converter[string, any]        = (*FuncContextConverter[string, any])(nil)
+	_ ContextConverter[string, any] = (*FuncContextConverter[string, any])(nil)
+)
+
+// FuncContextConverter is a function-based ContextConverter. It is a Converter too,
+// Convert calls the function with context.Background().
+type FuncContextConverter[S any, T any] struct {
+	convFunc func(context.Context, S) (T, error)
+}
+
+// MakeFuncContextConverter creates FuncContextConverter.
+func MakeFuncContextConverter[S any, T any](
+	convFunc func(context.Context, S) (T, error)) FuncContextConverter[S, T] {
+	return FuncContextConverter[S, T]{convFunc: convFunc}
+}
+
+// Convert is the implementation of Converter for FuncContextConverter.
+func (conv FuncContextConverter[S, T]) Convert(src S) (T, error) {
+	return conv.convFunc(context.Background(), src)
+}
+
+// ConvertContext is the implementation of ContextConverter for FuncContextConverter.
+func (conv FuncContextConverter[S, T]) ConvertContext(ctx context.Context, src S) (T, error) {
+	return conv.convFunc(ctx, src)
+}
+
+// MakeContextConverter adapts the Converter to ContextConverter. The converter is
+// returned as is, if it implements ContextConverter. Otherwise, the context is
+// ignored.
+func MakeContextConverter[S any, T any](converter Converter[S, T]) ContextConverter[S, T] {
+	if ctxConverter, ok := converter.(ContextConverter[S, T]); ok {
+		return ctxConverter
+	}
+	return MakeFuncContextConverter(func(_ context.Context, src S) (T, error) {
+		return converter.Convert(src)
+	})
+}
+
+// boundContextConverter is a Converter, that calls the ContextConverter with
+// the fixed context.
+type boundContextConverter[S any, T any] struct {
+	ctx       context.Context
+	converter ContextConverter[S, T]
+}
+
+// Convert is the implementation of Converter for boundContextConverter.
+func (conv boundContextConverter[S, T]) Convert(src S) (T, error) {
+	return conv.converter.ConvertContext(conv.ctx, src)
+}
+
+// MakeConverterWithContext adapts the ContextConverter to Converter, that calls it
+// with the context. The context of MapContext and ConvertContext of the wrapping
+// converters is not passed to the result.
+func MakeConverterWithContext[S any, T any](
+	ctx context.Context, converter ContextConverter[S, T]) Converter[S, T] {
+	return boundContextConverter[S, T]{ctx: ctx, converter: converter}
+}
+
+// convertContext converts the value with the converter. The context is passed to
+// the converter, if it implements ContextConverter.
+func convertContext[S any, T any](ctx context.Context, converter Converter[S, T],
+	src S) (T, error) {
+	if ctxConverter, ok := converter.(ContextConverter[S, T]); ok {
+		return ctxConverter.ConvertContext(ctx, src)
+	}
+	return converter.Convert(src)
+}
@@ -0,0 +1,214 @@
+package tupleconv_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tarantool/go-tupleconv"
+)
+
+type tenantKey struct{}
+
+// tenantConverter is a context-aware converter, that prefixes the value with
+// the tenant from the context.
+var tenantConverter = tupleconv.MakeFuncContextConverter(
+	func(ctx context.Context, src string) (any, error) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		tenant, ok := ctx.Value(tenantKey{}).(string)
+		if !ok {
+			return nil, errors.New("no tenant")
+		}
+		return tenant + ":" + src, nil
+	})
+
+// tenantConvFactory is a factory with the context-aware string converter.
+type tenantConvFactory struct {
+	tupleconv.StringToTTConvFactory
+}
+
+func (tenantConvFactory) GetStringConverter() tupleconv.Converter[string, any] {
+	return tenantConverter
+}
+
+func TestFuncContextConverter(t *testing.T) {
+	ctx := context.WithValue(context.Background(), tenantKey{}, "t1")
+	result, err := tenantConverter.ConvertContext(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, "t1:a", result)
+
+	_, err = tenantConverter.Convert("a")
+	assert.EqualError(t, err, "no tenant")
+}
+
+func TestMakeContextConverter(t *testing.T) {
+	ctx := context.WithValue(context.Background(), tenantKey{}, "t1")
+
+	result, err := tupleconv.MakeContextConverter[string, any](tenantConverter).
+		ConvertContext(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, "t1:a", result)
+
+	plain := tupleconv.MakeContextConverter[string, any](
+		tupleconv.MakeStringToUIntConverter(""))
+	result, err = plain.ConvertContext(ctx, "12")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(12), result)
+}
+
+func TestMakeConverterWithContext(t *testing.T) {
+	ctx := context.WithValue(context.Background(), tenantKey{}, "t1")
+	bound := tupleconv.MakeConverterWithContext[string, any](ctx, tenantConverter)
+	result, err := bound.Convert("a")
+	require.NoError(t, err)
+	assert.Equal(t, "t1:a", result)
+
+	// The bound context is not replaced by the context of the mapping.
+	other := context.WithValue(context.Background(), tenantKey{}, "t2")
+	tuple, err := tupleconv.MakeMapper([]tupleconv.Converter[string, any]{bound}).
+		MapContext(other, []string{"b"})
+	require.NoError(t, err)
+	assert.Equal(t, []any{"t1:b"}, tuple)
+}
+
+func TestCombinators_passContext(t *testing.T) {
+	ctx := context.WithValue(context.Background(), tenantKey{}, "t1")
+	toString := tupleconv.MakeMapResultConverter[string, any, string](tenantConverter,
+		func(value any) string { return value.(string) })
+
+	cases := []struct {
+		name      string
+		converter tupleconv.Converter[string, any]
+		expected  any
+	}{
+		{
+			name: "sequence",
+			converter: tupleconv.MakeSequenceConverter([]tupleconv.Converter[string, any]{
+				tupleconv.MakeStringToUIntConverter(""), tenantConverter,
+			}),
+			expected: "t1:a",
+		},
+		{
+			name: "chain",
+			converter: tupleconv.MakeChainConverter[string, string, any](
+				toString, tupleconv.MakeIdentityConverter[string]()),
+			expected: "t1:a",
+		},
+		{
+			name: "validate",
+			converter: tupleconv.MakeValidateConverter[string, any](tenantConverter,
+				func(any) error { return nil }),
+			expected: "t1:a",
+		},
+		{
+			name: "fallback",
+			converter: tupleconv.MakeFallbackConverter[string, any](tenantConverter,
+				"fallback"),
+			expected: "t1:a",
+		},
+		{
+			name:      "optional",
+			converter: tupleconv.MakeOptionalConverter[string, any](tenantConverter),
+			expected:  "t1:a",
+		},
+		{
+			name: "wrap error",
+			converter: tupleconv.MakeWrapErrorConverter[string, any](tenantConverter,
+				"tenant"),
+			expected: "t1:a",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := tupleconv.MakeContextConverter(tc.converter).ConvertContext(ctx, "a")
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, result)
+		})
+	}
+}
+
+func TestMakeFallbackConverter_doneContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	converter := tupleconv.MakeFallbackConverter[string, any](tenantConverter, "fallback")
+	_, err := tupleconv.MakeContextConverter(converter).ConvertContext(ctx, "a")
+	assert.ErrorIs(t, err, context.Canceled)
+
+	result, err := converter.Convert("a")
+	require.NoError(t, err)
+	assert.Equal(t, "fallback", result)
+}
+
+func TestMapper_MapContext(t *testing.T) {
+	spaceFmt := []tupleconv.SpaceField{
+		{Name: "id", Type: tupleconv.TypeUnsigned},
+		{Name: "name", Type: tupleconv.TypeString, IsNullable: true},
+		{Name: "code", Type: tupleconv.TypeString},
+	}
+	fac := tenantConvFactory{
+		StringToTTConvFactory: tupleconv.MakeStringToTTConvFactory().
+			WithNullValue("NULL").
+			WithNormalization(tupleconv.NormalizeOptions{Trim: true}),
+	}
+	converters, err := tupleconv.MakeTypeToTTConverters[string](fac, spaceFmt)
+	require.NoError(t, err)
+	mapper := tupleconv.MakeMapper(converters)
+
+	ctx := context.WithValue(context.Background(), tenantKey{}, "t1")
+	tuple, err := mapper.MapContext(ctx, []string{"1", " a ", "b"})
+	require.NoError(t, err)
+	assert.Equal(t, []any{uint64(1), "t1:a", "t1:b"}, tuple)
+
+	tuple, err = mapper.MapContext(ctx, []string{"1", "NULL", "b"})
+	require.NoError(t, err)
+	assert.Equal(t, []any{uint64(1), nil, "t1:b"}, tuple)
+
+	// Map uses context.Background().
+	_, err = mapper.Map([]string{"1", "a", "b"})
+	assert.EqualError(t, err, `unexpected value a for type "string"`)
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	tuple, err = mapper.MapContext(cancelled, []string{"1", "a", "b"})
+	assert.Nil(t, tuple)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestMapper_MapContext_cancelDuringMapping(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	canceling := tupleconv.MakeFuncConverter(func(src string) (any, error) {
+		cancel()
+		return src, nil
+	})
+	converters, err := tupleconv.MakeTypeToTTConverters[string](
+		tenantConvFactory{StringToTTConvFactory: tupleconv.MakeStringToTTConvFactory()},
+		[]tupleconv.SpaceField{{Type: tupleconv.TypeString}})
+	require.NoError(t, err)
+	mapper := tupleconv.MakeMapper(append(
+		[]tupleconv.Converter[string, any]{canceling}, converters...))
+
+	_, err = mapper.MapContext(ctx, []string{"a", "b"})
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestBatchMapper_passContext(t *testing.T) {
+	mapper := tupleconv.MakeMapper([]tupleconv.Converter[string, any]{tenantConverter})
+	tuples := make([][]string, 100)
+	for i := range tuples {
+		tuples[i] = []string{fmt.Sprint(i)}
+	}
+
+	ctx := context.WithValue(context.Background(), tenantKey{}, "t1")
+	result, err := tupleconv.MakeBatchMapper(mapper).WithWorkers(4).MapBatch(ctx, tuples)
+	require.NoError(t, err)
+	for i, tuple := range result {
+		assert.Equal(t, []any{fmt.Sprintf("t1:%d", i)}, tuple)
+	}
+}
@@ -1,12 +1,14 @@
 package tupleconv
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
 	"github.com/tarantool/go-tarantool/v2/datetime"
@@ -14,6 +16,10 @@ import (
 )
 
 // Converter is a converter from S to T.
+//
+// All converters of the package, including the ones created by the factories and
+// the combinators, are immutable and safe for concurrent use, if the wrapped
+// converters and functions are.
 type Converter[S any, T any] interface {
 	Convert(src S) (T, error)
 }
@@ -27,9 +33,11 @@ var (
 	_ Converter[string, any] = (*StringToDecimalConverter)(nil)
 	_ Converter[string, any] = (*StringToUUIDConverter)(nil)
 	_ Converter[string, any] = (*StringToDatetimeConverter)(nil)
+	_ Converter[string, any] = (*StringToDatetimeLayoutsConverter)(nil)
 	_ Converter[string, any] = (*StringToMapConverter)(nil)
 	_ Converter[string, any] = (*StringToSliceConverter)(nil)
 	_ Converter[string, any] = (*StringToNullConverter)(nil)
+	_ Converter[string, any] = (*StringToNullValuesConverter)(nil)
 	_ Converter[string, any] = (*IdentityConverter[string])(nil)
 	_ Converter[string, any] = (*StringToIntervalConverter)(nil)
 
@@ -67,23 +75,168 @@ func (conv FuncConverter[S, T]) Convert(src S) (T, error) {
 
 // MakeSequenceConverter makes a sequential Converter from a Converter list.
 func MakeSequenceConverter[S any, T any](converters []Converter[S, T]) Converter[S, T] {
-	return MakeFuncConverter(func(src S) (T, error) {
+	return MakeFuncContextConverter(func(ctx context.Context, src S) (T, error) {
+		var ret T
 		for _, conv := range converters {
-			if result, err := conv.Convert(src); err == nil {
+			if result, err := convertContext(ctx, conv, src); err == nil {
 				return result, nil
 			}
+			if err := ctx.Err(); err != nil {
+				return ret, err
+			}
 		}
-		var ret T
-		return ret, fmt.Errorf("unexpected value %v", src)
+		return ret, fmt.Errorf("unexpected value %v", printableValue(src))
 	})
 }
 
-// replaceSeparators replaces all characters from `charsToReplace` with a specific string.
-func replaceCharacters(src, charsToReplace, replaceTo string) string {
-	for _, char := range charsToReplace {
-		src = strings.ReplaceAll(src, string(char), replaceTo)
+// printableValue returns the value for error messages. Byte slices are printed
+// as strings.
+func printableValue(value any) any {
+	if bytes, ok := value.([]byte); ok {
+		return string(bytes)
 	}
-	return src
+	return value
+}
+
+// MakeChainConverter makes a Converter from S to T, that converts S to M with the first
+// converter and then M to T with the second one.
+func MakeChainConverter[S any, M any, T any](
+	first Converter[S, M], second Converter[M, T]) Converter[S, T] {
+	return MakeFuncContextConverter(func(ctx context.Context, src S) (T, error) {
+		middle, err := convertContext(ctx, first, src)
+		if err != nil {
+			var ret T
+			return ret, err
+		}
+		return convertContext(ctx, second, middle)
+	})
+}
+
+// MakeValidateConverter makes a Converter, that checks the result of the converter
+// with the validator. The validator error is returned as the conversion error.
+func MakeValidateConverter[S any, T any](
+	converter Converter[S, T], validate func(T) error) Converter[S, T] {
+	return MakeFuncContextConverter(func(ctx context.Context, src S) (T, error) {
+		result, err := convertContext(ctx, converter, src)
+		if err == nil {
+			err = validate(result)
+		}
+		if err != nil {
+			var ret T
+			return ret, err
+		}
+		return result, nil
+	})
+}
+
+// MakeMapResultConverter makes a Converter, that transforms the result of the converter
+// with the function.
+func MakeMapResultConverter[S any, T any, R any](
+	converter Converter[S, T], mapFunc func(T) R) Converter[S, R] {
+	return MakeFuncContextConverter(func(ctx context.Context, src S) (R, error) {
+		result, err := convertContext(ctx, converter, src)
+		if err != nil {
+			var ret R
+			return ret, err
+		}
+		return mapFunc(result), nil
+	})
+}
+
+// MakeFallbackConverter makes a Converter, that returns the fallback value if
+// the converter fails. Errors of the done context are returned as is.
+func MakeFallbackConverter[S any, T any](converter Converter[S, T], fallback T) Converter[S, T] {
+	return MakeFuncContextConverter(func(ctx context.Context, src S) (T, error) {
+		result, err := convertContext(ctx, converter, src)
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				var ret T
+				return ret, ctxErr
+			}
+			return fallback, nil
+		}
+		return result, nil
+	})
+}
+
+// MakeOptionalConverter makes a Converter, that returns the zero value of T for
+// the zero value of S without calling the converter.
+func MakeOptionalConverter[S comparable, T any](converter Converter[S, T]) Converter[S, T] {
+	return MakeFuncContextConverter(func(ctx context.Context, src S) (T, error) {
+		var zero S
+		if src == zero {
+			var ret T
+			return ret, nil
+		}
+		return convertContext(ctx, converter, src)
+	})
+}
+
+// MakeWrapErrorConverter makes a Converter, that wraps errors of the converter
+// with the message: "<message>: <error>".
+func MakeWrapErrorConverter[S any, T any](
+	converter Converter[S, T], message string) Converter[S, T] {
+	return MakeFuncContextConverter(func(ctx context.Context, src S) (T, error) {
+		result, err := convertContext(ctx, converter, src)
+		if err != nil {
+			var ret T
+			return ret, fmt.Errorf("%s: %w", message, err)
+		}
+		return result, nil
+	})
+}
+
+// numberBufSize is the size of the stack buffer for numbers with separators.
+// Longer numbers are handled too, but with an allocation.
+const numberBufSize = 64
+
+// appendCleanNumber appends the number to the buffer in a single pass, removing
+// the characters from ignoreChars and replacing the characters from decSeparators
+// with '.'. If the number contains none of the characters, the buffer is returned
+// unchanged and ok is false, so the number could be parsed as is. The number
+// is not scanned at all, if there are no ignored characters and '.' is the only
+// decimal separator.
+func appendCleanNumber(buf []byte, src, ignoreChars, decSeparators string) ([]byte, bool) {
+	if ignoreChars == "" && (decSeparators == "" || decSeparators == ".") {
+		return buf, false
+	}
+	changed := false
+	for i := 0; i < len(src); {
+		char, size := utf8.DecodeRuneInString(src[i:])
+		ignored := strings.ContainsRune(ignoreChars, char)
+		if ignored || char != '.' && strings.ContainsRune(decSeparators, char) {
+			if !changed {
+				buf = append(buf, src[:i]...)
+				changed = true
+			}
+			if !ignored {
+				buf = append(buf, '.')
+			}
+		} else if changed {
+			buf = append(buf, src[i:i+size]...)
+		}
+		i += size
+	}
+	return buf, changed
+}
+
+// makeIntegerConverter makes the converter, that converts numbers by the unsigned
+// converter and then by the signed one. Negative numbers are converted by the
+// signed converter only, so no error of the unsigned one is allocated for them.
+func makeIntegerConverter[S string | []byte](
+	unsigned, signed Converter[S, any], ignoreChars string) Converter[S, any] {
+	sequence := MakeSequenceConverter([]Converter[S, any]{unsigned, signed})
+	if strings.Contains(ignoreChars, "-") {
+		return sequence
+	}
+	return MakeFuncContextConverter(func(ctx context.Context, src S) (any, error) {
+		if len(src) > 0 && src[0] == '-' {
+			if result, err := convertContext(ctx, signed, src); err == nil {
+				return result, nil
+			}
+		}
+		return convertContext(ctx, sequence, src)
+	})
 }
 
 // StringToBoolConverter is a converter from string to bool.
@@ -111,7 +264,10 @@ func MakeStringToUIntConverter(ignoreChars string) StringToUIntConverter {
 
 // Convert is the implementation of Converter[string, any] for StringToUIntConverter.
 func (conv StringToUIntConverter) Convert(src string) (any, error) {
-	src = replaceCharacters(src, conv.ignoreChars, "")
+	var buf [numberBufSize]byte
+	if cleaned, ok := appendCleanNumber(buf[:0], src, conv.ignoreChars, ""); ok {
+		return strconv.ParseUint(string(cleaned), 10, 64)
+	}
 	return strconv.ParseUint(src, 10, 64)
 }
 
@@ -127,7 +283,10 @@ func MakeStringToIntConverter(ignoreChars string) StringToIntConverter {
 
 // Convert is the implementation of Converter[string, any] for StringToIntConverter.
 func (conv StringToIntConverter) Convert(src string) (any, error) {
-	src = replaceCharacters(src, conv.ignoreChars, "")
+	var buf [numberBufSize]byte
+	if cleaned, ok := appendCleanNumber(buf[:0], src, conv.ignoreChars, ""); ok {
+		return strconv.ParseInt(string(cleaned), 10, 64)
+	}
 	return strconv.ParseInt(src, 10, 64)
 }
 
@@ -144,8 +303,11 @@ func MakeStringToFloatConverter(ignoreChars, decSeparators string) StringToFloat
 
 // Convert is the implementation of Converter[string, any] for StringToFloatConverter.
 func (conv StringToFloatConverter) Convert(src string) (any, error) {
-	src = replaceCharacters(src, conv.ignoreChars, "")
-	src = replaceCharacters(src, conv.decSeparators, ".")
+	var buf [numberBufSize]byte
+	cleaned, ok := appendCleanNumber(buf[:0], src, conv.ignoreChars, conv.decSeparators)
+	if ok {
+		return strconv.ParseFloat(string(cleaned), 64)
+	}
 	return strconv.ParseFloat(src, 64)
 }
 
@@ -162,8 +324,11 @@ func MakeStringToDecimalConverter(ignoreChars, decSeparators string) StringToDec
 
 // Convert is the implementation of Converter[string, any] for StringToDecimalConverter.
 func (conv StringToDecimalConverter) Convert(src string) (any, error) {
-	src = replaceCharacters(src, conv.ignoreChars, "")
-	src = replaceCharacters(src, conv.decSeparators, ".")
+	var buf [numberBufSize]byte
+	cleaned, ok := appendCleanNumber(buf[:0], src, conv.ignoreChars, conv.decSeparators)
+	if ok {
+		return decimal.MakeDecimalFromString(string(cleaned))
+	}
 	return decimal.MakeDecimalFromString(src)
 }
 
@@ -219,6 +384,39 @@ func (StringToDatetimeConverter) Convert(src string) (any, error) {
 	return datetime.MakeDatetime(tm)
 }
 
+// makeDatetime creates datetime.Datetime from time.Time. The local time zone
+// is not supported by tarantool, so it is replaced with the fixed offset.
+func makeDatetime(tm time.Time) (datetime.Datetime, error) {
+	if tm.Location() == time.Local {
+		_, offset := tm.Zone()
+		tm = tm.In(time.FixedZone(datetime.NoTimezone, offset))
+	}
+	return datetime.MakeDatetime(tm)
+}
+
+// StringToDatetimeLayoutsConverter is a converter from string to datetime.Datetime
+// with custom time layouts. Layouts are tried in order. Times without time zone
+// are parsed as UTC.
+type StringToDatetimeLayoutsConverter struct {
+	layouts []string
+}
+
+// MakeStringToDatetimeLayoutsConverter creates StringToDatetimeLayoutsConverter.
+func MakeStringToDatetimeLayoutsConverter(layouts []string) StringToDatetimeLayoutsConverter {
+	return StringToDatetimeLayoutsConverter{layouts: layouts}
+}
+
+// Convert is the implementation of Converter[string, any] for
+// StringToDatetimeLayoutsConverter.
+func (conv StringToDatetimeLayoutsConverter) Convert(src string) (any, error) {
+	for _, layout := range conv.layouts {
+		if tm, err := time.Parse(layout, src); err == nil {
+			return makeDatetime(tm)
+		}
+	}
+	return nil, fmt.Errorf("unexpected datetime format: %s", src)
+}
+
 // StringToMapConverter is a converter from string to map.
 // Only `json` is supported now.
 type StringToMapConverter struct{}
@@ -288,6 +486,27 @@ func (conv StringToNullConverter) Convert(src string) (any, error) {
 	return nil, nil
 }
 
+// StringToNullValuesConverter is a converter from string to nil, that accepts
+// several null values.
+type StringToNullValuesConverter struct {
+	nullValues []string
+}
+
+// MakeStringToNullValuesConverter creates StringToNullValuesConverter.
+func MakeStringToNullValuesConverter(nullValues []string) StringToNullValuesConverter {
+	return StringToNullValuesConverter{nullValues: append([]string{}, nullValues...)}
+}
+
+// Convert is the implementation of Converter[string, any] for StringToNullValuesConverter.
+func (conv StringToNullValuesConverter) Convert(src string) (any, error) {
+	for _, nullValue := range conv.nullValues {
+		if src == nullValue {
+			return nil, nil
+		}
+	}
+	return nil, fmt.Errorf("unexpected value: %v", src)
+}
+
 // StringToIntervalConverter is a converter from string to datetime.Interval.
 type StringToIntervalConverter struct{}
 
@@ -1,8 +1,10 @@
 package tupleconv_test
 
 import (
+	"errors"
 	"fmt"
 	"math/big"
+	"strings"
 	"testing"
 	"time"
 
@@ -311,6 +313,119 @@ func TestConverters(t *testing.T) {
 	}
 }
 
+func TestMakeStringToNullValuesConverter(t *testing.T) {
+	converter := tupleconv.MakeStringToNullValuesConverter([]string{"NULL", `\N`, "n/a"})
+	cases := []convCase[string, any]{
+		// Basic.
+		{value: "NULL", expected: nil},
+		{value: `\N`, expected: nil},
+		{value: "n/a", expected: nil},
+
+		// Error.
+		{value: "", isErr: true},
+		{value: "null", isErr: true},
+		{value: "N/A", isErr: true},
+	}
+	HelperTestConverter[string, any](t, converter, cases)
+
+	empty := tupleconv.MakeStringToNullValuesConverter(nil)
+	HelperTestConverter[string, any](t, empty, []convCase[string, any]{
+		{value: "", isErr: true},
+	})
+}
+
+func TestNumberConverters_separators(t *testing.T) {
+	thSeparators := "\u00a0\u202f'"
+	decSeparators := ",\u066b'"
+	long := strings.Repeat("1'", 40) + "5"
+
+	HelperTestConverter[string, any](t,
+		tupleconv.MakeStringToUIntConverter(thSeparators),
+		[]convCase[string, any]{
+			{value: "1\u00a0000\u202f000", expected: uint64(1000000)},
+			{value: "1'000", expected: uint64(1000)},
+			{value: "\u00a0", isErr: true},
+			{value: long, isErr: true}, // Too big.
+			{value: "1\u00a0\xff", isErr: true},
+		})
+	HelperTestConverter[string, any](t,
+		tupleconv.MakeStringToIntConverter(thSeparators),
+		[]convCase[string, any]{
+			{value: "-1\u00a0000", expected: int64(-1000)},
+			{value: "-1'000", expected: int64(-1000)},
+		})
+	HelperTestConverter[string, any](t,
+		tupleconv.MakeStringToFloatConverter(thSeparators, decSeparators),
+		[]convCase[string, any]{
+			// Removal of the thousand separators goes first.
+			{value: "1'000,5", expected: 1000.5},
+			{value: "1\u00a0000\u066b25", expected: 1000.25},
+			{value: "1.5", expected: 1.5},
+			{value: long, expected: 1.1111111111111111e+40},
+			{value: "1,5,5", isErr: true},
+		})
+	HelperTestConverter[string, any](t,
+		tupleconv.MakeStringToDecimalConverter(thSeparators, decSeparators),
+		[]convCase[string, any]{
+			{
+				value:    "1\u202f000,5",
+				expected: decimal.Decimal{Decimal: dec.NewFromBigInt(big.NewInt(10005), -1)},
+			},
+			{value: "1,5,5", isErr: true},
+		})
+}
+
+func TestNumberConverters_allocs(t *testing.T) {
+	cases := []struct {
+		name string
+		conv tupleconv.Converter[string, any]
+		src  string
+	}{
+		{"unsigned", tupleconv.MakeStringToUIntConverter(""), "100500"},
+		{"unsigned separators", tupleconv.MakeStringToUIntConverter(" '"), "100 500"},
+		{"integer separators", tupleconv.MakeStringToIntConverter(" '"), "-4'200"},
+		{"double", tupleconv.MakeStringToFloatConverter("", "."), "1.5"},
+		{"double separators", tupleconv.MakeStringToFloatConverter(" ", ","), "1 000,5"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			allocs := testing.AllocsPerRun(100, func() {
+				if _, err := tc.conv.Convert(tc.src); err != nil {
+					t.Fatal(err)
+				}
+			})
+			// The only allocation is the result, boxed into any.
+			assert.Equal(t, 1.0, allocs)
+		})
+	}
+}
+
+func TestMakeStringToDatetimeLayoutsConverter(t *testing.T) {
+	converter := tupleconv.MakeStringToDatetimeLayoutsConverter([]string{
+		"02.01.2006 15:04",
+		"2006/01/02 15:04:05 -0700",
+	})
+	cases := []convCase[string, any]{
+		// Basic.
+		{
+			value: "30.08.2023 12:06",
+			expected: getDatetimeWithValidate(t,
+				time.Date(2023, 8, 30, 12, 6, 0, 0, time.UTC)),
+		},
+		{
+			value: "2023/08/30 12:06:05 +0300",
+			expected: getDatetimeWithValidate(t,
+				time.Date(2023, 8, 30, 12, 6, 5, 0, time.FixedZone("", 3*60*60))),
+		},
+
+		// Error.
+		{value: "", isErr: true},
+		{value: "2023-08-30T12:06:05Z", isErr: true},
+		{value: "30.08.2023", isErr: true},
+	}
+	HelperTestConverter[string, any](t, converter, cases)
+}
+
 func TestMakeDatetimeToStringConverter(t *testing.T) {
 	parisLoc, err := time.LoadLocation("Europe/Paris")
 	require.NoError(t, err)
@@ -387,3 +502,124 @@ func TestMakeSequenceConverter(t *testing.T) {
 	}
 	HelperTestConverter(t, parser, cases)
 }
+
+func TestMakeChainConverter(t *testing.T) {
+	normalizer := tupleconv.MakeStringNormalizer(tupleconv.NormalizeOptions{Trim: true})
+	converter := tupleconv.MakeChainConverter[string, string, any](
+		normalizer, tupleconv.MakeStringToUIntConverter(""))
+
+	cases := []convCase[string, any]{
+		// Basic.
+		{value: " 12 ", expected: uint64(12)},
+		{value: "0", expected: uint64(0)},
+
+		// Error.
+		{value: " -1 ", isErr: true},
+		{value: "", isErr: true},
+	}
+	HelperTestConverter(t, converter, cases)
+
+	failing := tupleconv.MakeChainConverter[string, string, any](
+		tupleconv.MakeFuncConverter(func(string) (string, error) {
+			return "", errors.New("first")
+		}),
+		tupleconv.MakeFuncConverter(func(string) (any, error) {
+			t.Fatal("the second converter must not be called")
+			return nil, nil
+		}))
+	_, err := failing.Convert("1")
+	assert.EqualError(t, err, "first")
+}
+
+func TestMakeValidateConverter(t *testing.T) {
+	converter := tupleconv.MakeValidateConverter[string, int64](
+		tupleconv.MakeMapResultConverter[string, any, int64](
+			tupleconv.MakeStringToIntConverter(""),
+			func(value any) int64 { return value.(int64) }),
+		func(value int64) error {
+			if value < 0 || value > 100 {
+				return fmt.Errorf("%d is out of range", value)
+			}
+			return nil
+		})
+
+	cases := []convCase[string, int64]{
+		// Basic.
+		{value: "0", expected: 0},
+		{value: "100", expected: 100},
+
+		// Error.
+		{value: "101", isErr: true},
+		{value: "-1", isErr: true},
+		{value: "abc", isErr: true},
+	}
+	HelperTestConverter(t, converter, cases)
+
+	_, err := converter.Convert("101")
+	assert.EqualError(t, err, "101 is out of range")
+}
+
+func TestMakeMapResultConverter(t *testing.T) {
+	converter := tupleconv.MakeMapResultConverter[string, any, string](
+		tupleconv.MakeStringToBoolConverter(),
+		func(value any) string { return fmt.Sprintf("<%v>", value) })
+
+	cases := []convCase[string, string]{
+		// Basic.
+		{value: "true", expected: "<true>"},
+		{value: "0", expected: "<false>"},
+
+		// Error.
+		{value: "yes", isErr: true},
+	}
+	HelperTestConverter(t, converter, cases)
+}
+
+func TestMakeFallbackConverter(t *testing.T) {
+	converter := tupleconv.MakeFallbackConverter[string, any](
+		tupleconv.MakeStringToUIntConverter(""), uint64(0))
+
+	cases := []convCase[string, any]{
+		{value: "12", expected: uint64(12)},
+		{value: "abc", expected: uint64(0)},
+		{value: "", expected: uint64(0)},
+	}
+	HelperTestConverter(t, converter, cases)
+}
+
+func TestMakeOptionalConverter(t *testing.T) {
+	converter := tupleconv.MakeOptionalConverter[string, any](
+		tupleconv.MakeStringToUUIDConverter())
+	someUUID, err := uuid.Parse("09b56913-11f0-4fa4-b5d0-901b5efa532a")
+	require.NoError(t, err)
+
+	cases := []convCase[string, any]{
+		// Basic.
+		{value: "", expected: nil},
+		{value: "09b56913-11f0-4fa4-b5d0-901b5efa532a", expected: someUUID},
+
+		// Error.
+		{value: "not uuid", isErr: true},
+	}
+	HelperTestConverter(t, converter, cases)
+}
+
+func TestMakeWrapErrorConverter(t *testing.T) {
+	someError := errors.New("some error")
+	converter := tupleconv.MakeWrapErrorConverter[string, any](
+		tupleconv.MakeFuncConverter(func(src string) (any, error) {
+			if src == "bad" {
+				return "partial", someError
+			}
+			return src, nil
+		}), "column \"price\"")
+
+	result, err := converter.Convert("good")
+	assert.NoError(t, err)
+	assert.Equal(t, "good", result)
+
+	result, err = converter.Convert("bad")
+	assert.Nil(t, result)
+	assert.EqualError(t, err, `column "price": some error`)
+	assert.ErrorIs(t, err, someError)
+}
@@ -0,0 +1,251 @@
+package tupleconv
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+
+	"github.com/tarantool/go-tarantool/v2"
+	"github.com/tarantool/go-tarantool/v2/datetime"
+	"github.com/tarantool/go-tarantool/v2/decimal"
+)
+
+// UpdateOperator is an operator of the tarantool update operation.
+type UpdateOperator string
+
+// Update operators, produced by TupleDiffer.
+const (
+	OperatorAssign   UpdateOperator = "="
+	OperatorAdd      UpdateOperator = "+"
+	OperatorSubtract UpdateOperator = "-"
+	OperatorDelete   UpdateOperator = "#"
+)
+
+// UpdateOperation is a tarantool update operation.
+type UpdateOperation struct {
+	// Operator is the operator.
+	Operator UpdateOperator
+	// Field is the zero-based field number, like in go-tarantool.
+	Field int
+	// Arg is the operation argument. For OperatorDelete it is the number of
+	// fields to delete.
+	Arg any
+}
+
+// TupleDiffer generates update operations, that turn the old tuple into the new one.
+type TupleDiffer struct {
+	spaceFmt  []SpaceField
+	isKey     []bool
+	useDeltas bool
+}
+
+// MakeTupleDiffer creates TupleDiffer by the space format and the names of
+// the primary key fields. Primary key fields are never updated.
+func MakeTupleDiffer(spaceFmt []SpaceField, keyFields []string) (TupleDiffer, error) {
+	names, err := MakeFieldNames(spaceFmt)
+	if err != nil {
+		return TupleDiffer{}, err
+	}
+	isKey := make([]bool, len(spaceFmt))
+	for _, name := range keyFields {
+		pos, ok := names.Position(name)
+		if !ok {
+			return TupleDiffer{}, fmt.Errorf("unknown key field %q", name)
+		}
+		isKey[pos] = true
+	}
+	return TupleDiffer{spaceFmt: spaceFmt, isKey: isKey}, nil
+}
+
+// WithNumericDeltas sets whether changed numeric fields are updated with `+` and
+// `-` operations instead of `=`.
+func (differ TupleDiffer) WithNumericDeltas(useDeltas bool) TupleDiffer {
+	differ.useDeltas = useDeltas
+	return differ
+}
+
+// isNullable checks if the field at the position is nullable. Fields beyond
+// the space format are nullable.
+func (differ TupleDiffer) isNullable(pos int) bool {
+	return pos >= len(differ.spaceFmt) || differ.spaceFmt[pos].IsNullable
+}
+
+// fieldName returns the field name for error messages.
+func (differ TupleDiffer) fieldName(pos int) string {
+	if pos < len(differ.spaceFmt) {
+		return fmt.Sprintf("%q", differ.spaceFmt[pos].Name)
+	}
+	return fmt.Sprintf("#%d", pos+1)
+}
+
+// Diff returns update operations, that turn the old tuple into the new one:
+//   - `=` for changed and appended fields;
+//   - `+` or `-` for changed numeric fields, if numeric deltas are enabled;
+//   - `#` for truncated trailing fields, which must be nullable.
+//
+// Primary key fields must be equal in both tuples.
+func (differ TupleDiffer) Diff(oldTuple, newTuple []any) ([]UpdateOperation, error) {
+	for pos, isKey := range differ.isKey {
+		if !isKey {
+			continue
+		}
+		if pos >= len(oldTuple) || pos >= len(newTuple) {
+			return nil, fmt.Errorf("key field %s is missing", differ.fieldName(pos))
+		}
+		if !valuesEqual(oldTuple[pos], newTuple[pos]) {
+			return nil, fmt.Errorf("key field %s is changed", differ.fieldName(pos))
+		}
+	}
+
+	var ops []UpdateOperation
+	for pos, newValue := range newTuple {
+		if pos < len(differ.isKey) && differ.isKey[pos] {
+			continue
+		}
+		if pos >= len(oldTuple) {
+			ops = append(ops, UpdateOperation{Operator: OperatorAssign, Field: pos, Arg: newValue})
+			continue
+		}
+		oldValue := oldTuple[pos]
+		if valuesEqual(oldValue, newValue) {
+			continue
+		}
+		if differ.useDeltas && differ.isNumericField(pos) {
+			if op, delta, ok := numericDelta(oldValue, newValue); ok {
+				ops = append(ops, UpdateOperation{Operator: op, Field: pos, Arg: delta})
+				continue
+			}
+		}
+		ops = append(ops, UpdateOperation{Operator: OperatorAssign, Field: pos, Arg: newValue})
+	}
+
+	if len(newTuple) < len(oldTuple) {
+		for pos := len(newTuple); pos < len(oldTuple); pos++ {
+			if !differ.isNullable(pos) {
+				return nil, fmt.Errorf("non-nullable field %s is truncated",
+					differ.fieldName(pos))
+			}
+		}
+		ops = append(ops, UpdateOperation{
+			Operator: OperatorDelete,
+			Field:    len(newTuple),
+			Arg:      len(oldTuple) - len(newTuple),
+		})
+	}
+	return ops, nil
+}
+
+// DiffOperations is like Diff, but returns go-tarantool update operations.
+func (differ TupleDiffer) DiffOperations(
+	oldTuple, newTuple []any) (*tarantool.Operations, error) {
+	ops, err := differ.Diff(oldTuple, newTuple)
+	if err != nil {
+		return nil, err
+	}
+	return MakeTarantoolOperations(ops), nil
+}
+
+// MakeTarantoolOperations converts update operations to go-tarantool update operations.
+func MakeTarantoolOperations(ops []UpdateOperation) *tarantool.Operations {
+	result := tarantool.NewOperations()
+	for _, op := range ops {
+		switch op.Operator {
+		case OperatorAssign:
+			result.Assign(op.Field, op.Arg)
+		case OperatorAdd:
+			result.Add(op.Field, op.Arg)
+		case OperatorSubtract:
+			result.Subtract(op.Field, op.Arg)
+		case OperatorDelete:
+			result.Delete(op.Field, op.Arg)
+		}
+	}
+	return result
+}
+
+// isNumericField checks if the field at the position has a numeric type.
+func (differ TupleDiffer) isNumericField(pos int) bool {
+	if pos >= len(differ.spaceFmt) {
+		return false
+	}
+	switch differ.spaceFmt[pos].Type {
+	case TypeUnsigned, TypeInteger, TypeNumber, TypeDouble, TypeDecimal:
+		return true
+	}
+	return false
+}
+
+// valuesEqual checks if the converted values are equal.
+func valuesEqual(lhs, rhs any) bool {
+	if lhsDatetime, ok := lhs.(datetime.Datetime); ok {
+		rhsDatetime, ok := rhs.(datetime.Datetime)
+		if !ok {
+			return false
+		}
+		lhsTime, rhsTime := lhsDatetime.ToTime(), rhsDatetime.ToTime()
+		_, lhsOffset := lhsTime.Zone()
+		_, rhsOffset := rhsTime.Zone()
+		return lhsTime.Equal(rhsTime) && lhsOffset == rhsOffset &&
+			lhsTime.Location().String() == rhsTime.Location().String()
+	}
+	return reflect.DeepEqual(lhs, rhs)
+}
+
+// toBigInt converts an integer value to big.Int.
+func toBigInt(value any) (*big.Int, bool) {
+	switch value := value.(type) {
+	case uint64:
+		return new(big.Int).SetUint64(value), true
+	case int64:
+		return big.NewInt(value), true
+	}
+	return nil, false
+}
+
+// numericDelta returns the operation, that turns the old numeric value into the new one.
+// The last result is false, if there is no such exact operation.
+func numericDelta(oldValue, newValue any) (UpdateOperator, any, bool) {
+	if oldInt, ok := toBigInt(oldValue); ok {
+		newInt, ok := toBigInt(newValue)
+		if !ok {
+			return "", nil, false
+		}
+		delta := new(big.Int).Sub(newInt, oldInt)
+		op := OperatorAdd
+		if delta.Sign() < 0 {
+			op = OperatorSubtract
+			delta.Neg(delta)
+		}
+		if !delta.IsUint64() {
+			return "", nil, false
+		}
+		return op, delta.Uint64(), true
+	}
+
+	switch oldValue := oldValue.(type) {
+	case float64:
+		newValue, ok := newValue.(float64)
+		if !ok {
+			return "", nil, false
+		}
+		delta := newValue - oldValue
+		if oldValue+delta != newValue {
+			return "", nil, false
+		}
+		if delta < 0 {
+			return OperatorSubtract, -delta, true
+		}
+		return OperatorAdd, delta, true
+	case decimal.Decimal:
+		newValue, ok := newValue.(decimal.Decimal)
+		if !ok {
+			return "", nil, false
+		}
+		delta := newValue.Sub(oldValue.Decimal)
+		if delta.Sign() < 0 {
+			return OperatorSubtract, decimal.MakeDecimal(delta.Neg()), true
+		}
+		return OperatorAdd, decimal.MakeDecimal(delta), true
+	}
+	return "", nil, false
+}
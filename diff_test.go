@@ -0,0 +1,191 @@
+package tupleconv_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tarantool/go-tarantool/v2"
+	"github.com/tarantool/go-tarantool/v2/decimal"
+	"github.com/tarantool/go-tupleconv"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+var diffTestSpaceFmt = []tupleconv.SpaceField{
+	{Name: "id", Type: tupleconv.TypeUnsigned},
+	{Name: "name", Type: tupleconv.TypeString},
+	{Name: "balance", Type: tupleconv.TypeInteger},
+	{Name: "rate", Type: tupleconv.TypeDouble},
+	{Name: "amount", Type: tupleconv.TypeDecimal},
+	{Name: "created", Type: tupleconv.TypeDatetime, IsNullable: true},
+	{Name: "comment", Type: tupleconv.TypeString, IsNullable: true},
+}
+
+func makeTestDecimal(t *testing.T, src string) decimal.Decimal {
+	value, err := decimal.MakeDecimalFromString(src)
+	require.NoError(t, err)
+	return value
+}
+
+func TestTupleDiffer_Diff(t *testing.T) {
+	differ, err := tupleconv.MakeTupleDiffer(diffTestSpaceFmt, []string{"id"})
+	require.NoError(t, err)
+
+	created1 := getDatetimeWithValidate(t, time.Date(2023, 8, 30, 12, 0, 0, 0, time.UTC))
+	created2 := getDatetimeWithValidate(t, time.Date(2023, 8, 31, 12, 0, 0, 0, time.UTC))
+
+	old := []any{uint64(1), "a", int64(10), 1.5, makeTestDecimal(t, "1.5"), created1, "c"}
+
+	cases := []struct {
+		name       string
+		newTuple   []any
+		useDeltas  bool
+		expected   []tupleconv.UpdateOperation
+		errMessage string
+	}{
+		{
+			name: "equal",
+			newTuple: []any{uint64(1), "a", int64(10), 1.5,
+				makeTestDecimal(t, "1.5"), created1, "c"},
+		},
+		{
+			name: "assign",
+			newTuple: []any{uint64(1), "b", int64(12), 0.5,
+				makeTestDecimal(t, "2"), created2, "c"},
+			expected: []tupleconv.UpdateOperation{
+				{Operator: tupleconv.OperatorAssign, Field: 1, Arg: "b"},
+				{Operator: tupleconv.OperatorAssign, Field: 2, Arg: int64(12)},
+				{Operator: tupleconv.OperatorAssign, Field: 3, Arg: 0.5},
+				{Operator: tupleconv.OperatorAssign, Field: 4, Arg: makeTestDecimal(t, "2")},
+				{Operator: tupleconv.OperatorAssign, Field: 5, Arg: created2},
+			},
+		},
+		{
+			name: "deltas",
+			newTuple: []any{uint64(1), "b", uint64(12), 0.5,
+				makeTestDecimal(t, "1"), created1, "c"},
+			useDeltas: true,
+			expected: []tupleconv.UpdateOperation{
+				{Operator: tupleconv.OperatorAssign, Field: 1, Arg: "b"},
+				{Operator: tupleconv.OperatorAdd, Field: 2, Arg: uint64(2)},
+				{Operator: tupleconv.OperatorSubtract, Field: 3, Arg: 1.0},
+				{Operator: tupleconv.OperatorSubtract, Field: 4, Arg: makeTestDecimal(t, "0.5")},
+			},
+		},
+		{
+			name: "negative delta",
+			newTuple: []any{uint64(1), "a", int64(-5), 1.5,
+				makeTestDecimal(t, "1.5"), created1, "c"},
+			useDeltas: true,
+			expected: []tupleconv.UpdateOperation{
+				{Operator: tupleconv.OperatorSubtract, Field: 2, Arg: uint64(15)},
+			},
+		},
+		{
+			name: "inexact float delta",
+			newTuple: []any{uint64(1), "a", int64(10), 0.1,
+				makeTestDecimal(t, "1.5"), created1, "c"},
+			useDeltas: true,
+			expected: []tupleconv.UpdateOperation{
+				{Operator: tupleconv.OperatorAssign, Field: 3, Arg: 0.1},
+			},
+		},
+		{
+			name: "truncate nullable",
+			newTuple: []any{uint64(1), "a", int64(10), 1.5,
+				makeTestDecimal(t, "1.5")},
+			expected: []tupleconv.UpdateOperation{
+				{Operator: tupleconv.OperatorDelete, Field: 5, Arg: 2},
+			},
+		},
+		{
+			name: "append",
+			newTuple: []any{uint64(1), "a", int64(10), 1.5,
+				makeTestDecimal(t, "1.5"), created1,
+				nil, "extra"},
+			expected: []tupleconv.UpdateOperation{
+				{Operator: tupleconv.OperatorAssign, Field: 6, Arg: nil},
+				{Operator: tupleconv.OperatorAssign, Field: 7, Arg: "extra"},
+			},
+		},
+		{
+			name:       "truncate non-nullable",
+			newTuple:   []any{uint64(1), "a", int64(10), 1.5},
+			errMessage: `non-nullable field "amount" is truncated`,
+		},
+		{
+			name:       "key changed",
+			newTuple:   []any{uint64(2), "a", int64(10), 1.5, makeTestDecimal(t, "1.5"), created1},
+			errMessage: `key field "id" is changed`,
+		},
+		{
+			name:       "key missing",
+			newTuple:   []any{},
+			errMessage: `key field "id" is missing`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ops, err := differ.WithNumericDeltas(tc.useDeltas).Diff(old, tc.newTuple)
+			if tc.errMessage != "" {
+				assert.EqualError(t, err, tc.errMessage)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expected, ops)
+			}
+		})
+	}
+}
+
+func TestTupleDiffer_mixedIntegers(t *testing.T) {
+	differ, err := tupleconv.MakeTupleDiffer(diffTestSpaceFmt, []string{"id"})
+	require.NoError(t, err)
+	differ = differ.WithNumericDeltas(true)
+
+	ops, err := differ.Diff(
+		[]any{uint64(1), "a", int64(-9223372036854775808)},
+		[]any{uint64(1), "a", uint64(18446744073709551615)})
+	assert.NoError(t, err)
+	assert.Equal(t, []tupleconv.UpdateOperation{
+		{Operator: tupleconv.OperatorAssign, Field: 2, Arg: uint64(18446744073709551615)},
+	}, ops)
+
+	ops, err = differ.Diff(
+		[]any{uint64(1), "a", uint64(3)},
+		[]any{uint64(1), "a", int64(-3)})
+	assert.NoError(t, err)
+	assert.Equal(t, []tupleconv.UpdateOperation{
+		{Operator: tupleconv.OperatorSubtract, Field: 2, Arg: uint64(6)},
+	}, ops)
+}
+
+func TestMakeTupleDiffer_unknownKey(t *testing.T) {
+	_, err := tupleconv.MakeTupleDiffer(diffTestSpaceFmt, []string{"uid"})
+	assert.EqualError(t, err, `unknown key field "uid"`)
+}
+
+func TestTupleDiffer_DiffOperations(t *testing.T) {
+	differ, err := tupleconv.MakeTupleDiffer(diffTestSpaceFmt, []string{"id"})
+	require.NoError(t, err)
+
+	ops, err := differ.WithNumericDeltas(true).DiffOperations(
+		[]any{uint64(1), "a", int64(1), 1.5, makeTestDecimal(t, "1"), nil, "c"},
+		[]any{uint64(1), "b", int64(3), 1.5, makeTestDecimal(t, "1")})
+	require.NoError(t, err)
+
+	expected := tarantool.NewOperations().
+		Assign(1, "b").
+		Add(2, uint64(2)).
+		Delete(5, 2)
+
+	actualEncoded, err := msgpack.Marshal(ops)
+	require.NoError(t, err)
+	expectedEncoded, err := msgpack.Marshal(expected)
+	require.NoError(t, err)
+	assert.Equal(t, expectedEncoded, actualEncoded)
+
+	_, err = differ.DiffOperations([]any{uint64(1)}, []any{uint64(2)})
+	assert.Error(t, err)
+}
@@ -49,6 +49,33 @@ func ExampleConverter() {
 	// 100 <nil>
 }
 
+// ExampleMakeChainConverter demonstrates how to compose a custom converter
+// from the built-in ones.
+func ExampleMakeChainConverter() {
+	trim := tupleconv.MakeStringNormalizer(tupleconv.NormalizeOptions{Trim: true})
+	percent := tupleconv.MakeWrapErrorConverter(
+		tupleconv.MakeValidateConverter(
+			tupleconv.MakeChainConverter[string, string, any](
+				trim, tupleconv.MakeStringToUIntConverter("")),
+			func(value any) error {
+				if value.(uint64) > 100 {
+					return errors.New("too big")
+				}
+				return nil
+			}),
+		"percent")
+	withDefault := tupleconv.MakeFallbackConverter(percent, any(uint64(0)))
+
+	fmt.Println(percent.Convert(" 42 "))
+	fmt.Println(percent.Convert("420"))
+	fmt.Println(withDefault.Convert("n/a"))
+
+	// Output:
+	// 42 <nil>
+	// <nil> percent: too big
+	// 0 <nil>
+}
+
 // ExampleMapper_basicMapper demonstrates the basic usage of the Mapper.
 func ExampleMapper_basicMapper() {
 	// Mapper example.
@@ -155,6 +182,28 @@ func ExampleStringToTTConvFactory_convertNullable() {
 	// <nil> <nil>
 }
 
+// ExampleNamedMapper demonstrates how to map tuples to the named form and back.
+func ExampleNamedMapper() {
+	spaceFmt := []tupleconv.SpaceField{
+		{Name: "id", Type: tupleconv.TypeUnsigned},
+		{Name: "name", Type: tupleconv.TypeString},
+		{Name: "score", Type: tupleconv.TypeDouble, IsNullable: true},
+	}
+	factory := tupleconv.MakeStringToTTConvFactory()
+	converters, _ := tupleconv.MakeTypeToTTConverters[string](factory, spaceFmt)
+	mapper, _ := tupleconv.MakeNamedMapper(tupleconv.MakeMapper(converters), spaceFmt)
+
+	named, err := mapper.Map([]string{"1", "Alice", "4.5"})
+	fmt.Println(named, err)
+
+	tuple, err := mapper.MapNamed(map[string]string{"id": "2", "name": "Bob"})
+	fmt.Println(tuple, err)
+
+	// Output:
+	// map[id:1 name:Alice score:4.5] <nil>
+	// [2 Bob <nil>] <nil>
+}
+
 type customFactory struct {
 	tupleconv.StringToTTConvFactory
 }
@@ -295,7 +344,7 @@ func ExampleMap_insertMappedTuples() {
 	fmt.Println(encodedTuple0)
 
 	// Output:
-	// [{0 id unsigned false} {0 boolean boolean false} {0 number number false}]
+	// [{0 id unsigned false } {0 boolean boolean false } {0 number number false }]
 	// [1 true 12 143.5 2020-08-22T11:27:43.123456789-0200 <nil> str <nil> [1 2 3] 190 <nil>]
 }
 
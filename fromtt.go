@@ -0,0 +1,364 @@
+package tupleconv
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/tarantool/go-tarantool/v2/datetime"
+	"github.com/tarantool/go-tarantool/v2/decimal"
+)
+
+// TTToTypeConvFactory is a factory capable of creating converters from tarantool
+// types to Type. It is the reverse of TTConvFactory.
+type TTToTypeConvFactory[Type any] interface {
+	// GetBooleanConverter returns a converter from boolean to Type.
+	GetBooleanConverter() Converter[any, Type]
+
+	// GetStringConverter returns a converter from string to Type.
+	GetStringConverter() Converter[any, Type]
+
+	// GetUnsignedConverter returns a converter from unsigned to Type.
+	GetUnsignedConverter() Converter[any, Type]
+
+	// GetDatetimeConverter returns a converter from datetime to Type.
+	GetDatetimeConverter() Converter[any, Type]
+
+	// GetUUIDConverter returns a converter from uuid to Type.
+	GetUUIDConverter() Converter[any, Type]
+
+	// GetMapConverter returns a converter from map to Type.
+	GetMapConverter() Converter[any, Type]
+
+	// GetArrayConverter returns a converter from array to Type.
+	GetArrayConverter() Converter[any, Type]
+
+	// GetVarbinaryConverter returns a converter from varbinary to Type.
+	GetVarbinaryConverter() Converter[any, Type]
+
+	// GetDoubleConverter returns a converter from double to Type.
+	GetDoubleConverter() Converter[any, Type]
+
+	// GetDecimalConverter returns a converter from decimal to Type.
+	GetDecimalConverter() Converter[any, Type]
+
+	// GetIntegerConverter returns a converter from integer to Type.
+	GetIntegerConverter() Converter[any, Type]
+
+	// GetNumberConverter returns a converter from number to Type.
+	GetNumberConverter() Converter[any, Type]
+
+	// GetAnyConverter returns a converter from any to Type.
+	GetAnyConverter() Converter[any, Type]
+
+	// GetScalarConverter returns a converter from scalar to Type.
+	GetScalarConverter() Converter[any, Type]
+
+	// GetIntervalConverter returns a converter from interval to Type.
+	GetIntervalConverter() Converter[any, Type]
+
+	// MakeNullableConverter extends the incoming converter to a nullable converter.
+	MakeNullableConverter(Converter[any, Type]) Converter[any, Type]
+}
+
+// GetTTToTypeConverterByType returns a converter by TTToTypeConvFactory and typename.
+func GetTTToTypeConverterByType[Type any](
+	fac TTToTypeConvFactory[Type], typ TypeName) (conv Converter[any, Type], err error) {
+	switch typ {
+	case TypeBoolean:
+		conv = fac.GetBooleanConverter()
+	case TypeString:
+		conv = fac.GetStringConverter()
+	case TypeUnsigned:
+		conv = fac.GetUnsignedConverter()
+	case TypeDatetime:
+		conv = fac.GetDatetimeConverter()
+	case TypeUUID:
+		conv = fac.GetUUIDConverter()
+	case TypeMap:
+		conv = fac.GetMapConverter()
+	case TypeArray:
+		conv = fac.GetArrayConverter()
+	case TypeVarbinary:
+		conv = fac.GetVarbinaryConverter()
+	case TypeDouble:
+		conv = fac.GetDoubleConverter()
+	case TypeDecimal:
+		conv = fac.GetDecimalConverter()
+	case TypeInteger:
+		conv = fac.GetIntegerConverter()
+	case TypeNumber:
+		conv = fac.GetNumberConverter()
+	case TypeAny:
+		conv = fac.GetAnyConverter()
+	case TypeScalar:
+		conv = fac.GetScalarConverter()
+	case TypeInterval:
+		conv = fac.GetIntervalConverter()
+	default:
+		return nil, fmt.Errorf("unexpected type: %s", typ)
+	}
+	return conv, nil
+}
+
+// MakeTTToTypeConverters creates list of the converters from tt type to Type by
+// the factory and space format.
+func MakeTTToTypeConverters[Type any](
+	fac TTToTypeConvFactory[Type],
+	spaceFmt []SpaceField) ([]Converter[any, Type], error) {
+	converters := make([]Converter[any, Type], len(spaceFmt))
+	for i, field := range spaceFmt {
+		conv, err := GetTTToTypeConverterByType(fac, field.Type)
+		if err != nil {
+			return nil, err
+		}
+		if field.IsNullable {
+			conv = fac.MakeNullableConverter(conv)
+		}
+		converters[i] = conv
+	}
+	return converters, nil
+}
+
+// TTToStringConvFactory is the default TTToTypeConvFactory for strings. Values are
+// converted to the representations, accepted by StringToTTConvFactory with
+// default options.
+type TTToStringConvFactory struct {
+	// nullValue is the representation of null.
+	nullValue string
+}
+
+// MakeTTToStringConvFactory creates TTToStringConvFactory.
+func MakeTTToStringConvFactory() TTToStringConvFactory {
+	return TTToStringConvFactory{nullValue: defaultNullValue}
+}
+
+var _ TTToTypeConvFactory[string] = (*TTToStringConvFactory)(nil)
+
+// WithNullValue sets the representation of null.
+func (fac TTToStringConvFactory) WithNullValue(nullValue string) TTToStringConvFactory {
+	fac.nullValue = nullValue
+	return fac
+}
+
+// unexpectedValueError returns an error about the value of the unexpected type.
+func unexpectedValueError(value any, typ TypeName) error {
+	return fmt.Errorf("unexpected value %v of type %T for %s", value, value, typ)
+}
+
+// makeTTToStringConverter creates a converter from any to string, that accepts
+// only values of the type.
+func makeTTToStringConverter[T any](typ TypeName,
+	convFunc func(T) (string, error)) Converter[any, string] {
+	return MakeFuncConverter(func(value any) (string, error) {
+		typed, ok := value.(T)
+		if !ok {
+			return "", unexpectedValueError(value, typ)
+		}
+		return convFunc(typed)
+	})
+}
+
+func (TTToStringConvFactory) GetBooleanConverter() Converter[any, string] {
+	return makeTTToStringConverter(TypeBoolean, func(value bool) (string, error) {
+		return strconv.FormatBool(value), nil
+	})
+}
+
+func (TTToStringConvFactory) GetStringConverter() Converter[any, string] {
+	return makeTTToStringConverter(TypeString, func(value string) (string, error) {
+		return value, nil
+	})
+}
+
+func (TTToStringConvFactory) GetUnsignedConverter() Converter[any, string] {
+	return MakeFuncConverter(func(value any) (string, error) {
+		switch value := value.(type) {
+		case uint64:
+			return strconv.FormatUint(value, 10), nil
+		case int64:
+			if value >= 0 {
+				return strconv.FormatInt(value, 10), nil
+			}
+		}
+		return "", unexpectedValueError(value, TypeUnsigned)
+	})
+}
+
+func (TTToStringConvFactory) GetDatetimeConverter() Converter[any, string] {
+	return makeTTToStringConverter(TypeDatetime, MakeDatetimeToStringConverter().Convert)
+}
+
+func (TTToStringConvFactory) GetUUIDConverter() Converter[any, string] {
+	return makeTTToStringConverter(TypeUUID, func(value uuid.UUID) (string, error) {
+		return value.String(), nil
+	})
+}
+
+// marshalJSON converts the array or the map to JSON. Nested values of tarantool
+// extension types are represented as strings.
+func marshalJSON(value any) (string, error) {
+	compatible, err := toJSONCompatible(value)
+	if err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(compatible)
+	return string(data), err
+}
+
+// toJSONCompatible replaces tarantool extension values and maps with non-string
+// keys, that are not supported by encoding/json.
+func toJSONCompatible(value any) (any, error) {
+	var err error
+	switch value := value.(type) {
+	case []byte:
+		return string(value), nil
+	case decimal.Decimal:
+		return value.String(), nil
+	case uuid.UUID:
+		return value.String(), nil
+	case datetime.Datetime:
+		return MakeDatetimeToStringConverter().Convert(value)
+	case datetime.Interval:
+		return MakeIntervalToStringConverter().Convert(value)
+	case []any:
+		result := make([]any, len(value))
+		for i, item := range value {
+			if result[i], err = toJSONCompatible(item); err != nil {
+				return nil, err
+			}
+		}
+		return result, nil
+	case map[string]any:
+		result := make(map[string]any, len(value))
+		for key, item := range value {
+			if result[key], err = toJSONCompatible(item); err != nil {
+				return nil, err
+			}
+		}
+		return result, nil
+	case map[any]any:
+		result := make(map[string]any, len(value))
+		for key, item := range value {
+			if result[fmt.Sprint(key)], err = toJSONCompatible(item); err != nil {
+				return nil, err
+			}
+		}
+		return result, nil
+	}
+	return value, nil
+}
+
+func (TTToStringConvFactory) GetMapConverter() Converter[any, string] {
+	return MakeFuncConverter(func(value any) (string, error) {
+		switch value.(type) {
+		case map[string]any, map[any]any:
+			return marshalJSON(value)
+		}
+		return "", unexpectedValueError(value, TypeMap)
+	})
+}
+
+func (TTToStringConvFactory) GetArrayConverter() Converter[any, string] {
+	return makeTTToStringConverter(TypeArray, func(value []any) (string, error) {
+		return marshalJSON(value)
+	})
+}
+
+func (TTToStringConvFactory) GetVarbinaryConverter() Converter[any, string] {
+	return makeTTToStringConverter(TypeVarbinary, func(value []byte) (string, error) {
+		return string(value), nil
+	})
+}
+
+func (TTToStringConvFactory) GetDoubleConverter() Converter[any, string] {
+	return makeTTToStringConverter(TypeDouble, func(value float64) (string, error) {
+		return strconv.FormatFloat(value, 'g', -1, 64), nil
+	})
+}
+
+func (TTToStringConvFactory) GetDecimalConverter() Converter[any, string] {
+	return makeTTToStringConverter(TypeDecimal, func(value decimal.Decimal) (string, error) {
+		return value.String(), nil
+	})
+}
+
+func (TTToStringConvFactory) GetIntegerConverter() Converter[any, string] {
+	return MakeFuncConverter(func(value any) (string, error) {
+		switch value := value.(type) {
+		case uint64:
+			return strconv.FormatUint(value, 10), nil
+		case int64:
+			return strconv.FormatInt(value, 10), nil
+		}
+		return "", unexpectedValueError(value, TypeInteger)
+	})
+}
+
+func (fac TTToStringConvFactory) GetNumberConverter() Converter[any, string] {
+	integer, double, dec := fac.GetIntegerConverter(), fac.GetDoubleConverter(),
+		fac.GetDecimalConverter()
+	return MakeFuncConverter(func(value any) (string, error) {
+		switch value.(type) {
+		case uint64, int64:
+			return integer.Convert(value)
+		case float64:
+			return double.Convert(value)
+		case decimal.Decimal:
+			return dec.Convert(value)
+		}
+		return "", unexpectedValueError(value, TypeNumber)
+	})
+}
+
+// convertScalar converts the scalar value by its dynamic type.
+func (fac TTToStringConvFactory) convertScalar(value any) (string, error) {
+	switch value.(type) {
+	case bool:
+		return fac.GetBooleanConverter().Convert(value)
+	case uint64, int64, float64, decimal.Decimal:
+		return fac.GetNumberConverter().Convert(value)
+	case string:
+		return fac.GetStringConverter().Convert(value)
+	case []byte:
+		return fac.GetVarbinaryConverter().Convert(value)
+	case uuid.UUID:
+		return fac.GetUUIDConverter().Convert(value)
+	case datetime.Datetime:
+		return fac.GetDatetimeConverter().Convert(value)
+	case datetime.Interval:
+		return fac.GetIntervalConverter().Convert(value)
+	}
+	return "", unexpectedValueError(value, TypeScalar)
+}
+
+func (fac TTToStringConvFactory) GetAnyConverter() Converter[any, string] {
+	return MakeFuncConverter(func(value any) (string, error) {
+		switch value.(type) {
+		case []any:
+			return fac.GetArrayConverter().Convert(value)
+		case map[string]any, map[any]any:
+			return fac.GetMapConverter().Convert(value)
+		}
+		return fac.convertScalar(value)
+	})
+}
+
+func (fac TTToStringConvFactory) GetScalarConverter() Converter[any, string] {
+	return MakeFuncConverter(fac.convertScalar)
+}
+
+func (TTToStringConvFactory) GetIntervalConverter() Converter[any, string] {
+	return makeTTToStringConverter(TypeInterval, MakeIntervalToStringConverter().Convert)
+}
+
+func (fac TTToStringConvFactory) MakeNullableConverter(
+	converter Converter[any, string]) Converter[any, string] {
+	return MakeFuncConverter(func(value any) (string, error) {
+		if value == nil {
+			return fac.nullValue, nil
+		}
+		return converter.Convert(value)
+	})
+}
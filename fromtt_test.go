@@ -0,0 +1,118 @@
+package tupleconv_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tarantool/go-tarantool/v2/datetime"
+
+	"github.com/tarantool/go-tupleconv"
+)
+
+func TestTTToStringConvFactory(t *testing.T) {
+	someUUID := uuid.MustParse("09b56913-11f0-4fa4-b5d0-901b5efa532a")
+	dt := getDatetimeWithValidate(t, time.Date(2023, 8, 30, 12, 6, 5, 120000000,
+		time.FixedZone("", 0)))
+	fac := tupleconv.MakeTTToStringConvFactory()
+
+	cases := map[tupleconv.TypeName][]convCase[any, string]{
+		tupleconv.TypeBoolean: {
+			{value: true, expected: "true"},
+			{value: "true", isErr: true},
+		},
+		tupleconv.TypeUnsigned: {
+			{value: uint64(math.MaxUint64), expected: "18446744073709551615"},
+			{value: int64(1), expected: "1"},
+			{value: int64(-1), isErr: true},
+		},
+		tupleconv.TypeInteger: {
+			{value: int64(-1), expected: "-1"},
+			{value: 1.0, isErr: true},
+		},
+		tupleconv.TypeDouble: {
+			{value: 1.5, expected: "1.5"},
+			{value: 1e100, expected: "1e+100"},
+			{value: uint64(1), isErr: true},
+		},
+		tupleconv.TypeNumber: {
+			{value: uint64(1), expected: "1"},
+			{value: -0.5, expected: "-0.5"},
+			{value: makeTestDecimal(t, "1.25"), expected: "1.25"},
+			{value: "1", isErr: true},
+		},
+		tupleconv.TypeDecimal: {
+			{value: makeTestDecimal(t, "-100.5"), expected: "-100.5"},
+			{value: 1.5, isErr: true},
+		},
+		tupleconv.TypeString: {
+			{value: "abc", expected: "abc"},
+			{value: []byte("abc"), isErr: true},
+		},
+		tupleconv.TypeVarbinary: {
+			{value: []byte("abc"), expected: "abc"},
+			{value: "abc", isErr: true},
+		},
+		tupleconv.TypeUUID: {
+			{value: someUUID, expected: "09b56913-11f0-4fa4-b5d0-901b5efa532a"},
+		},
+		tupleconv.TypeDatetime: {
+			{value: dt, expected: "2023-08-30T12:06:05.12+0000"},
+		},
+		tupleconv.TypeInterval: {
+			{value: datetime.Interval{Day: 2, Adjust: datetime.LastAdjust},
+				expected: "0,0,0,2,0,0,0,0,2"},
+		},
+		tupleconv.TypeArray: {
+			{value: []any{uint64(1), someUUID, []byte("a")},
+				expected: `[1,"09b56913-11f0-4fa4-b5d0-901b5efa532a","a"]`},
+			{value: map[string]any{}, isErr: true},
+		},
+		tupleconv.TypeMap: {
+			{value: map[string]any{"a": dt}, expected: `{"a":"2023-08-30T12:06:05.12+0000"}`},
+			{value: map[any]any{uint64(1): nil}, expected: `{"1":null}`},
+			{value: []any{}, isErr: true},
+		},
+		tupleconv.TypeScalar: {
+			{value: false, expected: "false"},
+			{value: someUUID, expected: "09b56913-11f0-4fa4-b5d0-901b5efa532a"},
+			{value: []any{}, isErr: true},
+		},
+		tupleconv.TypeAny: {
+			{value: int64(-5), expected: "-5"},
+			{value: []any{"a"}, expected: `["a"]`},
+			{value: nil, isErr: true},
+		},
+	}
+
+	for typ, typeCases := range cases {
+		t.Run(string(typ), func(t *testing.T) {
+			conv, err := tupleconv.GetTTToTypeConverterByType[string](fac, typ)
+			require.NoError(t, err)
+			HelperTestConverter(t, conv, typeCases)
+		})
+	}
+}
+
+func TestTTToStringConvFactory_nullable(t *testing.T) {
+	spaceFmt := []tupleconv.SpaceField{
+		{Type: tupleconv.TypeString},
+		{Type: tupleconv.TypeString, IsNullable: true},
+	}
+	fac := tupleconv.MakeTTToStringConvFactory().WithNullValue("NULL")
+	converters, err := tupleconv.MakeTTToTypeConverters[string](fac, spaceFmt)
+	require.NoError(t, err)
+
+	_, err = converters[0].Convert(nil)
+	assert.Error(t, err)
+	result, err := converters[1].Convert(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "NULL", result)
+
+	_, err = tupleconv.MakeTTToTypeConverters[string](fac,
+		[]tupleconv.SpaceField{{Type: "text"}})
+	assert.EqualError(t, err, "unexpected type: text")
+}
@@ -0,0 +1,204 @@
+package tupleconv
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"fmt"
+	"hash"
+	"hash/fnv"
+	"io"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// GeneratedField is a field of the space format, that is absent in the source
+// tuples and is generated instead of being converted.
+type GeneratedField struct {
+	// Pos is the position of the field in the space format.
+	Pos int
+	// Generator generates the value of the field by the tuple, where the converted
+	// fields and the generated fields at lower positions are set.
+	Generator Converter[[]any, any]
+}
+
+// MakeTypeToTTMapper creates a mapper from Type to tarantool types by the factory
+// and the space format. Source tuples contain only the fields, that are not
+// generated, in the order of the space format. The generated fields are inserted
+// into the converted tuples in the order of their positions.
+func MakeTypeToTTMapper[Type any](fac TTConvFactory[Type], spaceFmt []SpaceField,
+	generated ...GeneratedField) (Mapper[Type, any], error) {
+	generated = append([]GeneratedField{}, generated...)
+	sort.Slice(generated, func(i, j int) bool {
+		return generated[i].Pos < generated[j].Pos
+	})
+	isGenerated := make([]bool, len(spaceFmt))
+	for _, field := range generated {
+		if field.Pos < 0 || field.Pos >= len(spaceFmt) {
+			return Mapper[Type, any]{}, fmt.Errorf(
+				"generated field position %d is out of range of the space format", field.Pos)
+		}
+		if isGenerated[field.Pos] {
+			return Mapper[Type, any]{}, fmt.Errorf(
+				"duplicate generated field position %d", field.Pos)
+		}
+		isGenerated[field.Pos] = true
+	}
+	srcFmt := make([]SpaceField, 0, len(spaceFmt)-len(generated))
+	for i, field := range spaceFmt {
+		if !isGenerated[i] {
+			srcFmt = append(srcFmt, field)
+		}
+	}
+	converters, err := MakeTypeToTTConverters(fac, srcFmt)
+	if err != nil {
+		return Mapper[Type, any]{}, err
+	}
+	mapper := MakeMapper(converters)
+	if len(generated) == 0 {
+		return mapper, nil
+	}
+	return mapper.WithPostTransform(MakeFuncContextConverter(
+		func(ctx context.Context, tuple []any) ([]any, error) {
+			if len(tuple) != len(srcFmt) {
+				return nil, fmt.Errorf("%d fields, expected %d", len(tuple), len(srcFmt))
+			}
+			result := make([]any, len(spaceFmt))
+			for i, pos := 0, 0; i < len(result); i++ {
+				if !isGenerated[i] {
+					result[i] = tuple[pos]
+					pos++
+				}
+			}
+			for _, field := range generated {
+				value, err := convertContext(ctx, field.Generator, result)
+				if err != nil {
+					return nil, fmt.Errorf("field #%d (%q): %w", field.Pos+1,
+						spaceFmt[field.Pos].Name, err)
+				}
+				result[field.Pos] = value
+			}
+			return result, nil
+		})), nil
+}
+
+// MakeSequenceGenerator creates a generator of unsigned values, starting from
+// start and incremented by 1 for each tuple. It is safe for concurrent use.
+//
+// Values of tuples, mapped by BatchMapper, don't depend on the order, in which
+// the workers complete: the first call in a batch reserves a value for every
+// tuple of the batch, and the i-th tuple gets the first reserved value plus i.
+// Batches, mapped one after another, get consecutive ranges, values of failed
+// and not mapped tuples are skipped.
+func MakeSequenceGenerator(start uint64) Converter[[]any, any] {
+	next := new(atomic.Uint64)
+	next.Store(start)
+	return MakeFuncContextConverter(func(ctx context.Context, _ []any) (any, error) {
+		if row, ok := ctx.Value(batchRowKey{}).(batchRow); ok {
+			first := row.state.reserveRange(next, func(size uint64) uint64 {
+				return next.Add(size) - size
+			})
+			return first + uint64(row.row), nil
+		}
+		return next.Add(1) - 1, nil
+	})
+}
+
+// MakeClockGenerator creates a generator of datetime values by the clock. If
+// the clock is nil, time.Now is used.
+func MakeClockGenerator(now func() time.Time) Converter[[]any, any] {
+	if now == nil {
+		now = time.Now
+	}
+	return MakeFuncConverter(func([]any) (any, error) {
+		return makeDatetime(now())
+	})
+}
+
+// lockedReader is a reader, that is safe for concurrent use.
+type lockedReader struct {
+	mutex  sync.Mutex
+	reader io.Reader
+}
+
+// Read is the implementation of io.Reader for lockedReader.
+func (reader *lockedReader) Read(buf []byte) (int, error) {
+	reader.mutex.Lock()
+	defer reader.mutex.Unlock()
+	return reader.reader.Read(buf)
+}
+
+// makeRandom returns the random source, that is safe for concurrent use. If
+// the source is nil, crypto/rand.Reader is used.
+func makeRandom(random io.Reader) io.Reader {
+	if random == nil {
+		return rand.Reader
+	}
+	return &lockedReader{reader: random}
+}
+
+// MakeUUIDv4Generator creates a generator of random uuid values of version 4 by
+// the random source. If the source is nil, crypto/rand.Reader is used.
+func MakeUUIDv4Generator(random io.Reader) Converter[[]any, any] {
+	random = makeRandom(random)
+	return MakeFuncConverter(func([]any) (any, error) {
+		return uuid.NewRandomFromReader(random)
+	})
+}
+
+// MakeUUIDv7Generator creates a generator of time-ordered uuid values of version 7
+// (RFC 9562) by the clock and the random source: 48 bits of the Unix time in
+// milliseconds are followed by random bits. Values, generated within the same
+// millisecond, are not ordered. If the clock is nil, time.Now is used, if
+// the source is nil, crypto/rand.Reader is used.
+func MakeUUIDv7Generator(now func() time.Time, random io.Reader) Converter[[]any, any] {
+	if now == nil {
+		now = time.Now
+	}
+	random = makeRandom(random)
+	return MakeFuncConverter(func([]any) (any, error) {
+		var id uuid.UUID
+		if _, err := io.ReadFull(random, id[6:]); err != nil {
+			return nil, err
+		}
+		ms := uint64(now().UnixMilli())
+		for i := 0; i < 6; i++ {
+			id[i] = byte(ms >> (40 - 8*i))
+		}
+		id[6] = id[6]&0x0f | 0x70 // Version 7.
+		id[8] = id[8]&0x3f | 0x80 // Variant 10.
+		return id, nil
+	})
+}
+
+// MakeHashGenerator creates a generator of unsigned hashes of the fields at
+// the positions. The fields are hashed in the MessagePack encoding with compact
+// integers and sorted keys of maps, so equal integers of different types have
+// equal hashes. If newHash is nil, 64-bit FNV-1a is used.
+func MakeHashGenerator(positions []int, newHash func() hash.Hash64) Converter[[]any, any] {
+	if newHash == nil {
+		newHash = fnv.New64a
+	}
+	return MakeFuncConverter(func(tuple []any) (any, error) {
+		if err := checkPositions(len(tuple), positions); err != nil {
+			return nil, err
+		}
+		var buf bytes.Buffer
+		encoder := msgpack.NewEncoder(&buf)
+		encoder.SetSortMapKeys(true)
+		encoder.UseCompactInts(true)
+		for _, pos := range positions {
+			if err := encodeValue(encoder, tuple[pos]); err != nil {
+				return nil, err
+			}
+		}
+		hash := newHash()
+		hash.Write(buf.Bytes())
+		return hash.Sum64(), nil
+	})
+}
@@ -0,0 +1,190 @@
+package tupleconv_test
+
+import (
+	"bytes"
+	"context"
+	"hash/fnv"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tarantool/go-tupleconv"
+)
+
+func fixedClock() time.Time {
+	return time.Date(2023, 8, 30, 12, 6, 5, 0, time.UTC)
+}
+
+func TestMakeSequenceGenerator(t *testing.T) {
+	gen := tupleconv.MakeSequenceGenerator(10)
+	var wg sync.WaitGroup
+	values := make([]any, 100)
+	for i := range values {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			values[i], _ = gen.Convert(nil)
+		}(i)
+	}
+	wg.Wait()
+	seen := map[any]bool{}
+	for _, value := range values {
+		seen[value] = true
+	}
+	assert.Len(t, seen, 100)
+	value, err := gen.Convert(nil)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(110), value)
+}
+
+func TestMakeSequenceGenerator_batch(t *testing.T) {
+	spaceFmt := []tupleconv.SpaceField{
+		{Name: "id", Type: tupleconv.TypeUnsigned},
+		{Name: "value", Type: tupleconv.TypeUnsigned},
+	}
+	mapper, err := tupleconv.MakeTypeToTTMapper[string](tupleconv.MakeStringToTTConvFactory(),
+		spaceFmt, tupleconv.GeneratedField{Pos: 0, Generator: tupleconv.MakeSequenceGenerator(10)})
+	require.NoError(t, err)
+	batchMapper := tupleconv.MakeBatchMapper(mapper).WithWorkers(8).WithCollectErrors(true)
+
+	tuples := make([][]string, 1000)
+	for i := range tuples {
+		tuples[i] = []string{strconv.Itoa(i)}
+	}
+	tuples[5] = []string{"x"}
+	start := uint64(10)
+	for batch := 0; batch < 3; batch++ {
+		results, err := batchMapper.MapBatch(context.Background(), tuples)
+		var batchErr *tupleconv.BatchError
+		require.ErrorAs(t, err, &batchErr)
+		require.Len(t, batchErr.Errors, 1)
+		for i, result := range results {
+			if i == 5 {
+				assert.Nil(t, result)
+				continue
+			}
+			assert.Equal(t, []any{start + uint64(i), uint64(i)}, result)
+		}
+		start += uint64(len(tuples))
+	}
+
+	result, err := mapper.Map([]string{"1"})
+	require.NoError(t, err)
+	assert.Equal(t, []any{start, uint64(1)}, result)
+}
+
+func TestMakeClockGenerator(t *testing.T) {
+	value, err := tupleconv.MakeClockGenerator(fixedClock).Convert(nil)
+	require.NoError(t, err)
+	assert.Equal(t, mustDatetime(t, fixedClock()), value)
+
+	value, err = tupleconv.MakeClockGenerator(nil).Convert(nil)
+	require.NoError(t, err)
+	assert.NotNil(t, value)
+}
+
+func TestMakeUUIDGenerators(t *testing.T) {
+	random := bytes.NewReader(bytes.Repeat([]byte{0xff}, 32))
+	value, err := tupleconv.MakeUUIDv4Generator(random).Convert(nil)
+	require.NoError(t, err)
+	assert.Equal(t, uuid.MustParse("ffffffff-ffff-4fff-bfff-ffffffffffff"), value)
+
+	random = bytes.NewReader(bytes.Repeat([]byte{0}, 10))
+	value, err = tupleconv.MakeUUIDv7Generator(fixedClock, random).Convert(nil)
+	require.NoError(t, err)
+	// 1693397165000 ms is 0x018a465623c8.
+	id := uuid.MustParse("018a4656-23c8-7000-8000-000000000000")
+	assert.Equal(t, id, value)
+	assert.Equal(t, uuid.Version(7), id.Version())
+	assert.Equal(t, uuid.RFC4122, id.Variant())
+
+	_, err = tupleconv.MakeUUIDv7Generator(fixedClock, random).Convert(nil)
+	assert.Error(t, err)
+
+	value, err = tupleconv.MakeUUIDv7Generator(nil, nil).Convert(nil)
+	require.NoError(t, err)
+	assert.Equal(t, uuid.Version(7), value.(uuid.UUID).Version())
+}
+
+func TestMakeHashGenerator(t *testing.T) {
+	gen := tupleconv.MakeHashGenerator([]int{0, 2}, nil)
+	value, err := gen.Convert([]any{uint64(1), "ignored", "a"})
+	require.NoError(t, err)
+
+	hash := fnv.New64a()
+	hash.Write([]byte{0x01, 0xa1, 'a'})
+	assert.Equal(t, hash.Sum64(), value)
+
+	signed, err := gen.Convert([]any{int64(1), nil, "a"})
+	require.NoError(t, err)
+	assert.Equal(t, value, signed)
+
+	other, err := gen.Convert([]any{uint64(1), "other", "a"})
+	require.NoError(t, err)
+	assert.Equal(t, value, other)
+
+	first, err := gen.Convert([]any{map[string]any{"a": 1, "b": 2, "c": 3}, nil, nil})
+	require.NoError(t, err)
+	for i := 0; i < 10; i++ {
+		next, err := gen.Convert([]any{map[string]any{"c": 3, "b": 2, "a": 1}, nil, nil})
+		require.NoError(t, err)
+		assert.Equal(t, first, next)
+	}
+
+	_, err = gen.Convert([]any{uint64(1)})
+	assert.EqualError(t, err, "position 2 is out of range of the tuple of length 1")
+}
+
+func TestMakeTypeToTTMapper(t *testing.T) {
+	spaceFmt := []tupleconv.SpaceField{
+		{Name: "id", Type: tupleconv.TypeUnsigned},
+		{Name: "name", Type: tupleconv.TypeString},
+		{Name: "created_at", Type: tupleconv.TypeDatetime},
+		{Name: "key", Type: tupleconv.TypeUUID},
+		{Name: "age", Type: tupleconv.TypeUnsigned, IsNullable: true},
+		{Name: "hash", Type: tupleconv.TypeUnsigned},
+	}
+	random := bytes.NewReader(bytes.Repeat([]byte{0}, 20))
+	mapper, err := tupleconv.MakeTypeToTTMapper[string](
+		tupleconv.MakeStringToTTConvFactory(), spaceFmt,
+		tupleconv.GeneratedField{Pos: 5, Generator: tupleconv.MakeHashGenerator([]int{0, 1}, nil)},
+		tupleconv.GeneratedField{Pos: 0, Generator: tupleconv.MakeSequenceGenerator(1)},
+		tupleconv.GeneratedField{Pos: 2, Generator: tupleconv.MakeClockGenerator(fixedClock)},
+		tupleconv.GeneratedField{
+			Pos:       3,
+			Generator: tupleconv.MakeUUIDv7Generator(fixedClock, random),
+		},
+	)
+	require.NoError(t, err)
+
+	first, err := mapper.Map([]string{"Alice", "30"})
+	require.NoError(t, err)
+	second, err := mapper.MapContext(context.Background(), []string{"Bob", ""})
+	require.NoError(t, err)
+
+	id := uuid.MustParse("018a4656-23c8-7000-8000-000000000000")
+	created := mustDatetime(t, fixedClock())
+	require.Len(t, first, 6)
+	assert.Equal(t, []any{uint64(1), "Alice", created, id, uint64(30)}, first[:5])
+	assert.Equal(t, []any{uint64(2), "Bob", created, id, nil}, second[:5])
+	assert.IsType(t, uint64(0), first[5])
+	assert.NotEqual(t, first[5], second[5])
+
+	_, err = mapper.Map([]string{"Alice"})
+	assert.EqualError(t, err, "1 fields, expected 2")
+
+	_, err = mapper.Map([]string{"Alice", "30"})
+	assert.EqualError(t, err, `field #4 ("key"): EOF`)
+
+	_, err = tupleconv.MakeTypeToTTMapper[string](tupleconv.MakeStringToTTConvFactory(),
+		spaceFmt, tupleconv.GeneratedField{Pos: 6})
+	assert.EqualError(t, err, "generated field position 6 is out of range of the space format")
+	_, err = tupleconv.MakeTypeToTTMapper[string](tupleconv.MakeStringToTTConvFactory(),
+		spaceFmt, tupleconv.GeneratedField{Pos: 1}, tupleconv.GeneratedField{Pos: 1})
+	assert.EqualError(t, err, "duplicate generated field position 1")
+}
@@ -0,0 +1,241 @@
+package tupleconv
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"golang.org/x/text/collate"
+	"golang.org/x/text/language"
+)
+
+// IndexPart is a part of the index definition.
+type IndexPart struct {
+	// Field is the zero-based field number, like in go-tarantool. It is used,
+	// if the name is empty.
+	Field uint32 `msgpack:"field"`
+	// Name is the field name.
+	Name string `msgpack:"name,omitempty"`
+	// Type is the part type. If it is empty, the field type is used.
+	Type TypeName `msgpack:"type,omitempty"`
+	// Collation is the string collation. If it is empty, the field collation is used.
+	Collation string `msgpack:"collation,omitempty"`
+	// IsNullable is true, if the part may be null. Parts of nullable fields are
+	// always nullable.
+	IsNullable bool `msgpack:"is_nullable,omitempty"`
+	// Path is the JSON path in the field, for example `name.first` or `[1].id`.
+	Path string `msgpack:"path,omitempty"`
+}
+
+// Index is the index definition.
+type Index struct {
+	Name   string      `msgpack:"name"`
+	Unique bool        `msgpack:"unique,omitempty"`
+	Parts  []IndexPart `msgpack:"parts"`
+}
+
+// stringComparator compares strings by the collation.
+type stringComparator func(lhs, rhs string) int
+
+// makeStringComparator makes the string comparator by the tarantool collation:
+// binary, `unicode`, `unicode_ci` or `unicode_<locale>_s1`, `_s2`, `_s3` with
+// the primary, secondary and tertiary strength.
+func makeStringComparator(collation string) (stringComparator, error) {
+	if collation == "" || collation == "binary" || collation == "none" {
+		return strings.Compare, nil
+	}
+	tag, options := language.Und, []collate.Option(nil)
+	switch {
+	case collation == "unicode":
+	case collation == unicodeCICollation:
+		options = append(options, collate.Loose)
+	case strings.HasPrefix(collation, "unicode_"):
+		name := strings.TrimPrefix(collation, "unicode_")
+		sep := strings.LastIndexByte(name, '_')
+		if sep < 0 {
+			return nil, fmt.Errorf("unknown collation %q", collation)
+		}
+		switch name[sep+1:] {
+		case "s1":
+			options = append(options, collate.Loose)
+		case "s2":
+			options = append(options, collate.IgnoreCase)
+		case "s3":
+		default:
+			return nil, fmt.Errorf("unknown collation %q", collation)
+		}
+		var err error
+		if tag, err = language.Parse(name[:sep]); err != nil {
+			return nil, fmt.Errorf("unknown collation %q: %w", collation, err)
+		}
+	default:
+		return nil, fmt.Errorf("unknown collation %q", collation)
+	}
+	// Collators are not safe for concurrent use.
+	pool := sync.Pool{New: func() any {
+		return collate.New(tag, options...)
+	}}
+	return func(lhs, rhs string) int {
+		collator := pool.Get().(*collate.Collator)
+		defer pool.Put(collator)
+		return collator.CompareString(lhs, rhs)
+	}, nil
+}
+
+// indexPartTypes are the types, that may be indexed.
+var indexPartTypes = map[TypeName]bool{
+	TypeUnsigned:  true,
+	TypeInteger:   true,
+	TypeNumber:    true,
+	TypeDouble:    true,
+	TypeDecimal:   true,
+	TypeString:    true,
+	TypeVarbinary: true,
+	TypeBoolean:   true,
+	TypeUUID:      true,
+	TypeDatetime:  true,
+	TypeScalar:    true,
+}
+
+// keyPart is the resolved index part.
+type keyPart struct {
+	// pos is the position of the field.
+	pos int
+	// field is the description of the part for validation and errors.
+	field SpaceField
+	// path is the parsed JSON path.
+	path []jsonPathToken
+	// compareStrings compares strings by the collation.
+	compareStrings stringComparator
+}
+
+// KeyDef extracts index keys from converted tuples and compares them the way
+// tarantool does.
+type KeyDef struct {
+	parts []keyPart
+}
+
+// MakeKeyDef creates KeyDef by the space format and the index parts.
+func MakeKeyDef(spaceFmt []SpaceField, parts []IndexPart) (KeyDef, error) {
+	if len(parts) == 0 {
+		return KeyDef{}, fmt.Errorf("index has no parts")
+	}
+	names, err := MakeFieldNames(spaceFmt)
+	if err != nil {
+		return KeyDef{}, err
+	}
+	keyParts := make([]keyPart, len(parts))
+	for i, part := range parts {
+		pos := int(part.Field)
+		if part.Name != "" {
+			var ok bool
+			if pos, ok = names.Position(part.Name); !ok {
+				return KeyDef{}, fmt.Errorf("part #%d: unknown field %q", i+1, part.Name)
+			}
+		} else if pos >= len(spaceFmt) {
+			return KeyDef{}, fmt.Errorf("part #%d: field %d is out of range "+
+				"of the space format", i+1, pos)
+		}
+		field := spaceFmt[pos]
+		name := field.Name
+		if part.Path != "" {
+			if part.Type == "" {
+				return KeyDef{}, fmt.Errorf("part #%d: type is required for the path", i+1)
+			}
+			if part.Path[0] != '.' && part.Path[0] != '[' {
+				name += "."
+			}
+			name += part.Path
+		}
+		keyPart := keyPart{pos: pos, field: SpaceField{
+			Name:       name,
+			Type:       part.Type,
+			IsNullable: part.IsNullable || field.IsNullable,
+			Collation:  part.Collation,
+		}}
+		if keyPart.field.Type == "" {
+			keyPart.field.Type = field.Type
+		}
+		if keyPart.field.Collation == "" {
+			keyPart.field.Collation = field.Collation
+		}
+		if !indexPartTypes[keyPart.field.Type] {
+			return KeyDef{}, fmt.Errorf("part #%d: type %s is not supported by indexes",
+				i+1, keyPart.field.Type)
+		}
+		if part.Path == "" && !isTypeAssignable(field.Type, keyPart.field.Type) &&
+			!isTypeAssignable(keyPart.field.Type, field.Type) {
+			return KeyDef{}, fmt.Errorf("part #%d: type %s does not match field type %s",
+				i+1, keyPart.field.Type, field.Type)
+		}
+		if keyPart.path, err = parseJSONPath(part.Path); err != nil {
+			return KeyDef{}, fmt.Errorf("part #%d: %w", i+1, err)
+		}
+		if isMultikeyPath(keyPart.path) {
+			return KeyDef{}, fmt.Errorf("part #%d: path %q: multikey paths are not supported",
+				i+1, part.Path)
+		}
+		if keyPart.compareStrings, err = makeStringComparator(
+			keyPart.field.Collation); err != nil {
+			return KeyDef{}, fmt.Errorf("part #%d: %w", i+1, err)
+		}
+		keyParts[i] = keyPart
+	}
+	return KeyDef{parts: keyParts}, nil
+}
+
+// ExtractKey extracts the key from the converted tuple. Values of the parts
+// are validated by their types and nullability.
+func (def KeyDef) ExtractKey(tuple []any) ([]any, error) {
+	key := make([]any, len(def.parts))
+	for i, part := range def.parts {
+		var value any
+		found := false
+		if part.pos < len(tuple) {
+			value, found = lookupJSONPath(tuple[part.pos], part.path)
+		}
+		if !found && !part.field.IsNullable {
+			return nil, fmt.Errorf("field #%d (%q) is missing", part.pos+1, part.field.Name)
+		}
+		if err := validateTTValue(part.field, value); err != nil {
+			return nil, fmt.Errorf("field #%d (%q): %w", part.pos+1, part.field.Name, err)
+		}
+		key[i] = value
+	}
+	return key, nil
+}
+
+// CompareKeys compares the keys. The result is negative, if lhs is less than
+// rhs, 0, if they are equal, and positive otherwise. Like in tarantool, keys
+// may be partial: only the common prefix of the keys is compared.
+func (def KeyDef) CompareKeys(lhs, rhs []any) (int, error) {
+	if len(lhs) > len(def.parts) || len(rhs) > len(def.parts) {
+		return 0, fmt.Errorf("key has more parts, than the index: %d", len(def.parts))
+	}
+	for i := 0; i < len(lhs) && i < len(rhs); i++ {
+		part := def.parts[i]
+		for _, value := range []any{lhs[i], rhs[i]} {
+			if err := validateTTValue(part.field, value); err != nil {
+				return 0, fmt.Errorf("part #%d: %w", i+1, err)
+			}
+		}
+		result, err := compareValues(lhs[i], rhs[i], part.compareStrings)
+		if err != nil || result != 0 {
+			return result, err
+		}
+	}
+	return 0, nil
+}
+
+// Compare compares the converted tuples by their keys.
+func (def KeyDef) Compare(lhs, rhs []any) (int, error) {
+	lhsKey, err := def.ExtractKey(lhs)
+	if err != nil {
+		return 0, err
+	}
+	rhsKey, err := def.ExtractKey(rhs)
+	if err != nil {
+		return 0, err
+	}
+	return def.CompareKeys(lhsKey, rhsKey)
+}
@@ -0,0 +1,208 @@
+package tupleconv_test
+
+import (
+	"math"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tarantool/go-tarantool/v2/decimal"
+
+	"github.com/tarantool/go-tupleconv"
+)
+
+// indexedFormat is the format of a space with indexes.
+var indexedFormat = []tupleconv.SpaceField{
+	{Name: "id", Type: tupleconv.TypeUnsigned},
+	{Name: "name", Type: tupleconv.TypeString, Collation: "unicode_ci"},
+	{Name: "score", Type: tupleconv.TypeNumber, IsNullable: true},
+	{Name: "data", Type: tupleconv.TypeMap, IsNullable: true},
+	{Name: "value", Type: tupleconv.TypeScalar, IsNullable: true},
+}
+
+func TestMakeKeyDef(t *testing.T) {
+	cases := []struct {
+		name  string
+		parts []tupleconv.IndexPart
+		err   string
+	}{
+		{"no parts", nil, "index has no parts"},
+		{"unknown field", []tupleconv.IndexPart{{Name: "unknown"}},
+			`part #1: unknown field "unknown"`},
+		{"out of range", []tupleconv.IndexPart{{Field: 0}, {Field: 5}},
+			"part #2: field 5 is out of range of the space format"},
+		{"not indexed type", []tupleconv.IndexPart{{Name: "data"}},
+			"part #1: type map is not supported by indexes"},
+		{"type mismatch", []tupleconv.IndexPart{{Name: "name", Type: tupleconv.TypeUUID}},
+			"part #1: type uuid does not match field type string"},
+		{"path without type", []tupleconv.IndexPart{{Name: "data", Path: "a"}},
+			"part #1: type is required for the path"},
+		{"multikey path", []tupleconv.IndexPart{
+			{Name: "data", Path: "a[*]", Type: tupleconv.TypeString}},
+			`part #1: path "a[*]": multikey paths are not supported`},
+		{"invalid index", []tupleconv.IndexPart{
+			{Name: "data", Path: "[0]", Type: tupleconv.TypeString}},
+			`part #1: path "[0]": invalid index "0"`},
+		{"unknown collation", []tupleconv.IndexPart{
+			{Name: "name", Collation: "unicode_xx"}},
+			`part #1: unknown collation "unicode_xx"`},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := tupleconv.MakeKeyDef(indexedFormat, tc.parts)
+			assert.EqualError(t, err, tc.err)
+		})
+	}
+}
+
+func TestKeyDef_ExtractKey(t *testing.T) {
+	def, err := tupleconv.MakeKeyDef(indexedFormat, []tupleconv.IndexPart{
+		{Name: "data", Path: "user.name", Type: tupleconv.TypeString},
+		{Name: "data", Path: `["tags"][2]`, Type: tupleconv.TypeString, IsNullable: true},
+		{Field: 0},
+	})
+	require.NoError(t, err)
+
+	data := map[string]any{
+		"user": map[string]any{"name": "alice"},
+		"tags": []any{"a", "b"},
+	}
+	key, err := def.ExtractKey([]any{uint64(1), "Alice", nil, data})
+	require.NoError(t, err)
+	assert.Equal(t, []any{"alice", "b", uint64(1)}, key)
+
+	data = map[string]any{"user": map[string]any{"name": "bob"}}
+	key, err = def.ExtractKey([]any{uint64(2), "Bob", nil, data})
+	require.NoError(t, err)
+	assert.Equal(t, []any{"bob", nil, uint64(2)}, key)
+
+	// Parts of nullable fields are nullable.
+	key, err = def.ExtractKey([]any{uint64(3)})
+	require.NoError(t, err)
+	assert.Equal(t, []any{nil, nil, uint64(3)}, key)
+
+	_, err = def.ExtractKey([]any{})
+	assert.EqualError(t, err, `field #1 ("id") is missing`)
+	_, err = def.ExtractKey([]any{nil})
+	assert.EqualError(t, err, `field #1 ("id"): unexpected null value for non-nullable field`)
+	data = map[string]any{"user": map[string]any{"name": int64(-1)}}
+	_, err = def.ExtractKey([]any{uint64(3), "Carol", nil, data})
+	assert.EqualError(t, err,
+		`field #4 ("data.user.name"): unexpected integer value for string field`)
+}
+
+func TestKeyDef_CompareKeys(t *testing.T) {
+	def, err := tupleconv.MakeKeyDef(indexedFormat, []tupleconv.IndexPart{
+		{Name: "score"}, {Name: "name"},
+	})
+	require.NoError(t, err)
+
+	one, err := decimal.MakeDecimalFromString("1")
+	require.NoError(t, err)
+	half, err := decimal.MakeDecimalFromString("0.5")
+	require.NoError(t, err)
+
+	// Sorted keys.
+	keys := [][]any{
+		{nil, "a"},
+		{math.NaN(), "a"},
+		{math.Inf(-1), "a"},
+		{int64(-1), "a"},
+		{half, "a"},
+		{float64(0.75), "a"},
+		{one, "a"},
+		{uint64(1), "b"},
+		{float64(1), "C"},
+		{uint64(math.MaxUint64), "a"},
+		{math.Inf(1), "a"},
+	}
+	for i := range keys {
+		for j := range keys {
+			result, err := def.CompareKeys(keys[i], keys[j])
+			require.NoError(t, err)
+			assert.Equal(t, i < j, result < 0, "%v %v", keys[i], keys[j])
+			assert.Equal(t, i == j, result == 0, "%v %v", keys[i], keys[j])
+		}
+	}
+
+	// Equal numbers of different types.
+	result, err := def.CompareKeys([]any{one, "a"}, []any{uint64(1), "A"})
+	require.NoError(t, err)
+	assert.Zero(t, result)
+
+	// Partial keys.
+	result, err = def.CompareKeys([]any{uint64(1)}, []any{uint64(1), "b"})
+	require.NoError(t, err)
+	assert.Zero(t, result)
+
+	_, err = def.CompareKeys([]any{"1"}, []any{uint64(1)})
+	assert.EqualError(t, err, "part #1: unexpected string value for number field")
+	_, err = def.CompareKeys([]any{uint64(1), "a", "b"}, nil)
+	assert.EqualError(t, err, "key has more parts, than the index: 2")
+}
+
+func TestKeyDef_CompareCollations(t *testing.T) {
+	cases := []struct {
+		collation string
+		lhs, rhs  string
+		expected  int
+	}{
+		{"binary", "B", "a", -1},
+		{"binary", "ä", "b", 1},
+		{"unicode", "ä", "b", -1},
+		{"unicode", "a", "A", -1},
+		{"unicode_ci", "a", "A", 0},
+		{"unicode_ci", "Straße", "strasse", 0},
+		{"unicode_sv_s3", "ä", "z", 1},
+		{"unicode_de_s2", "ä", "A", 1},
+		{"unicode_de_s2", "Ä", "ä", 0},
+		{"unicode_de_s1", "Ä", "a", 0},
+	}
+	for _, tc := range cases {
+		t.Run(tc.collation+" "+tc.lhs+" "+tc.rhs, func(t *testing.T) {
+			def, err := tupleconv.MakeKeyDef(indexedFormat, []tupleconv.IndexPart{
+				{Name: "name", Collation: tc.collation},
+			})
+			require.NoError(t, err)
+			result, err := def.CompareKeys([]any{tc.lhs}, []any{tc.rhs})
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, result)
+		})
+	}
+}
+
+func TestKeyDef_CompareScalars(t *testing.T) {
+	def, err := tupleconv.MakeKeyDef(indexedFormat, []tupleconv.IndexPart{{Name: "value"}})
+	require.NoError(t, err)
+
+	earlier := mustDatetime(t, time.Date(2023, 8, 30, 12, 6, 5, 0, time.UTC))
+	later := mustDatetime(t, time.Date(2023, 8, 30, 15, 6, 5, 1,
+		time.FixedZone("", 3*60*60)))
+	values := []any{
+		nil,
+		false,
+		true,
+		int64(-5),
+		uint64(5),
+		"a",
+		[]byte("a"),
+		uuid.MustParse("00000000-0000-0000-0000-000000000001"),
+		uuid.MustParse("10000000-0000-0000-0000-000000000000"),
+		earlier,
+		later,
+	}
+	shuffled := append([]any{}, values...)
+	for i, j := 0, len(shuffled)-1; i < j; i, j = i+1, j-1 {
+		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+	}
+	sort.SliceStable(shuffled, func(i, j int) bool {
+		result, err := def.Compare([]any{nil, nil, nil, nil, shuffled[i]},
+			[]any{nil, nil, nil, nil, shuffled[j]})
+		require.NoError(t, err)
+		return result < 0
+	})
+	assert.Equal(t, values, shuffled)
+}
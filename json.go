@@ -0,0 +1,799 @@
+package tupleconv
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tarantool/go-tarantool/v2/datetime"
+	"github.com/tarantool/go-tarantool/v2/decimal"
+)
+
+// JSONRowShape is the shape of tuples in JSON.
+type JSONRowShape int
+
+const (
+	// JSONArrayRows represents tuples by JSON arrays.
+	JSONArrayRows JSONRowShape = iota
+	// JSONObjectRows represents tuples by JSON objects, keyed by the field names.
+	JSONObjectRows
+)
+
+// JSONEncoder encodes tuples of tarantool types to JSON by the space format:
+//   - decimal as an exact string or number;
+//   - datetime as RFC 3339 with the time zone name in brackets, if it is set,
+//     for example `2023-08-30T12:06:05+03:00[Europe/Moscow]`;
+//   - uuid as the canonical text;
+//   - interval as ISO 8601 duration, for example `P1Y2M3DT4H5M6.5S`;
+//   - varbinary as standard base64;
+//   - double as a number with a fraction or an exponent.
+//
+// Values of scalar and any fields, items of arrays and values of maps have no
+// type in the format, so decimal, datetime, uuid, interval and varbinary values
+// there are wrapped into objects with a single tag key, for example
+// `{"$decimal":"1.5"}` or `{"$binary":"AAH/"}`. Maps with keys, that are not
+// strings, are encoded as `{"$entries":[[key,value],...]}`, and maps with a
+// single key, that starts with "$", are wrapped into `{"$map":{...}}`, so
+// JSONDecoder decodes all of them back to the same values.
+type JSONEncoder struct {
+	spaceFmt []SpaceField
+	shape    JSONRowShape
+	// decimalAsNumber is true, if decimals are encoded as numbers.
+	decimalAsNumber bool
+}
+
+// MakeJSONEncoder creates JSONEncoder for the space format and the shape of rows.
+// Field names must be non-empty and unique for object rows.
+func MakeJSONEncoder(spaceFmt []SpaceField, shape JSONRowShape) (JSONEncoder, error) {
+	if shape == JSONObjectRows {
+		if _, err := MakeFieldNames(spaceFmt); err != nil {
+			return JSONEncoder{}, err
+		}
+	}
+	return JSONEncoder{spaceFmt: spaceFmt, shape: shape}, nil
+}
+
+// WithDecimalAsNumber sets whether decimals are encoded as numbers instead of
+// strings. Numbers are exact, but may lose precision in JSON parsers, that
+// decode them to float64. Decimals of number fields are tagged then, for
+// example `{"$decimal":10}`, to be decoded as decimals.
+func (encoder JSONEncoder) WithDecimalAsNumber(asNumber bool) JSONEncoder {
+	encoder.decimalAsNumber = asNumber
+	return encoder
+}
+
+// AppendTuple encodes the tuple and appends it to the buffer. The buffer is
+// returned unchanged on error.
+func (encoder JSONEncoder) AppendTuple(buf []byte, tuple []any) ([]byte, error) {
+	initial := buf
+	if encoder.shape == JSONObjectRows && len(tuple) > len(encoder.spaceFmt) {
+		return initial, fmt.Errorf("unknown field #%d", len(encoder.spaceFmt)+1)
+	}
+	open, end := byte('['), byte(']')
+	if encoder.shape == JSONObjectRows {
+		open, end = '{', '}'
+	}
+	buf = append(buf, open)
+	for i, value := range tuple {
+		field := SpaceField{Type: TypeAny, IsNullable: true}
+		if i < len(encoder.spaceFmt) {
+			field = encoder.spaceFmt[i]
+		}
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+		if encoder.shape == JSONObjectRows {
+			buf = appendJSONString(buf, field.Name)
+			buf = append(buf, ':')
+		}
+		var err error
+		if err = validateTTValue(field, value); err == nil {
+			// Decimals as numbers in number fields are tagged to not be decoded
+			// as unsigned, integer or double.
+			tagged := field.Type == TypeScalar || field.Type == TypeAny ||
+				field.Type == TypeNumber && encoder.decimalAsNumber
+			buf, err = encoder.appendValue(buf, value, tagged)
+		}
+		if err != nil {
+			return initial, fmt.Errorf("field #%d (%q): %w", i+1, field.Name, err)
+		}
+	}
+	for i := len(tuple); i < len(encoder.spaceFmt); i++ {
+		if !encoder.spaceFmt[i].IsNullable {
+			return initial, fmt.Errorf("field #%d (%q) is missing", i+1,
+				encoder.spaceFmt[i].Name)
+		}
+	}
+	return append(buf, end), nil
+}
+
+// WriteTuple encodes the tuple and writes it to the writer as a line of JSON
+// Lines.
+func (encoder JSONEncoder) WriteTuple(writer io.Writer, tuple []any) error {
+	buf, err := encoder.AppendTuple(nil, tuple)
+	if err != nil {
+		return err
+	}
+	_, err = writer.Write(append(buf, '\n'))
+	return err
+}
+
+// appendJSONString appends the string as JSON.
+func appendJSONString(buf []byte, value string) []byte {
+	// Marshaling of strings never fails.
+	data, _ := json.Marshal(value)
+	return append(buf, data...)
+}
+
+// Tags of values in JSON objects with a single key.
+const (
+	jsonTagDecimal  = "$decimal"
+	jsonTagDatetime = "$datetime"
+	jsonTagUUID     = "$uuid"
+	jsonTagInterval = "$interval"
+	jsonTagBinary   = "$binary"
+	jsonTagMap      = "$map"
+	jsonTagEntries  = "$entries"
+)
+
+// jsonValueTag returns the tag of the value, that can't be distinguished from
+// a string by its JSON, or an empty string.
+func jsonValueTag(value any) string {
+	switch value.(type) {
+	case decimal.Decimal:
+		return jsonTagDecimal
+	case datetime.Datetime:
+		return jsonTagDatetime
+	case uuid.UUID:
+		return jsonTagUUID
+	case datetime.Interval:
+		return jsonTagInterval
+	case []byte:
+		return jsonTagBinary
+	}
+	return ""
+}
+
+// appendValue appends the value as JSON by its type. The value is wrapped into
+// an object with its tag, if tagged is true.
+func (encoder JSONEncoder) appendValue(buf []byte, value any, tagged bool) ([]byte, error) {
+	var err error
+	if tag := jsonValueTag(value); tagged && tag != "" {
+		buf = append(buf, '{')
+		buf = appendJSONString(buf, tag)
+		buf = append(buf, ':')
+		if buf, err = encoder.appendValue(buf, value, false); err != nil {
+			return nil, err
+		}
+		return append(buf, '}'), nil
+	}
+	switch value := value.(type) {
+	case nil:
+		return append(buf, "null"...), nil
+	case bool:
+		return strconv.AppendBool(buf, value), nil
+	case uint64:
+		return strconv.AppendUint(buf, value, 10), nil
+	case int64:
+		return strconv.AppendInt(buf, value, 10), nil
+	case float64:
+		return appendJSONFloat(buf, value)
+	case string:
+		return appendJSONString(buf, value), nil
+	case []byte:
+		buf = append(buf, '"')
+		buf = append(buf, base64.StdEncoding.EncodeToString(value)...)
+		return append(buf, '"'), nil
+	case decimal.Decimal:
+		if encoder.decimalAsNumber {
+			return append(buf, value.String()...), nil
+		}
+		return appendJSONString(buf, value.String()), nil
+	case uuid.UUID:
+		return appendJSONString(buf, value.String()), nil
+	case datetime.Datetime:
+		return appendJSONString(buf, formatJSONDatetime(value)), nil
+	case datetime.Interval:
+		str, err := formatISOInterval(value)
+		if err != nil {
+			return nil, err
+		}
+		return appendJSONString(buf, str), nil
+	case []any:
+		buf = append(buf, '[')
+		for i, item := range value {
+			if i > 0 {
+				buf = append(buf, ',')
+			}
+			if buf, err = encoder.appendValue(buf, item, true); err != nil {
+				return nil, err
+			}
+		}
+		return append(buf, ']'), nil
+	case map[string]any:
+		return encoder.appendObject(buf, value)
+	case map[any]any:
+		keyValues := make(map[string]any, len(value))
+		for key, item := range value {
+			str, ok := key.(string)
+			if !ok {
+				return encoder.appendEntries(buf, value)
+			}
+			keyValues[str] = item
+		}
+		return encoder.appendObject(buf, keyValues)
+	}
+	return nil, fmt.Errorf("unexpected value of type %T", value)
+}
+
+// appendObject appends the map as a JSON object with sorted keys. The object
+// with a single key, that starts with "$", is wrapped to not be decoded as
+// a tagged value.
+func (encoder JSONEncoder) appendObject(buf []byte, value map[string]any) ([]byte, error) {
+	keys := make([]string, 0, len(value))
+	for key := range value {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	escaped := len(keys) == 1 && strings.HasPrefix(keys[0], "$")
+	if escaped {
+		buf = append(buf, '{')
+		buf = appendJSONString(buf, jsonTagMap)
+		buf = append(buf, ':')
+	}
+	buf = append(buf, '{')
+	for i, key := range keys {
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+		buf = appendJSONString(buf, key)
+		buf = append(buf, ':')
+		var err error
+		if buf, err = encoder.appendValue(buf, value[key], true); err != nil {
+			return nil, err
+		}
+	}
+	if buf = append(buf, '}'); escaped {
+		buf = append(buf, '}')
+	}
+	return buf, nil
+}
+
+// appendEntries appends the map with keys, that are not strings, as a tagged
+// array of key-value pairs, sorted by their JSON.
+func (encoder JSONEncoder) appendEntries(buf []byte, value map[any]any) ([]byte, error) {
+	entries := make([][]byte, 0, len(value))
+	for key, item := range value {
+		entry, err := encoder.appendValue([]byte{'['}, key, true)
+		if err != nil {
+			return nil, err
+		}
+		if entry, err = encoder.appendValue(append(entry, ','), item, true); err != nil {
+			return nil, err
+		}
+		entries = append(entries, append(entry, ']'))
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return bytes.Compare(entries[i], entries[j]) < 0
+	})
+	buf = append(buf, '{')
+	buf = appendJSONString(buf, jsonTagEntries)
+	buf = append(buf, ":["...)
+	for i, entry := range entries {
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+		buf = append(buf, entry...)
+	}
+	return append(buf, "]}"...), nil
+}
+
+// appendJSONFloat appends the float as a JSON number with a fraction or an
+// exponent, so it is decoded as double.
+func appendJSONFloat(buf []byte, value float64) ([]byte, error) {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return nil, fmt.Errorf("%v can't be represented in JSON", value)
+	}
+	start := len(buf)
+	buf = strconv.AppendFloat(buf, value, 'g', -1, 64)
+	if !bytes.ContainsAny(buf[start:], ".e") {
+		buf = append(buf, ".0"...)
+	}
+	return buf, nil
+}
+
+// formatJSONDatetime formats the datetime as RFC 3339 with the time zone name.
+// Zones, that can't be loaded by time.LoadLocation, get the offset of the
+// string, when decoded.
+func formatJSONDatetime(value datetime.Datetime) string {
+	tm := value.ToTime()
+	str := tm.Format(time.RFC3339Nano)
+	if zone := tm.Location().String(); zone != datetime.NoTimezone {
+		str += "[" + zone + "]"
+	}
+	return str
+}
+
+// parseJSONDatetime parses the datetime in RFC 3339 with the optional time zone
+// name in brackets.
+func parseJSONDatetime(src string) (datetime.Datetime, error) {
+	str, zone := src, ""
+	if strings.HasSuffix(src, "]") {
+		open := strings.LastIndexByte(src, '[')
+		if open < 0 {
+			return datetime.Datetime{}, fmt.Errorf("invalid datetime %q", src)
+		}
+		str, zone = src[:open], src[open+1:len(src)-1]
+	}
+	tm, err := time.Parse(time.RFC3339Nano, str)
+	if err != nil {
+		return datetime.Datetime{}, err
+	}
+	if zone == "" {
+		return makeDatetime(tm)
+	}
+	// Zones, that can't be loaded, for example, abbreviations like MSK, get
+	// the offset of the string, the same way, as go-tarantool decodes them.
+	_, offset := tm.Zone()
+	loc, err := time.LoadLocation(zone)
+	if err != nil {
+		return datetime.MakeDatetime(tm.In(time.FixedZone(zone, offset)))
+	}
+	if tm = tm.In(loc); !hasOffset(tm, offset) {
+		return datetime.Datetime{}, fmt.Errorf("offset of %q doesn't match the time zone", src)
+	}
+	return datetime.MakeDatetime(tm)
+}
+
+// hasOffset returns true, if the time has the offset.
+func hasOffset(tm time.Time, offset int) bool {
+	_, tmOffset := tm.Zone()
+	return tmOffset == offset
+}
+
+// nsecPerSec is the number of nanoseconds in a second.
+const nsecPerSec = int64(time.Second)
+
+// errIntervalISO is returned for intervals, that can't be represented in ISO 8601.
+var errIntervalISO = errors.New("interval can't be represented in ISO 8601")
+
+// formatISOInterval formats the interval as ISO 8601 duration. Components may be
+// negative. Intervals with adjust and with seconds and nanoseconds of different
+// signs can't be represented.
+func formatISOInterval(value datetime.Interval) (string, error) {
+	if value.Adjust != datetime.NoneAdjust {
+		return "", errIntervalISO
+	}
+	if value.Nsec <= -nsecPerSec || value.Nsec >= nsecPerSec ||
+		value.Sec > 0 && value.Nsec < 0 || value.Sec < 0 && value.Nsec > 0 {
+		return "", errIntervalISO
+	}
+	buf := []byte{'P'}
+	appendComponent := func(number int64, designator byte) {
+		if number != 0 {
+			buf = strconv.AppendInt(buf, number, 10)
+			buf = append(buf, designator)
+		}
+	}
+	appendComponent(value.Year, 'Y')
+	appendComponent(value.Month, 'M')
+	appendComponent(value.Week, 'W')
+	appendComponent(value.Day, 'D')
+	if value.Hour == 0 && value.Min == 0 && value.Sec == 0 && value.Nsec == 0 {
+		if len(buf) == 1 {
+			return "PT0S", nil
+		}
+		return string(buf), nil
+	}
+	buf = append(buf, 'T')
+	appendComponent(value.Hour, 'H')
+	appendComponent(value.Min, 'M')
+	if value.Nsec == 0 {
+		appendComponent(value.Sec, 'S')
+		return string(buf), nil
+	}
+	sec, nsec := value.Sec, value.Nsec
+	if sec < 0 || nsec < 0 {
+		buf = append(buf, '-')
+		sec, nsec = -sec, -nsec
+	}
+	buf = strconv.AppendInt(buf, sec, 10)
+	fraction := strings.TrimRight(fmt.Sprintf("%09d", nsec), "0")
+	buf = append(buf, '.')
+	buf = append(buf, fraction...)
+	return string(append(buf, 'S')), nil
+}
+
+// parseISOInterval parses ISO 8601 duration with optionally negative components.
+func parseISOInterval(src string) (datetime.Interval, error) {
+	var interval datetime.Interval
+	invalid := fmt.Errorf("invalid ISO 8601 duration %q", src)
+	if len(src) < 3 || src[0] != 'P' {
+		return interval, invalid
+	}
+	dateComponents := []*int64{&interval.Year, &interval.Month, &interval.Week, &interval.Day}
+	timeComponents := []*int64{&interval.Hour, &interval.Min, &interval.Sec}
+	components, designators := dateComponents, "YMWD"
+	// next is the index of the next allowed designator.
+	next := 0
+	for str := src[1:]; str != ""; {
+		if str[0] == 'T' {
+			if len(components) == len(timeComponents) || len(str) == 1 {
+				return interval, invalid
+			}
+			components, designators, next = timeComponents, "HMS", 0
+			str = str[1:]
+			continue
+		}
+		end := strings.IndexFunc(str, func(r rune) bool {
+			return r != '-' && r != '.' && (r < '0' || r > '9')
+		})
+		if end <= 0 {
+			return interval, invalid
+		}
+		number, designator := str[:end], str[end]
+		str = str[end+1:]
+		pos := strings.IndexByte(designators, designator)
+		if pos < next {
+			return interval, invalid
+		}
+		next = pos + 1
+		if designator == 'S' && len(components) == len(timeComponents) {
+			sec, nsec, err := parseISOSeconds(number)
+			if err != nil {
+				return interval, invalid
+			}
+			interval.Sec, interval.Nsec = sec, nsec
+			continue
+		}
+		value, err := strconv.ParseInt(number, 10, 64)
+		if err != nil {
+			return interval, invalid
+		}
+		*components[pos] = value
+	}
+	return interval, nil
+}
+
+// parseISOSeconds parses seconds with the optional fraction.
+func parseISOSeconds(src string) (int64, int64, error) {
+	whole, fraction, hasFraction := strings.Cut(src, ".")
+	sec, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+	if !hasFraction {
+		return sec, 0, nil
+	}
+	if fraction == "" || len(fraction) > 9 {
+		return 0, 0, fmt.Errorf("invalid fraction %q", fraction)
+	}
+	nsec, err := strconv.ParseUint(fraction+strings.Repeat("0", 9-len(fraction)), 10, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+	if strings.HasPrefix(whole, "-") {
+		return sec, -int64(nsec), nil
+	}
+	return sec, int64(nsec), nil
+}
+
+// JSONDecoder decodes tuples from JSON, encoded by JSONEncoder, by the space
+// format. Decimals are accepted as strings and numbers.
+//
+// Values of array, map, scalar and any fields are decoded by their JSON types:
+// numbers with a fraction or an exponent as double, other numbers as unsigned
+// or integer, strings as strings, objects with a single tag key, written by
+// JSONEncoder, as the tagged values. Maps with keys, that are not strings, are
+// decoded as map[any]any, other maps as map[string]any.
+type JSONDecoder struct {
+	spaceFmt []SpaceField
+	shape    JSONRowShape
+	names    FieldNames
+}
+
+// MakeJSONDecoder creates JSONDecoder for the space format and the shape of rows.
+// Field names must be non-empty and unique for object rows.
+func MakeJSONDecoder(spaceFmt []SpaceField, shape JSONRowShape) (JSONDecoder, error) {
+	decoder := JSONDecoder{spaceFmt: spaceFmt, shape: shape}
+	if shape == JSONObjectRows {
+		var err error
+		if decoder.names, err = MakeFieldNames(spaceFmt); err != nil {
+			return JSONDecoder{}, err
+		}
+	}
+	return decoder, nil
+}
+
+// ReadTuple decodes a tuple from the JSON data. Array rows may be shorter than
+// the format, if the missing fields are nullable, and longer, extra fields are
+// decoded as any. Missing nullable fields of object rows are nil.
+func (decoder JSONDecoder) ReadTuple(data []byte) ([]any, error) {
+	jsonDecoder := json.NewDecoder(bytes.NewReader(data))
+	jsonDecoder.UseNumber()
+	var row any
+	if err := jsonDecoder.Decode(&row); err != nil {
+		return nil, err
+	}
+	if jsonDecoder.More() {
+		return nil, fmt.Errorf("unexpected data after the tuple")
+	}
+	var values []any
+	switch row := row.(type) {
+	case []any:
+		if decoder.shape != JSONArrayRows {
+			return nil, fmt.Errorf("tuple object expected, got array")
+		}
+		for i := len(row); i < len(decoder.spaceFmt); i++ {
+			if !decoder.spaceFmt[i].IsNullable {
+				return nil, fmt.Errorf("field #%d (%q) is missing", i+1,
+					decoder.spaceFmt[i].Name)
+			}
+		}
+		values = row
+	case map[string]any:
+		if decoder.shape != JSONObjectRows {
+			return nil, fmt.Errorf("tuple array expected, got object")
+		}
+		var err error
+		if values, err = ToPositional(decoder.names, row); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("tuple expected, got %v", row)
+	}
+
+	tuple := make([]any, len(values))
+	for i, value := range values {
+		field := SpaceField{Type: TypeAny, IsNullable: true}
+		if i < len(decoder.spaceFmt) {
+			field = decoder.spaceFmt[i]
+		}
+		var err error
+		if tuple[i], err = decodeJSONValue(field, value); err != nil {
+			return nil, fmt.Errorf("field #%d (%q): %w", i+1, field.Name, err)
+		}
+	}
+	return tuple, nil
+}
+
+// DecodeTuple reads the next JSON value from the decoder and decodes a tuple.
+func (decoder JSONDecoder) DecodeTuple(jsonDecoder *json.Decoder) ([]any, error) {
+	var raw json.RawMessage
+	if err := jsonDecoder.Decode(&raw); err != nil {
+		return nil, err
+	}
+	return decoder.ReadTuple(raw)
+}
+
+// decodeJSONNumber decodes the JSON number as double, if it has a fraction or
+// an exponent, or as unsigned or integer.
+func decodeJSONNumber(value json.Number) (any, error) {
+	if strings.ContainsAny(string(value), ".eE") {
+		return strconv.ParseFloat(string(value), 64)
+	}
+	return integerFromNumber(value)
+}
+
+// decodeJSONNested decodes numbers and tagged values of the JSON value
+// recursively.
+func decodeJSONNested(value any) (any, error) {
+	var err error
+	switch value := value.(type) {
+	case json.Number:
+		return decodeJSONNumber(value)
+	case []any:
+		for i, item := range value {
+			if value[i], err = decodeJSONNested(item); err != nil {
+				return nil, err
+			}
+		}
+	case map[string]any:
+		if len(value) == 1 {
+			for key, item := range value {
+				if isJSONTag(key) {
+					return decodeJSONTagged(key, item)
+				}
+			}
+		}
+		return decodeJSONObject(value)
+	}
+	return value, nil
+}
+
+// decodeJSONObject decodes values of the JSON object recursively.
+func decodeJSONObject(value map[string]any) (map[string]any, error) {
+	var err error
+	for key, item := range value {
+		if value[key], err = decodeJSONNested(item); err != nil {
+			return nil, err
+		}
+	}
+	return value, nil
+}
+
+// isJSONTag returns true, if the key is a tag of JSONEncoder.
+func isJSONTag(key string) bool {
+	switch key {
+	case jsonTagDecimal, jsonTagDatetime, jsonTagUUID, jsonTagInterval, jsonTagBinary,
+		jsonTagMap, jsonTagEntries:
+		return true
+	}
+	return false
+}
+
+// decodeJSONTagged decodes the JSON value, tagged by JSONEncoder.
+func decodeJSONTagged(tag string, value any) (any, error) {
+	str, isString := value.(string)
+	switch tag {
+	case jsonTagDecimal:
+		if number, ok := value.(json.Number); ok {
+			return decimal.MakeDecimalFromString(string(number))
+		}
+		if isString {
+			return decimal.MakeDecimalFromString(str)
+		}
+	case jsonTagDatetime:
+		if isString {
+			return parseJSONDatetime(str)
+		}
+	case jsonTagUUID:
+		if isString {
+			return uuid.Parse(str)
+		}
+	case jsonTagInterval:
+		if isString {
+			return parseISOInterval(str)
+		}
+	case jsonTagBinary:
+		if isString {
+			return base64.StdEncoding.DecodeString(str)
+		}
+	case jsonTagMap:
+		if object, ok := value.(map[string]any); ok {
+			return decodeJSONObject(object)
+		}
+	case jsonTagEntries:
+		if entries, ok := value.([]any); ok {
+			return decodeJSONEntries(entries)
+		}
+	}
+	return nil, fmt.Errorf("unexpected JSON value %v for %q", value, tag)
+}
+
+// decodeJSONEntries decodes the array of key-value pairs as a map.
+func decodeJSONEntries(entries []any) (map[any]any, error) {
+	result := make(map[any]any, len(entries))
+	for _, entry := range entries {
+		pair, ok := entry.([]any)
+		if !ok || len(pair) != 2 {
+			return nil, fmt.Errorf("unexpected map entry %v", entry)
+		}
+		key, err := decodeJSONNested(pair[0])
+		if err != nil {
+			return nil, err
+		}
+		switch key.(type) {
+		case []any, []byte, map[string]any, map[any]any:
+			return nil, fmt.Errorf("unexpected map key %v", pair[0])
+		}
+		if result[key], err = decodeJSONNested(pair[1]); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// decodeJSONValue decodes the JSON value of the field.
+func decodeJSONValue(field SpaceField, value any) (any, error) {
+	if value == nil {
+		if field.IsNullable || field.Type == TypeAny {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("unexpected null value for non-nullable field")
+	}
+	number, isNumber := value.(json.Number)
+	str, isString := value.(string)
+	switch field.Type {
+	case TypeBoolean:
+		if boolean, ok := value.(bool); ok {
+			return boolean, nil
+		}
+	case TypeString:
+		if isString {
+			return str, nil
+		}
+	case TypeUnsigned, TypeInteger:
+		if !isNumber {
+			break
+		}
+		result, err := integerFromNumber(number)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := result.(int64); ok && field.Type == TypeUnsigned {
+			return nil, errNegativeUnsigned
+		}
+		return result, nil
+	case TypeDouble:
+		if isNumber {
+			return strconv.ParseFloat(string(number), 64)
+		}
+	case TypeNumber:
+		if isNumber {
+			return decodeJSONNumber(number)
+		}
+		if isString {
+			return decimal.MakeDecimalFromString(str)
+		}
+		if object, ok := value.(map[string]any); ok && len(object) == 1 {
+			if tagged, ok := object[jsonTagDecimal]; ok {
+				return decodeJSONTagged(jsonTagDecimal, tagged)
+			}
+		}
+	case TypeDecimal:
+		if isNumber {
+			return decimal.MakeDecimalFromString(string(number))
+		}
+		if isString {
+			return decimal.MakeDecimalFromString(str)
+		}
+	case TypeDatetime:
+		if isString {
+			return parseJSONDatetime(str)
+		}
+	case TypeUUID:
+		if isString {
+			return uuid.Parse(str)
+		}
+	case TypeInterval:
+		if isString {
+			return parseISOInterval(str)
+		}
+	case TypeVarbinary:
+		if isString {
+			return base64.StdEncoding.DecodeString(str)
+		}
+	case TypeArray:
+		if _, ok := value.([]any); ok {
+			return decodeJSONNested(value)
+		}
+	case TypeMap:
+		if _, ok := value.(map[string]any); !ok {
+			break
+		}
+		result, err := decodeJSONNested(value)
+		if err != nil {
+			return nil, err
+		}
+		switch result.(type) {
+		case map[string]any, map[any]any:
+			return result, nil
+		}
+	case TypeScalar:
+		if _, ok := value.([]any); ok {
+			break
+		}
+		result, err := decodeJSONNested(value)
+		if err != nil {
+			return nil, err
+		}
+		switch result.(type) {
+		case map[string]any, map[any]any:
+		default:
+			return result, nil
+		}
+	case TypeAny:
+		return decodeJSONNested(value)
+	}
+	return nil, fmt.Errorf("unexpected JSON value %v for %s field", value, field.Type)
+}
@@ -0,0 +1,321 @@
+package tupleconv_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tarantool/go-tarantool/v2/datetime"
+
+	"github.com/tarantool/go-tupleconv"
+)
+
+var jsonFormat = []tupleconv.SpaceField{
+	{Name: "id", Type: tupleconv.TypeUnsigned},
+	{Name: "balance", Type: tupleconv.TypeDecimal},
+	{Name: "created", Type: tupleconv.TypeDatetime},
+	{Name: "key", Type: tupleconv.TypeUUID},
+	{Name: "timeout", Type: tupleconv.TypeInterval},
+	{Name: "data", Type: tupleconv.TypeVarbinary},
+	{Name: "ratio", Type: tupleconv.TypeDouble},
+	{Name: "attrs", Type: tupleconv.TypeMap},
+	{Name: "comment", Type: tupleconv.TypeString, IsNullable: true},
+}
+
+func makeJSONTuple(t *testing.T) []any {
+	moscow, err := time.LoadLocation("Europe/Moscow")
+	require.NoError(t, err)
+	return []any{
+		uint64(1),
+		mustDecimal(t, "123456789012345678901234567890.5"),
+		mustDatetime(t, time.Date(2023, 8, 30, 12, 6, 5, 123456789, moscow)),
+		uuid.MustParse("09b56913-11f0-4fa4-b5d0-901b5efa532a"),
+		datetime.Interval{Year: 1, Day: -3, Hour: 4, Sec: 6, Nsec: 500000000},
+		[]byte{0, 1, 0xff},
+		float64(2),
+		map[string]any{"b": []any{int64(-1), 1.5, "x"}, "a": nil},
+		nil,
+	}
+}
+
+func TestJSONEncoder(t *testing.T) {
+	tuple := makeJSONTuple(t)
+
+	encoder, err := tupleconv.MakeJSONEncoder(jsonFormat, tupleconv.JSONArrayRows)
+	require.NoError(t, err)
+	data, err := encoder.AppendTuple(nil, tuple)
+	require.NoError(t, err)
+	assert.Equal(t, `[1,"123456789012345678901234567890.5",`+
+		`"2023-08-30T12:06:05.123456789+03:00[Europe/Moscow]",`+
+		`"09b56913-11f0-4fa4-b5d0-901b5efa532a","P1Y-3DT4H6.5S","AAH/",2.0,`+
+		`{"a":null,"b":[-1,1.5,"x"]},null]`, string(data))
+
+	encoder, err = tupleconv.MakeJSONEncoder(jsonFormat[:3], tupleconv.JSONObjectRows)
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	require.NoError(t, encoder.WithDecimalAsNumber(true).WriteTuple(&buf, tuple[:3]))
+	assert.Equal(t, `{"id":1,"balance":123456789012345678901234567890.5,`+
+		`"created":"2023-08-30T12:06:05.123456789+03:00[Europe/Moscow]"}`+"\n",
+		buf.String())
+}
+
+func TestJSONEncoder_errors(t *testing.T) {
+	_, err := tupleconv.MakeJSONEncoder([]tupleconv.SpaceField{{Type: tupleconv.TypeAny}},
+		tupleconv.JSONObjectRows)
+	assert.EqualError(t, err, "field #1 has no name")
+
+	encoder, err := tupleconv.MakeJSONEncoder(jsonFormat, tupleconv.JSONArrayRows)
+	require.NoError(t, err)
+	tuple := makeJSONTuple(t)
+
+	cases := []struct {
+		pos   int
+		value any
+		err   string
+	}{
+		{0, nil, `field #1 ("id"): unexpected null value for non-nullable field`},
+		{0, "1", `field #1 ("id"): unexpected string value for unsigned field`},
+		{6, math.Inf(1), `field #7 ("ratio"): +Inf can't be represented in JSON`},
+		{
+			4, datetime.Interval{Adjust: datetime.LastAdjust},
+			`field #5 ("timeout"): interval can't be represented in ISO 8601`,
+		},
+		{
+			4, datetime.Interval{Sec: 1, Nsec: -1},
+			`field #5 ("timeout"): interval can't be represented in ISO 8601`,
+		},
+	}
+	for _, tc := range cases {
+		invalid := append([]any{}, tuple...)
+		invalid[tc.pos] = tc.value
+		buf, err := encoder.AppendTuple([]byte("x"), invalid)
+		assert.EqualError(t, err, tc.err)
+		assert.Equal(t, []byte("x"), buf)
+	}
+
+	_, err = encoder.AppendTuple(nil, tuple[:2])
+	assert.EqualError(t, err, `field #3 ("created") is missing`)
+}
+
+func TestJSONDecoder_roundTrip(t *testing.T) {
+	tuple := makeJSONTuple(t)
+	for _, shape := range []tupleconv.JSONRowShape{
+		tupleconv.JSONArrayRows, tupleconv.JSONObjectRows,
+	} {
+		for _, asNumber := range []bool{false, true} {
+			encoder, err := tupleconv.MakeJSONEncoder(jsonFormat, shape)
+			require.NoError(t, err)
+			data, err := encoder.WithDecimalAsNumber(asNumber).AppendTuple(nil, tuple)
+			require.NoError(t, err)
+
+			decoder, err := tupleconv.MakeJSONDecoder(jsonFormat, shape)
+			require.NoError(t, err)
+			decoded, err := decoder.ReadTuple(data)
+			require.NoError(t, err, string(data))
+			assert.Equal(t, tuple, decoded)
+		}
+	}
+}
+
+func TestJSONDecoder_roundTripDynamic(t *testing.T) {
+	spaceFmt := []tupleconv.SpaceField{
+		{Name: "scalar", Type: tupleconv.TypeScalar},
+		{Name: "any", Type: tupleconv.TypeAny},
+		{Name: "array", Type: tupleconv.TypeArray},
+		{Name: "map", Type: tupleconv.TypeMap},
+	}
+	values := makeJSONTuple(t)[:7]
+	values = append(values, int64(-1), true, "$decimal", "09b56913-11f0-4fa4-b5d0-901b5efa532a",
+		mustDatetime(t, time.Date(2023, 8, 30, 12, 6, 5, 0, time.UTC)), []byte{})
+	nested := []any{
+		nil,
+		[]any{"a", uint64(1)},
+		map[string]any{"$decimal": "1.5"},
+		map[string]any{"$x": []any{}},
+		map[any]any{uint64(1): "a", "b": mustDecimal(t, "2.5"), false: nil},
+		map[any]any{uuid.MustParse("09b56913-11f0-4fa4-b5d0-901b5efa532a"): []byte{1}},
+	}
+
+	for _, asNumber := range []bool{false, true} {
+		encoder, err := tupleconv.MakeJSONEncoder(spaceFmt, tupleconv.JSONArrayRows)
+		require.NoError(t, err)
+		encoder = encoder.WithDecimalAsNumber(asNumber)
+		decoder, err := tupleconv.MakeJSONDecoder(spaceFmt, tupleconv.JSONArrayRows)
+		require.NoError(t, err)
+
+		for _, value := range append(values, nested...) {
+			tuple := []any{
+				value, value, []any{value, []any{value}},
+				map[string]any{"a": value, "b": map[string]any{"c": value}},
+			}
+			if _, ok := value.([]any); ok || value == nil {
+				tuple[0] = "x"
+			}
+			switch value.(type) {
+			case map[string]any, map[any]any:
+				tuple[0] = "x"
+			}
+			data, err := encoder.AppendTuple(nil, tuple)
+			require.NoError(t, err)
+			decoded, err := decoder.ReadTuple(data)
+			require.NoError(t, err, string(data))
+			assert.Equal(t, tuple, decoded, string(data))
+		}
+	}
+
+	encoder, err := tupleconv.MakeJSONEncoder(spaceFmt[1:2], tupleconv.JSONArrayRows)
+	require.NoError(t, err)
+	data, err := encoder.AppendTuple(nil, []any{[]any{
+		mustDecimal(t, "1.5"), []byte{0, 1, 0xff}, map[string]any{"$a": "b"},
+		map[any]any{uint64(2): "b", int64(-1): "a"},
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, `[[{"$decimal":"1.5"},{"$binary":"AAH/"},{"$map":{"$a":"b"}},`+
+		`{"$entries":[[-1,"a"],[2,"b"]]}]]`, string(data))
+}
+
+func TestJSONDecoder_roundTripNumber(t *testing.T) {
+	spaceFmt := []tupleconv.SpaceField{{Name: "value", Type: tupleconv.TypeNumber}}
+	encoder, err := tupleconv.MakeJSONEncoder(spaceFmt, tupleconv.JSONArrayRows)
+	require.NoError(t, err)
+	decoder, err := tupleconv.MakeJSONDecoder(spaceFmt, tupleconv.JSONArrayRows)
+	require.NoError(t, err)
+
+	values := []any{mustDecimal(t, "10"), mustDecimal(t, "1.5"), uint64(10), int64(-1), 1.5}
+	for _, asNumber := range []bool{false, true} {
+		for _, value := range values {
+			data, err := encoder.WithDecimalAsNumber(asNumber).AppendTuple(nil, []any{value})
+			require.NoError(t, err)
+			decoded, err := decoder.ReadTuple(data)
+			require.NoError(t, err, string(data))
+			assert.Equal(t, []any{value}, decoded, string(data))
+		}
+	}
+
+	data, err := encoder.WithDecimalAsNumber(true).AppendTuple(nil,
+		[]any{mustDecimal(t, "10")})
+	require.NoError(t, err)
+	assert.Equal(t, `[{"$decimal":10}]`, string(data))
+}
+
+func TestJSONDecoder_abbreviatedZone(t *testing.T) {
+	spaceFmt := []tupleconv.SpaceField{{Name: "created", Type: tupleconv.TypeDatetime}}
+	encoder, err := tupleconv.MakeJSONEncoder(spaceFmt, tupleconv.JSONArrayRows)
+	require.NoError(t, err)
+	decoder, err := tupleconv.MakeJSONDecoder(spaceFmt, tupleconv.JSONArrayRows)
+	require.NoError(t, err)
+
+	msk := time.FixedZone("MSK", 3*60*60)
+	tuple := []any{mustDatetime(t, time.Date(2023, 8, 30, 12, 6, 5, 0, msk))}
+	data, err := encoder.AppendTuple(nil, tuple)
+	require.NoError(t, err)
+	assert.Equal(t, `["2023-08-30T12:06:05+03:00[MSK]"]`, string(data))
+	decoded, err := decoder.ReadTuple(data)
+	require.NoError(t, err)
+	assert.Equal(t, tuple, decoded)
+
+	_, err = decoder.ReadTuple([]byte(`["2023-08-30T12:06:05+03:00[XYZ]"]`))
+	assert.EqualError(t, err, `field #1 ("created"): unknown timezone XYZ with offset 10800`)
+}
+
+func TestJSONDecoder(t *testing.T) {
+	spaceFmt := []tupleconv.SpaceField{
+		{Name: "id", Type: tupleconv.TypeInteger},
+		{Name: "value", Type: tupleconv.TypeNumber},
+		{Name: "data", Type: tupleconv.TypeScalar, IsNullable: true},
+	}
+	decoder, err := tupleconv.MakeJSONDecoder(spaceFmt, tupleconv.JSONArrayRows)
+	require.NoError(t, err)
+
+	cases := []struct {
+		data     string
+		expected []any
+		err      string
+	}{
+		{data: `[-1, 2, "a"]`, expected: []any{int64(-1), uint64(2), "a"}},
+		{data: `[1, 2.5]`, expected: []any{uint64(1), 2.5}},
+		{data: `[1, "1.5", 1e2, [1]]`, expected: []any{uint64(1), mustDecimal(t, "1.5"),
+			100.0, []any{uint64(1)}}},
+		{data: `[1]`, err: `field #2 ("value") is missing`},
+		{data: `[1.5, 1]`, err: `field #1 ("id"): 1.5 is not an integer`},
+		{data: `[1, 1, [1]]`, err: `field #3 ("data"): unexpected JSON value [1] for scalar field`},
+		{data: `[1, 1, {"$uuid": "1"}]`, err: `field #3 ("data"): invalid UUID length: 1`},
+		{
+			data: `[1, 1, {"$uuid": 1}]`,
+			err:  `field #3 ("data"): unexpected JSON value 1 for "$uuid"`,
+		},
+		{
+			data: `[1, 1, {"$map": {}}]`,
+			err:  `field #3 ("data"): unexpected JSON value map[$map:map[]] for scalar field`,
+		},
+		{
+			data: `[1, 1, {"$entries": [[[1], 2]]}]`,
+			err:  `field #3 ("data"): unexpected map key [1]`,
+		},
+		{data: `{"id": 1}`, err: `tuple array expected, got object`},
+		{data: `[1, 1] [2, 2]`, err: `unexpected data after the tuple`},
+	}
+	for _, tc := range cases {
+		t.Run(tc.data, func(t *testing.T) {
+			tuple, err := decoder.ReadTuple([]byte(tc.data))
+			if tc.err != "" {
+				assert.EqualError(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, tuple)
+		})
+	}
+
+	decoder, err = tupleconv.MakeJSONDecoder(spaceFmt, tupleconv.JSONObjectRows)
+	require.NoError(t, err)
+	jsonDecoder := json.NewDecoder(strings.NewReader(
+		`{"id": 1, "value": 2}` + "\n" + `{"id": 1, "extra": 2}`))
+	tuple, err := decoder.DecodeTuple(jsonDecoder)
+	require.NoError(t, err)
+	assert.Equal(t, []any{uint64(1), uint64(2), nil}, tuple)
+	_, err = decoder.DecodeTuple(jsonDecoder)
+	assert.EqualError(t, err, `unknown field "extra"`)
+}
+
+func TestJSONDecoder_interval(t *testing.T) {
+	spaceFmt := []tupleconv.SpaceField{{Name: "interval", Type: tupleconv.TypeInterval}}
+	encoder, err := tupleconv.MakeJSONEncoder(spaceFmt, tupleconv.JSONArrayRows)
+	require.NoError(t, err)
+	decoder, err := tupleconv.MakeJSONDecoder(spaceFmt, tupleconv.JSONArrayRows)
+	require.NoError(t, err)
+
+	cases := []struct {
+		interval datetime.Interval
+		iso      string
+	}{
+		{datetime.Interval{}, "PT0S"},
+		{datetime.Interval{Week: 2}, "P2W"},
+		{datetime.Interval{Month: 1, Min: -5}, "P1MT-5M"},
+		{datetime.Interval{Nsec: -1}, "PT-0.000000001S"},
+		{datetime.Interval{Sec: -2, Nsec: -500000000}, "PT-2.5S"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.iso, func(t *testing.T) {
+			data, err := encoder.AppendTuple(nil, []any{tc.interval})
+			require.NoError(t, err)
+			assert.Equal(t, `["`+tc.iso+`"]`, string(data))
+			tuple, err := decoder.ReadTuple(data)
+			require.NoError(t, err)
+			assert.Equal(t, []any{tc.interval}, tuple)
+		})
+	}
+
+	for _, invalid := range []string{"P", "PT", "P1H", "PT1D", "P1D1Y", "P1.5D", "PT1.S",
+		"PT0.1234567891S", "1D"} {
+		_, err := decoder.ReadTuple([]byte(`["` + invalid + `"]`))
+		assert.EqualError(t, err,
+			`field #1 ("interval"): invalid ISO 8601 duration "`+invalid+`"`, invalid)
+	}
+}
@@ -1,13 +1,19 @@
 package tupleconv
 
 import (
+	"context"
 	"fmt"
 )
 
-// Mapper performs tuple mapping.
+// Mapper performs tuple mapping. It is safe for concurrent use, if its
+// converters and transforms are.
 type Mapper[S any, T any] struct {
 	converters       []Converter[S, T]
 	defaultConverter *Converter[S, T]
+	// preTransforms are applied to source tuples before the field conversion.
+	preTransforms []Converter[[]S, []S]
+	// postTransforms are applied to the converted tuples.
+	postTransforms []Converter[[]T, []T]
 }
 
 // MakeMapper creates Mapper.
@@ -21,9 +27,50 @@ func (mapper Mapper[S, T]) WithDefaultConverter(converter Converter[S, T]) Mappe
 	return mapper
 }
 
+// WithPreTransform adds a transform of the whole source tuple, that is applied
+// before the field conversion, after the previously added ones. It may merge,
+// split, drop and reorder fields, the converters are chosen by the positions in
+// the transformed tuple. Transforms must not modify the input tuples.
+func (mapper Mapper[S, T]) WithPreTransform(transform Converter[[]S, []S]) Mapper[S, T] {
+	mapper.preTransforms = append(
+		append([]Converter[[]S, []S]{}, mapper.preTransforms...), transform)
+	return mapper
+}
+
+// WithPostTransform adds a transform of the whole converted tuple, that is
+// applied after the field conversion, after the previously added ones.
+// Transforms must not modify the input tuples.
+func (mapper Mapper[S, T]) WithPostTransform(transform Converter[[]T, []T]) Mapper[S, T] {
+	mapper.postTransforms = append(
+		append([]Converter[[]T, []T]{}, mapper.postTransforms...), transform)
+	return mapper
+}
+
+// hasTransforms returns true, if the mapper has transforms.
+func (mapper Mapper[S, T]) hasTransforms() bool {
+	return len(mapper.preTransforms) > 0 || len(mapper.postTransforms) > 0
+}
+
+// applyTransforms applies the transforms to the tuple in order.
+func applyTransforms[V any](ctx context.Context, transforms []Converter[[]V, []V],
+	tuple []V) ([]V, error) {
+	var err error
+	for _, transform := range transforms {
+		if tuple, err = convertContext(ctx, transform, tuple); err != nil {
+			return nil, err
+		}
+	}
+	return tuple, nil
+}
+
 // validateTuple validates tuple in accordance with the Mapper properties.
 func (mapper Mapper[S, T]) validateTuple(tuple []S) error {
-	if len(tuple) > len(mapper.converters) && mapper.defaultConverter == nil {
+	return mapper.validateLen(len(tuple))
+}
+
+// validateLen validates tuple length in accordance with the Mapper properties.
+func (mapper Mapper[S, T]) validateLen(tupleLen int) error {
+	if tupleLen > len(mapper.converters) && mapper.defaultConverter == nil {
 		return fmt.Errorf("tuple length should be less or equal converters list length, " +
 			"when default converter is not used")
 	}
@@ -32,20 +79,85 @@ func (mapper Mapper[S, T]) validateTuple(tuple []S) error {
 
 // Map maps tuple until the first error.
 func (mapper Mapper[S, T]) Map(tuple []S) ([]T, error) {
+	tuple, err := applyTransforms(context.Background(), mapper.preTransforms, tuple)
+	if err != nil {
+		return nil, err
+	}
 	if err := mapper.validateTuple(tuple); err != nil {
 		return nil, err
 	}
-	var err error
 	result := make([]T, len(tuple))
 	for i, field := range tuple {
-		if i < len(mapper.converters) {
-			result[i], err = mapper.converters[i].Convert(field)
-		} else {
-			result[i], err = (*mapper.defaultConverter).Convert(field)
-		}
-		if err != nil {
+		if result[i], err = mapper.convert(i, field); err != nil {
 			return nil, err
 		}
 	}
+	return applyTransforms(context.Background(), mapper.postTransforms, result)
+}
+
+// MapTo maps tuple until the first error into dst, reusing its capacity. The
+// result has the length of the tuple, dst is reallocated only if its capacity
+// is not enough. On error, dst is returned with zero length to be reused.
+// With post-transforms, the result is the one of the last transform.
+func (mapper Mapper[S, T]) MapTo(dst []T, tuple []S) ([]T, error) {
+	tuple, err := applyTransforms(context.Background(), mapper.preTransforms, tuple)
+	if err != nil {
+		return dst[:0], err
+	}
+	if err := mapper.validateTuple(tuple); err != nil {
+		return dst[:0], err
+	}
+	if cap(dst) < len(tuple) {
+		dst = make([]T, len(tuple))
+	}
+	dst = dst[:len(tuple)]
+	for i, field := range tuple {
+		if dst[i], err = mapper.convert(i, field); err != nil {
+			return dst[:0], err
+		}
+	}
+	if len(mapper.postTransforms) == 0 {
+		return dst, nil
+	}
+	result, err := applyTransforms(context.Background(), mapper.postTransforms, dst)
+	if err != nil {
+		return dst[:0], err
+	}
 	return result, nil
 }
+
+// MapContext maps tuple until the first error like Map, but passes the context to
+// the converters and the transforms, that implement ContextConverter. The context
+// error is returned, if the context is done before the mapping.
+func (mapper Mapper[S, T]) MapContext(ctx context.Context, tuple []S) ([]T, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	tuple, err := applyTransforms(ctx, mapper.preTransforms, tuple)
+	if err != nil {
+		return nil, err
+	}
+	if err := mapper.validateTuple(tuple); err != nil {
+		return nil, err
+	}
+	result := make([]T, len(tuple))
+	for i, field := range tuple {
+		if result[i], err = convertContext(ctx, mapper.converter(i), field); err != nil {
+			return nil, err
+		}
+	}
+	return applyTransforms(ctx, mapper.postTransforms, result)
+}
+
+// converter returns the converter of the field at the position.
+func (mapper Mapper[S, T]) converter(pos int) Converter[S, T] {
+	if pos < len(mapper.converters) {
+		return mapper.converters[pos]
+	}
+	return *mapper.defaultConverter
+}
+
+// convert converts the field at the position.
+func (mapper Mapper[S, T]) convert(pos int, field S) (T, error) {
+	return mapper.converter(pos).Convert(field)
+}
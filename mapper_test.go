@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestMapper_singleMapper(t *testing.T) {
@@ -160,3 +161,88 @@ func TestMapper_partialMapper(t *testing.T) {
 		})
 	}
 }
+
+func TestMapper_MapTo(t *testing.T) {
+	mapper := tupleconv.MakeMapper([]tupleconv.Converter[string, any]{
+		tupleconv.MakeStringToUIntConverter(" "),
+		tupleconv.MakeIdentityConverter[string](),
+	})
+
+	dst := make([]any, 0, 4)
+	result, err := mapper.MapTo(dst, []string{"1 000", "a"})
+	require.NoError(t, err)
+	assert.Equal(t, []any{uint64(1000), "a"}, result)
+	assert.Equal(t, &dst[:1][0], &result[0], "the buffer should be reused")
+
+	result, err = mapper.MapTo(result, []string{"2"})
+	require.NoError(t, err)
+	assert.Equal(t, []any{uint64(2)}, result)
+
+	result, err = mapper.MapTo(result, []string{"bad", "a"})
+	assert.Error(t, err)
+	assert.Empty(t, result)
+	assert.Equal(t, 4, cap(result))
+
+	_, err = mapper.MapTo(nil, []string{"1", "a", "b"})
+	assert.Error(t, err)
+
+	result, err = mapper.MapTo(nil, []string{"3", "b"})
+	require.NoError(t, err)
+	assert.Equal(t, []any{uint64(3), "b"}, result)
+}
+
+// BenchmarkMapper benchmarks the mapping of tuples with and without separators.
+// MapTo allocates only the converted values, boxed into any, and decimals:
+// 8 allocs/op for the default and the plain tuples. The separators tuple
+// needs 9 allocs/op: the decimal with separators is copied once.
+func BenchmarkMapper(b *testing.B) {
+	spaceFmt := []tupleconv.SpaceField{
+		{Name: "id", Type: tupleconv.TypeUnsigned},
+		{Name: "balance", Type: tupleconv.TypeInteger},
+		{Name: "name", Type: tupleconv.TypeString},
+		{Name: "rate", Type: tupleconv.TypeDouble},
+		{Name: "amount", Type: tupleconv.TypeDecimal},
+		{Name: "active", Type: tupleconv.TypeBoolean},
+		{Name: "comment", Type: tupleconv.TypeString, IsNullable: true},
+	}
+	defaultFac := tupleconv.MakeStringToTTConvFactory()
+	separatorsFac := defaultFac.WithThousandSeparators(" '").WithDecimalSeparators(",")
+
+	cases := []struct {
+		name  string
+		fac   tupleconv.StringToTTConvFactory
+		tuple []string
+	}{
+		{"default", defaultFac, []string{"100500", "-42", "Alice", "1.5", "12345.678", "true", ""}},
+		{"plain", separatorsFac,
+			[]string{"100500", "-42", "Alice", "1.5", "12345.678", "true", ""}},
+		{"separators", separatorsFac,
+			[]string{"100 500", "-4'200", "Alice", "1 000,5", "12 345,678", "true", ""}},
+	}
+	for _, tc := range cases {
+		tuple := tc.tuple
+		converters, err := tupleconv.MakeTypeToTTConverters[string](tc.fac, spaceFmt)
+		require.NoError(b, err)
+		mapper := tupleconv.MakeMapper(converters)
+
+		b.Run(tc.name+"/Map", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := mapper.Map(tuple); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+
+		b.Run(tc.name+"/MapTo", func(b *testing.B) {
+			b.ReportAllocs()
+			var dst []any
+			for i := 0; i < b.N; i++ {
+				var err error
+				if dst, err = mapper.MapTo(dst, tuple); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
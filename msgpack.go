@@ -0,0 +1,162 @@
+package tupleconv
+
+import (
+	"io"
+	"sync"
+
+	"github.com/vmihailenco/msgpack/v5"
+
+	_ "github.com/tarantool/go-tarantool/v2/uuid"
+)
+
+// MsgpackMapper maps tuples and encodes the results to MessagePack without building
+// intermediate []any tuples. Values of decimal, uuid, datetime and interval types
+// are encoded as MP_EXT by the go-tarantool extension encoders.
+type MsgpackMapper[S any] struct {
+	mapper Mapper[S, any]
+}
+
+// MakeMsgpackMapper creates MsgpackMapper.
+func MakeMsgpackMapper[S any](mapper Mapper[S, any]) MsgpackMapper[S] {
+	return MsgpackMapper[S]{mapper: mapper}
+}
+
+// EncodeTuple maps the tuple and encodes it as a MessagePack array.
+// On error, a part of the tuple may be already encoded.
+func (mapper MsgpackMapper[S]) EncodeTuple(encoder *msgpack.Encoder, tuple []S) error {
+	if mapper.mapper.hasTransforms() {
+		// Transforms need the whole tuple.
+		mapped, err := mapper.mapper.Map(tuple)
+		if err != nil {
+			return err
+		}
+		if err := encoder.EncodeArrayLen(len(mapped)); err != nil {
+			return err
+		}
+		for _, value := range mapped {
+			if err := encodeValue(encoder, value); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := mapper.mapper.validateLen(len(tuple)); err != nil {
+		return err
+	}
+	if err := encoder.EncodeArrayLen(len(tuple)); err != nil {
+		return err
+	}
+	for i, field := range tuple {
+		value, err := mapper.mapper.convert(i, field)
+		if err != nil {
+			return err
+		}
+		if err := encodeValue(encoder, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// EncodeTuples maps the tuples and encodes them as a MessagePack array of arrays.
+func (mapper MsgpackMapper[S]) EncodeTuples(encoder *msgpack.Encoder, tuples [][]S) error {
+	if err := encoder.EncodeArrayLen(len(tuples)); err != nil {
+		return err
+	}
+	for _, tuple := range tuples {
+		if err := mapper.EncodeTuple(encoder, tuple); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteTuple maps the tuple and writes it to the writer as a MessagePack array.
+// The writer should be buffered, values are written in small chunks.
+func (mapper MsgpackMapper[S]) WriteTuple(writer io.Writer, tuple []S) error {
+	encoder := msgpack.GetEncoder()
+	defer msgpack.PutEncoder(encoder)
+	encoder.Reset(writer)
+	encoder.UseCompactInts(true)
+	return mapper.EncodeTuple(encoder, tuple)
+}
+
+// AppendTuple maps the tuple and appends it to the buffer as a MessagePack array.
+// The buffer is returned unchanged on error.
+func (mapper MsgpackMapper[S]) AppendTuple(buf []byte, tuple []S) ([]byte, error) {
+	return appendEncoded(buf, func(encoder *msgpack.Encoder) error {
+		return mapper.EncodeTuple(encoder, tuple)
+	})
+}
+
+// AppendTuples maps the tuples and appends them to the buffer as a MessagePack
+// array of arrays. The buffer is returned unchanged on error.
+func (mapper MsgpackMapper[S]) AppendTuples(buf []byte, tuples [][]S) ([]byte, error) {
+	return appendEncoded(buf, func(encoder *msgpack.Encoder) error {
+		return mapper.EncodeTuples(encoder, tuples)
+	})
+}
+
+// encodeValue encodes the converted value. Basic types are encoded directly,
+// other ones are encoded with the registered encoders.
+func encodeValue(encoder *msgpack.Encoder, value any) error {
+	switch value := value.(type) {
+	case nil:
+		return encoder.EncodeNil()
+	case bool:
+		return encoder.EncodeBool(value)
+	case int64:
+		return encoder.EncodeInt(value)
+	case uint64:
+		return encoder.EncodeUint(value)
+	case float64:
+		return encoder.EncodeFloat64(value)
+	case string:
+		return encoder.EncodeString(value)
+	case []byte:
+		return encoder.EncodeBytes(value)
+	default:
+		return encoder.Encode(value)
+	}
+}
+
+// appendWriter is an encoder, that appends encoded data to a buffer.
+type appendWriter struct {
+	buf     []byte
+	encoder *msgpack.Encoder
+}
+
+var appendWriterPool = sync.Pool{
+	New: func() any {
+		writer := &appendWriter{}
+		writer.encoder = msgpack.NewEncoder(writer)
+		writer.encoder.UseCompactInts(true)
+		return writer
+	},
+}
+
+// Write is the implementation of io.Writer for appendWriter.
+func (writer *appendWriter) Write(data []byte) (int, error) {
+	writer.buf = append(writer.buf, data...)
+	return len(data), nil
+}
+
+// WriteByte is the implementation of io.ByteWriter for appendWriter.
+func (writer *appendWriter) WriteByte(char byte) error {
+	writer.buf = append(writer.buf, char)
+	return nil
+}
+
+// appendEncoded appends data, encoded by the function, to the buffer.
+func appendEncoded(buf []byte, encode func(*msgpack.Encoder) error) ([]byte, error) {
+	writer := appendWriterPool.Get().(*appendWriter)
+	defer appendWriterPool.Put(writer)
+	writer.buf = buf
+	err := encode(writer.encoder)
+	result := writer.buf
+	writer.buf = nil
+	if err != nil {
+		return buf, err
+	}
+	return result, nil
+}
@@ -0,0 +1,168 @@
+package tupleconv_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tarantool/go-tarantool/v2/datetime"
+	"github.com/vmihailenco/msgpack/v5"
+
+	"github.com/tarantool/go-tupleconv"
+)
+
+var msgpackTestFormat = []tupleconv.SpaceField{
+	{Type: tupleconv.TypeUnsigned},
+	{Type: tupleconv.TypeInteger},
+	{Type: tupleconv.TypeString},
+	{Type: tupleconv.TypeDouble},
+	{Type: tupleconv.TypeBoolean},
+	{Type: tupleconv.TypeDecimal},
+	{Type: tupleconv.TypeUUID},
+	{Type: tupleconv.TypeDatetime},
+	{Type: tupleconv.TypeInterval},
+	{Type: tupleconv.TypeArray},
+	{Type: tupleconv.TypeMap},
+	{Type: tupleconv.TypeVarbinary},
+	{Type: tupleconv.TypeString, IsNullable: true},
+}
+
+var msgpackTestTuple = []string{
+	"100500", "-42", "hello", "1.5", "true", "12345.678",
+	"09b56913-11f0-4fa4-b5d0-901b5efa532a", "2023-08-30T12:06:05.120-0000",
+	"1,2,0,0,0,0,0,0,1", "[1,\"a\",[]]", "{\"a\":{\"b\":null}}", "bin", "",
+}
+
+func makeMsgpackTestMapper(t testing.TB) tupleconv.Mapper[string, any] {
+	converters, err := tupleconv.MakeTypeToTTConverters[string](
+		tupleconv.MakeStringToTTConvFactory(), msgpackTestFormat)
+	require.NoError(t, err)
+	return tupleconv.MakeMapper(converters)
+}
+
+// decodeMsgpack decodes all MessagePack values from the data.
+func decodeMsgpack(t *testing.T, data []byte) []any {
+	decoder := msgpack.NewDecoder(bytes.NewReader(data))
+	var values []any
+	for {
+		value, err := decoder.DecodeInterface()
+		if err != nil {
+			break
+		}
+		values = append(values, value)
+	}
+	return values
+}
+
+func TestMsgpackMapper_AppendTuple(t *testing.T) {
+	mapper := makeMsgpackTestMapper(t)
+	expected, err := mapper.Map(msgpackTestTuple)
+	require.NoError(t, err)
+	var expectedData bytes.Buffer
+	encoder := msgpack.NewEncoder(&expectedData)
+	encoder.UseCompactInts(true)
+	require.NoError(t, encoder.Encode(expected))
+
+	msgpackMapper := tupleconv.MakeMsgpackMapper(mapper)
+	prefix := []byte{0xc0}
+	data, err := msgpackMapper.AppendTuple(prefix, msgpackTestTuple)
+	require.NoError(t, err)
+	assert.Equal(t, byte(0xc0), data[0])
+	assert.Equal(t, expectedData.Bytes(), data[1:])
+
+	values := decodeMsgpack(t, data[1:])
+	require.Len(t, values, 1)
+	tuple := values[0].([]any)
+	assert.IsType(t, uuid.UUID{}, tuple[6])
+	assert.IsType(t, datetime.Datetime{}, tuple[7])
+	assert.IsType(t, datetime.Interval{}, tuple[8])
+	assert.Equal(t, []byte("bin"), tuple[11])
+}
+
+func TestMsgpackMapper_AppendTuples(t *testing.T) {
+	msgpackMapper := tupleconv.MakeMsgpackMapper(makeMsgpackTestMapper(t))
+	data, err := msgpackMapper.AppendTuples(nil,
+		[][]string{{"1", "-1"}, {"2", "-2", "x"}})
+	require.NoError(t, err)
+
+	var tuples [][]any
+	require.NoError(t, msgpack.Unmarshal(data, &tuples))
+	assert.Equal(t, [][]any{{int8(1), int8(-1)}, {int8(2), int8(-2), "x"}}, tuples)
+}
+
+func TestMsgpackMapper_compactInts(t *testing.T) {
+	msgpackMapper := tupleconv.MakeMsgpackMapper(makeMsgpackTestMapper(t))
+	data, err := msgpackMapper.AppendTuple(nil, []string{"1", "-1"})
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0x92, 0x01, 0xff}, data)
+}
+
+func TestMsgpackMapper_errors(t *testing.T) {
+	msgpackMapper := tupleconv.MakeMsgpackMapper(makeMsgpackTestMapper(t))
+	buf := []byte{1, 2}
+
+	result, err := msgpackMapper.AppendTuple(buf, []string{"1", "x"})
+	assert.Error(t, err)
+	assert.Equal(t, []byte{1, 2}, result)
+
+	tooLong := make([]string, len(msgpackTestFormat)+1)
+	_, err = msgpackMapper.AppendTuple(nil, tooLong)
+	assert.Error(t, err)
+
+	_, err = msgpackMapper.AppendTuples(nil, [][]string{{"1"}, {"-1"}})
+	assert.Error(t, err)
+}
+
+func TestMsgpackMapper_WriteTuple(t *testing.T) {
+	msgpackMapper := tupleconv.MakeMsgpackMapper(makeMsgpackTestMapper(t))
+	var buf bytes.Buffer
+	require.NoError(t, msgpackMapper.WriteTuple(&buf, []string{"1", "-1"}))
+	require.NoError(t, msgpackMapper.WriteTuple(&buf, []string{"2"}))
+	assert.Equal(t, []byte{0x92, 0x01, 0xff, 0x91, 0x02}, buf.Bytes())
+
+	encoder := msgpack.NewEncoder(&buf)
+	buf.Reset()
+	require.NoError(t, msgpackMapper.EncodeTuples(encoder, [][]string{{"3"}}))
+	assert.Equal(t, []byte{0x91, 0x91, 0x03}, buf.Bytes())
+}
+
+func BenchmarkMsgpackMapper(b *testing.B) {
+	mapper := makeMsgpackTestMapper(b)
+	msgpackMapper := tupleconv.MakeMsgpackMapper(mapper)
+	tuples := map[string][]string{
+		"scalar": msgpackTestTuple[:5],
+		"all":    msgpackTestTuple,
+	}
+
+	for name, tuple := range tuples {
+		tuple := tuple
+		b.Run(name+"/AppendTuple", func(b *testing.B) {
+			b.ReportAllocs()
+			var buf []byte
+			for i := 0; i < b.N; i++ {
+				var err error
+				if buf, err = msgpackMapper.AppendTuple(buf[:0], tuple); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+
+		b.Run(name+"/MapAndEncode", func(b *testing.B) {
+			b.ReportAllocs()
+			var buf bytes.Buffer
+			encoder := msgpack.NewEncoder(&buf)
+			for i := 0; i < b.N; i++ {
+				buf.Reset()
+				mapped, err := mapper.Map(tuple)
+				if err != nil {
+					b.Fatal(err)
+				}
+				if err := encoder.Encode(mapped); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
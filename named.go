@@ -0,0 +1,148 @@
+package tupleconv
+
+import (
+	"context"
+	"fmt"
+)
+
+// FieldNames is an index of space field names, that is used to convert tuples
+// between the positional and the named forms.
+type FieldNames struct {
+	names      []string
+	positions  map[string]int
+	isNullable []bool
+}
+
+// MakeFieldNames creates FieldNames by the space format.
+// Field names must be non-empty and unique.
+func MakeFieldNames(spaceFmt []SpaceField) (FieldNames, error) {
+	names := FieldNames{
+		names:      make([]string, len(spaceFmt)),
+		positions:  make(map[string]int, len(spaceFmt)),
+		isNullable: make([]bool, len(spaceFmt)),
+	}
+	for i, field := range spaceFmt {
+		if field.Name == "" {
+			return FieldNames{}, fmt.Errorf("field #%d has no name", i+1)
+		}
+		if _, ok := names.positions[field.Name]; ok {
+			return FieldNames{}, fmt.Errorf("duplicate field name %q", field.Name)
+		}
+		names.names[i] = field.Name
+		names.positions[field.Name] = i
+		names.isNullable[i] = field.IsNullable
+	}
+	return names, nil
+}
+
+// Names returns the field names in the positional order.
+func (names FieldNames) Names() []string {
+	return append([]string(nil), names.names...)
+}
+
+// Position returns the position of the field with the name.
+func (names FieldNames) Position(name string) (int, bool) {
+	pos, ok := names.positions[name]
+	return pos, ok
+}
+
+// validateNamed checks that the named tuple has no unknown fields and all
+// non-nullable fields are present.
+func validateNamed[T any](names FieldNames, named map[string]T) error {
+	for name := range named {
+		if _, ok := names.positions[name]; !ok {
+			return fmt.Errorf("unknown field %q", name)
+		}
+	}
+	for i, name := range names.names {
+		if _, ok := named[name]; !ok && !names.isNullable[i] {
+			return fmt.Errorf("missing field %q", name)
+		}
+	}
+	return nil
+}
+
+// ToNamed converts the positional tuple to the named form.
+// The tuple may be shorter than the format, if the missing fields are nullable.
+// Missing fields are absent in the result.
+func ToNamed[T any](names FieldNames, tuple []T) (map[string]T, error) {
+	if len(tuple) > len(names.names) {
+		return nil, fmt.Errorf("unknown field #%d", len(names.names)+1)
+	}
+	for i := len(tuple); i < len(names.names); i++ {
+		if !names.isNullable[i] {
+			return nil, fmt.Errorf("missing field %q", names.names[i])
+		}
+	}
+	named := make(map[string]T, len(tuple))
+	for i, value := range tuple {
+		named[names.names[i]] = value
+	}
+	return named, nil
+}
+
+// ToPositional converts the named tuple to the positional form.
+// Missing nullable fields are filled with the zero value.
+func ToPositional[T any](names FieldNames, named map[string]T) ([]T, error) {
+	if err := validateNamed(names, named); err != nil {
+		return nil, err
+	}
+	tuple := make([]T, len(names.names))
+	for i, name := range names.names {
+		tuple[i] = named[name]
+	}
+	return tuple, nil
+}
+
+// NamedMapper performs tuple mapping with named tuples on the one side.
+type NamedMapper[S any, T any] struct {
+	mapper Mapper[S, T]
+	names  FieldNames
+}
+
+// MakeNamedMapper creates NamedMapper by the Mapper and the space format.
+func MakeNamedMapper[S any, T any](
+	mapper Mapper[S, T], spaceFmt []SpaceField) (NamedMapper[S, T], error) {
+	names, err := MakeFieldNames(spaceFmt)
+	if err != nil {
+		return NamedMapper[S, T]{}, err
+	}
+	return NamedMapper[S, T]{mapper: mapper, names: names}, nil
+}
+
+// Map maps the positional tuple to the named tuple until the first error.
+func (mapper NamedMapper[S, T]) Map(tuple []S) (map[string]T, error) {
+	mapped, err := mapper.mapper.Map(tuple)
+	if err != nil {
+		return nil, err
+	}
+	return ToNamed(mapper.names, mapped)
+}
+
+// MapNamed maps the named tuple to the positional tuple until the first error.
+// Missing nullable fields are not converted and are filled with the zero value.
+// The named tuple is keyed by the space format, so pre-transforms of the mapper
+// are not supported, post-transforms are applied.
+func (mapper NamedMapper[S, T]) MapNamed(named map[string]S) ([]T, error) {
+	if len(mapper.mapper.preTransforms) > 0 {
+		return nil, fmt.Errorf("pre-transforms are not supported for named tuples")
+	}
+	if err := validateNamed(mapper.names, named); err != nil {
+		return nil, err
+	}
+	if err := mapper.mapper.validateLen(len(mapper.names.names)); err != nil {
+		return nil, err
+	}
+	result := make([]T, len(mapper.names.names))
+	for i, name := range mapper.names.names {
+		field, ok := named[name]
+		if !ok {
+			continue
+		}
+		var err error
+		if result[i], err = mapper.mapper.convert(i, field); err != nil {
+			return nil, fmt.Errorf("field %q: %w", name, err)
+		}
+	}
+	return applyTransforms(context.Background(), mapper.mapper.postTransforms, result)
+}
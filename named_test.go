@@ -0,0 +1,169 @@
+package tupleconv_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tarantool/go-tupleconv"
+)
+
+var namedTestSpaceFmt = []tupleconv.SpaceField{
+	{Name: "id", Type: tupleconv.TypeUnsigned},
+	{Name: "name", Type: tupleconv.TypeString},
+	{Name: "score", Type: tupleconv.TypeDouble, IsNullable: true},
+}
+
+func TestMakeFieldNames(t *testing.T) {
+	names, err := tupleconv.MakeFieldNames(namedTestSpaceFmt)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"id", "name", "score"}, names.Names())
+	pos, ok := names.Position("score")
+	assert.True(t, ok)
+	assert.Equal(t, 2, pos)
+	_, ok = names.Position("unknown")
+	assert.False(t, ok)
+
+	_, err = tupleconv.MakeFieldNames([]tupleconv.SpaceField{{Name: "a"}, {Name: "a"}})
+	assert.EqualError(t, err, `duplicate field name "a"`)
+
+	_, err = tupleconv.MakeFieldNames([]tupleconv.SpaceField{{Name: "a"}, {}})
+	assert.EqualError(t, err, "field #2 has no name")
+}
+
+func TestToNamed(t *testing.T) {
+	names, err := tupleconv.MakeFieldNames(namedTestSpaceFmt)
+	require.NoError(t, err)
+
+	cases := []struct {
+		name     string
+		tuple    []any
+		expected map[string]any
+		err      string
+	}{
+		{
+			name:     "full",
+			tuple:    []any{uint64(1), "a", 2.5},
+			expected: map[string]any{"id": uint64(1), "name": "a", "score": 2.5},
+		},
+		{
+			name:     "missing nullable",
+			tuple:    []any{uint64(1), "a"},
+			expected: map[string]any{"id": uint64(1), "name": "a"},
+		},
+		{
+			name:  "missing non-nullable",
+			tuple: []any{uint64(1)},
+			err:   `missing field "name"`,
+		},
+		{
+			name:  "unknown",
+			tuple: []any{uint64(1), "a", 2.5, "extra"},
+			err:   "unknown field #4",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			named, err := tupleconv.ToNamed(names, tc.tuple)
+			if tc.err != "" {
+				assert.EqualError(t, err, tc.err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expected, named)
+			}
+		})
+	}
+}
+
+func TestToPositional(t *testing.T) {
+	names, err := tupleconv.MakeFieldNames(namedTestSpaceFmt)
+	require.NoError(t, err)
+
+	cases := []struct {
+		name     string
+		named    map[string]any
+		expected []any
+		err      string
+	}{
+		{
+			name:     "full",
+			named:    map[string]any{"id": uint64(1), "name": "a", "score": 2.5},
+			expected: []any{uint64(1), "a", 2.5},
+		},
+		{
+			name:     "missing nullable",
+			named:    map[string]any{"id": uint64(1), "name": "a"},
+			expected: []any{uint64(1), "a", nil},
+		},
+		{
+			name:  "missing non-nullable",
+			named: map[string]any{"name": "a"},
+			err:   `missing field "id"`,
+		},
+		{
+			name:  "unknown",
+			named: map[string]any{"id": uint64(1), "name": "a", "extra": 1},
+			err:   `unknown field "extra"`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tuple, err := tupleconv.ToPositional(names, tc.named)
+			if tc.err != "" {
+				assert.EqualError(t, err, tc.err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expected, tuple)
+			}
+		})
+	}
+}
+
+func TestNamedMapper(t *testing.T) {
+	fac := tupleconv.MakeStringToTTConvFactory().WithNullValue("null")
+	converters, err := tupleconv.MakeTypeToTTConverters[string](fac, namedTestSpaceFmt)
+	require.NoError(t, err)
+	mapper, err := tupleconv.MakeNamedMapper(tupleconv.MakeMapper(converters), namedTestSpaceFmt)
+	require.NoError(t, err)
+
+	named, err := mapper.Map([]string{"1", "a", "null"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{"id": uint64(1), "name": "a", "score": nil}, named)
+
+	named, err = mapper.Map([]string{"1", "a"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{"id": uint64(1), "name": "a"}, named)
+
+	_, err = mapper.Map([]string{"x", "a"})
+	assert.Error(t, err)
+
+	_, err = mapper.Map([]string{"1", "a", "2", "3"})
+	assert.Error(t, err)
+
+	tuple, err := mapper.MapNamed(map[string]string{"id": "1", "name": "a", "score": "2.5"})
+	assert.NoError(t, err)
+	assert.Equal(t, []any{uint64(1), "a", 2.5}, tuple)
+
+	tuple, err = mapper.MapNamed(map[string]string{"id": "1", "name": "a"})
+	assert.NoError(t, err)
+	assert.Equal(t, []any{uint64(1), "a", nil}, tuple)
+
+	_, err = mapper.MapNamed(map[string]string{"id": "x", "name": "a"})
+	assert.EqualError(t, err, `field "id": unexpected value x for type "unsigned"`)
+
+	_, err = mapper.MapNamed(map[string]string{"id": "1"})
+	assert.EqualError(t, err, `missing field "name"`)
+
+	_, err = mapper.MapNamed(map[string]string{"id": "1", "name": "a", "age": "3"})
+	assert.EqualError(t, err, `unknown field "age"`)
+}
+
+func TestNamedMapper_shortMapper(t *testing.T) {
+	mapper, err := tupleconv.MakeNamedMapper(
+		tupleconv.MakeMapper([]tupleconv.Converter[string, any]{}), namedTestSpaceFmt)
+	require.NoError(t, err)
+	_, err = mapper.MapNamed(map[string]string{"id": "1", "name": "a"})
+	assert.Error(t, err)
+}
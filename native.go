@@ -0,0 +1,494 @@
+package tupleconv
+
+import (
+	"context"
+	"encoding"
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	dec "github.com/shopspring/decimal"
+	"github.com/tarantool/go-tarantool/v2/datetime"
+	"github.com/tarantool/go-tarantool/v2/decimal"
+)
+
+// NativeToTTConvFactory is a TTConvFactory for native Go values:
+//   - bool and integer, floating-point and string kinds;
+//   - time.Time for datetime and time.Duration for interval;
+//   - *big.Int, *big.Float and decimal types for decimal;
+//   - uuid.UUID and [16]byte for uuid;
+//   - []byte for varbinary;
+//   - slices, arrays and maps for array and map;
+//   - types implementing encoding.TextMarshaler for string.
+//
+// Values of any and scalar fields, items of arrays and maps are converted by
+// their dynamic types, types implementing encoding.TextMarshaler are converted
+// to strings then.
+//
+// Integers are converted to uint64, if they are not negative, or to int64, values
+// out of the range of the field type and values, that can't be converted without
+// precision loss, are errors. Values of tarantool types from go-tarantool are
+// accepted as is. Pointers are dereferenced, nil pointers are null values.
+type NativeToTTConvFactory struct{}
+
+// MakeNativeToTTConvFactory creates NativeToTTConvFactory.
+func MakeNativeToTTConvFactory() NativeToTTConvFactory {
+	return NativeToTTConvFactory{}
+}
+
+var _ TTConvFactory[any] = (*NativeToTTConvFactory)(nil)
+
+var (
+	bigIntType   = reflect.TypeOf((*big.Int)(nil))
+	bigFloatType = reflect.TypeOf((*big.Float)(nil))
+	uuidType     = reflect.TypeOf(uuid.UUID{})
+
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// errUnexpectedNull is returned for null values of non-nullable fields.
+var errUnexpectedNull = errors.New("unexpected null value for non-nullable field")
+
+// derefNative dereferences pointers to the value, except *big.Int and *big.Float.
+// ok is false, if the value is nil or a nil pointer.
+func derefNative(value any) (result any, ok bool) {
+	if value == nil {
+		return nil, false
+	}
+	rv := reflect.ValueOf(value)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil, false
+		}
+		if rv.Type() == bigIntType || rv.Type() == bigFloatType {
+			break
+		}
+		rv = rv.Elem()
+	}
+	return rv.Interface(), true
+}
+
+// makeNativeConverter makes a converter from a native value, that dereferences
+// pointers before the conversion.
+func makeNativeConverter(convFunc func(any) (any, error)) Converter[any, any] {
+	return MakeFuncConverter(func(value any) (any, error) {
+		value, ok := derefNative(value)
+		if !ok {
+			return nil, errUnexpectedNull
+		}
+		return convFunc(value)
+	})
+}
+
+// nativeInteger converts the value of an integer kind to uint64, if it is not
+// negative, or to int64. ok is false, if the value is not of an integer kind.
+func nativeInteger(value any) (result any, ok bool) {
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if rv.Int() >= 0 {
+			return uint64(rv.Int()), true
+		}
+		return rv.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Uintptr:
+		return rv.Uint(), true
+	}
+	return nil, false
+}
+
+// nativeFloat converts the value of a floating-point kind to float64. ok is false,
+// if the value is not of a floating-point kind.
+func nativeFloat(value any) (result float64, bitSize int, ok bool) {
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Float32:
+		return rv.Float(), 32, true
+	case reflect.Float64:
+		return rv.Float(), 64, true
+	}
+	return 0, 0, false
+}
+
+// bigIntToInteger converts the big integer to uint64, if it is not negative, or
+// to int64.
+func bigIntToInteger(value *big.Int) (any, error) {
+	if value.IsUint64() {
+		return value.Uint64(), nil
+	}
+	if value.IsInt64() {
+		return value.Int64(), nil
+	}
+	return nil, fmt.Errorf("%s is out of range", value)
+}
+
+// bigFloatToFloat converts the big float to float64.
+func bigFloatToFloat(value *big.Float) (float64, error) {
+	result, accuracy := value.Float64()
+	if accuracy != big.Exact {
+		return 0, fmt.Errorf("%s can't be converted to double without precision loss",
+			value.Text('g', -1))
+	}
+	return result, nil
+}
+
+// toNativeInteger converts the integral value to uint64, if it is not negative,
+// or to int64.
+func toNativeInteger(value any, typ TypeName) (any, error) {
+	if result, ok := nativeInteger(value); ok {
+		return result, nil
+	}
+	if float, _, ok := nativeFloat(value); ok {
+		return integerFromFloat(float)
+	}
+	switch value := value.(type) {
+	case *big.Int:
+		return bigIntToInteger(value)
+	case *big.Float:
+		if !value.IsInt() {
+			return nil, fmt.Errorf("%s is not an integer", value.Text('g', -1))
+		}
+		integer, _ := value.Int(nil)
+		return bigIntToInteger(integer)
+	}
+	return nil, unexpectedValueError(value, typ)
+}
+
+// toNativeDouble converts the number to float64.
+func toNativeDouble(value any) (any, error) {
+	if float, _, ok := nativeFloat(value); ok {
+		return float, nil
+	}
+	if integer, ok := nativeInteger(value); ok {
+		return toDouble(integer)
+	}
+	switch value := value.(type) {
+	case *big.Float:
+		return bigFloatToFloat(value)
+	case *big.Int:
+		integer, err := bigIntToInteger(value)
+		if err != nil {
+			return nil, err
+		}
+		return toDouble(integer)
+	}
+	return nil, unexpectedValueError(value, TypeDouble)
+}
+
+// toNativeDecimal converts the number to decimal.Decimal.
+func toNativeDecimal(value any) (any, error) {
+	switch value := value.(type) {
+	case decimal.Decimal:
+		return value, nil
+	case dec.Decimal:
+		return decimal.MakeDecimal(value), nil
+	case *big.Int:
+		return decimal.MakeDecimalFromString(value.String())
+	case *big.Float:
+		if value.IsInf() {
+			return nil, fmt.Errorf("%s can't be converted to decimal", value.Text('g', -1))
+		}
+		return decimal.MakeDecimalFromString(value.Text('g', -1))
+	}
+	if integer, ok := nativeInteger(value); ok {
+		return decimal.MakeDecimalFromString(fmt.Sprint(integer))
+	}
+	if float, bitSize, ok := nativeFloat(value); ok {
+		return decimal.MakeDecimalFromString(strconv.FormatFloat(float, 'g', -1, bitSize))
+	}
+	return nil, unexpectedValueError(value, TypeDecimal)
+}
+
+// toNativeNumber converts integers to uint64 or int64, floating-point numbers to
+// float64, decimals to decimal.Decimal. Big numbers are converted to integers or
+// float64, if it is possible without precision loss, or to decimal.Decimal.
+func toNativeNumber(value any) (any, error) {
+	switch value := value.(type) {
+	case *big.Int:
+		if integer, err := bigIntToInteger(value); err == nil {
+			return integer, nil
+		}
+		return toNativeDecimal(value)
+	case *big.Float:
+		if float, err := bigFloatToFloat(value); err == nil {
+			return float, nil
+		}
+		return toNativeDecimal(value)
+	case decimal.Decimal, dec.Decimal:
+		return toNativeDecimal(value)
+	}
+	if integer, ok := nativeInteger(value); ok {
+		return integer, nil
+	}
+	if float, _, ok := nativeFloat(value); ok {
+		return float, nil
+	}
+	return nil, unexpectedValueError(value, TypeNumber)
+}
+
+// marshalText converts the value, that implements encoding.TextMarshaler by
+// the value or by the pointer, to string. ok is false, if it doesn't implement it.
+func marshalText(value any) (result string, ok bool, err error) {
+	marshaler, ok := value.(encoding.TextMarshaler)
+	if !ok {
+		typ := reflect.TypeOf(value)
+		if !reflect.PtrTo(typ).Implements(textMarshalerType) {
+			return "", false, nil
+		}
+		ptr := reflect.New(typ)
+		ptr.Elem().Set(reflect.ValueOf(value))
+		marshaler = ptr.Interface().(encoding.TextMarshaler)
+	}
+	text, err := marshaler.MarshalText()
+	return string(text), true, err
+}
+
+// durationToInterval converts the duration to the interval in seconds and
+// nanoseconds.
+func durationToInterval(duration time.Duration) datetime.Interval {
+	return datetime.Interval{
+		Sec:  int64(duration / time.Second),
+		Nsec: int64(duration % time.Second),
+	}
+}
+
+func (NativeToTTConvFactory) GetBooleanConverter() Converter[any, any] {
+	return makeNativeConverter(func(value any) (any, error) {
+		if rv := reflect.ValueOf(value); rv.Kind() == reflect.Bool {
+			return rv.Bool(), nil
+		}
+		return nil, unexpectedValueError(value, TypeBoolean)
+	})
+}
+
+// GetStringConverter returns a converter from values of string kinds and types,
+// that implement encoding.TextMarshaler, to string.
+func (NativeToTTConvFactory) GetStringConverter() Converter[any, any] {
+	return makeNativeConverter(func(value any) (any, error) {
+		if rv := reflect.ValueOf(value); rv.Kind() == reflect.String {
+			return rv.String(), nil
+		}
+		if text, ok, err := marshalText(value); ok {
+			return text, err
+		}
+		return nil, unexpectedValueError(value, TypeString)
+	})
+}
+
+func (NativeToTTConvFactory) GetUnsignedConverter() Converter[any, any] {
+	return makeNativeConverter(func(value any) (any, error) {
+		result, err := toNativeInteger(value, TypeUnsigned)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := result.(int64); ok {
+			return nil, errNegativeUnsigned
+		}
+		return result, nil
+	})
+}
+
+func (NativeToTTConvFactory) GetDatetimeConverter() Converter[any, any] {
+	return makeNativeConverter(func(value any) (any, error) {
+		switch value := value.(type) {
+		case time.Time:
+			return makeDatetime(value)
+		case datetime.Datetime:
+			return value, nil
+		}
+		return nil, unexpectedValueError(value, TypeDatetime)
+	})
+}
+
+func (NativeToTTConvFactory) GetUUIDConverter() Converter[any, any] {
+	return makeNativeConverter(func(value any) (any, error) {
+		rv := reflect.ValueOf(value)
+		if rv.Kind() == reflect.Array && rv.Type().ConvertibleTo(uuidType) {
+			return rv.Convert(uuidType).Interface(), nil
+		}
+		return nil, unexpectedValueError(value, TypeUUID)
+	})
+}
+
+func (fac NativeToTTConvFactory) GetMapConverter() Converter[any, any] {
+	return makeNativeConverter(func(value any) (any, error) {
+		if reflect.ValueOf(value).Kind() == reflect.Map {
+			return fac.convertDynamic(value)
+		}
+		return nil, unexpectedValueError(value, TypeMap)
+	})
+}
+
+func (fac NativeToTTConvFactory) GetArrayConverter() Converter[any, any] {
+	return makeNativeConverter(func(value any) (any, error) {
+		switch reflect.ValueOf(value).Kind() {
+		case reflect.Slice, reflect.Array:
+			return fac.convertList(reflect.ValueOf(value))
+		}
+		return nil, unexpectedValueError(value, TypeArray)
+	})
+}
+
+func (NativeToTTConvFactory) GetVarbinaryConverter() Converter[any, any] {
+	return makeNativeConverter(func(value any) (any, error) {
+		rv := reflect.ValueOf(value)
+		if rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() == reflect.Uint8 {
+			return rv.Bytes(), nil
+		}
+		return nil, unexpectedValueError(value, TypeVarbinary)
+	})
+}
+
+func (NativeToTTConvFactory) GetDoubleConverter() Converter[any, any] {
+	return makeNativeConverter(toNativeDouble)
+}
+
+func (NativeToTTConvFactory) GetDecimalConverter() Converter[any, any] {
+	return makeNativeConverter(toNativeDecimal)
+}
+
+func (NativeToTTConvFactory) GetIntegerConverter() Converter[any, any] {
+	return makeNativeConverter(func(value any) (any, error) {
+		return toNativeInteger(value, TypeInteger)
+	})
+}
+
+func (NativeToTTConvFactory) GetNumberConverter() Converter[any, any] {
+	return makeNativeConverter(toNativeNumber)
+}
+
+// GetAnyConverter returns a converter to any. Null values and nil pointers are
+// converted to nil, because any fields accept null values.
+func (fac NativeToTTConvFactory) GetAnyConverter() Converter[any, any] {
+	return MakeFuncConverter(func(value any) (any, error) {
+		value, ok := derefNative(value)
+		if !ok {
+			return nil, nil
+		}
+		return fac.convertDynamic(value)
+	})
+}
+
+func (fac NativeToTTConvFactory) GetScalarConverter() Converter[any, any] {
+	return makeNativeConverter(func(value any) (any, error) {
+		switch reflect.ValueOf(value).Kind() {
+		case reflect.Map:
+			return nil, unexpectedValueError(value, TypeScalar)
+		case reflect.Slice, reflect.Array:
+			if _, ok := value.([]byte); !ok && reflect.TypeOf(value) != uuidType {
+				return nil, unexpectedValueError(value, TypeScalar)
+			}
+		}
+		return fac.convertDynamic(value)
+	})
+}
+
+func (NativeToTTConvFactory) GetIntervalConverter() Converter[any, any] {
+	return makeNativeConverter(func(value any) (any, error) {
+		switch value := value.(type) {
+		case time.Duration:
+			return durationToInterval(value), nil
+		case datetime.Interval:
+			return value, nil
+		}
+		return nil, unexpectedValueError(value, TypeInterval)
+	})
+}
+
+// convertDynamic converts the value by its dynamic type.
+func (fac NativeToTTConvFactory) convertDynamic(value any) (any, error) {
+	value, ok := derefNative(value)
+	if !ok {
+		return nil, nil
+	}
+	switch value := value.(type) {
+	case time.Time:
+		return makeDatetime(value)
+	case time.Duration:
+		return durationToInterval(value), nil
+	case datetime.Datetime, datetime.Interval, decimal.Decimal, uuid.UUID, []byte:
+		return value, nil
+	case dec.Decimal, *big.Int, *big.Float:
+		return toNativeNumber(value)
+	}
+	if text, ok, err := marshalText(value); ok {
+		return text, err
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Bool:
+		return rv.Bool(), nil
+	case reflect.String:
+		return rv.String(), nil
+	case reflect.Slice, reflect.Array:
+		return fac.convertList(rv)
+	case reflect.Map:
+		return fac.convertMap(rv)
+	}
+	if result, err := toNativeNumber(value); err == nil {
+		return result, nil
+	}
+	return nil, unexpectedValueError(value, TypeAny)
+}
+
+// convertList converts the slice or the array to []any. Items are converted by
+// their dynamic types.
+func (fac NativeToTTConvFactory) convertList(rv reflect.Value) (any, error) {
+	result := make([]any, rv.Len())
+	for i := range result {
+		var err error
+		if result[i], err = fac.convertDynamic(rv.Index(i).Interface()); err != nil {
+			return nil, fmt.Errorf("item #%d: %w", i, err)
+		}
+	}
+	return result, nil
+}
+
+// convertMap converts the map to map[string]any, if its keys are strings, or to
+// map[any]any. Keys and values are converted by their dynamic types.
+func (fac NativeToTTConvFactory) convertMap(rv reflect.Value) (any, error) {
+	if rv.Type().Key().Kind() == reflect.String {
+		result := make(map[string]any, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			item, err := fac.convertDynamic(iter.Value().Interface())
+			if err != nil {
+				return nil, fmt.Errorf("item %q: %w", iter.Key().String(), err)
+			}
+			result[iter.Key().String()] = item
+		}
+		return result, nil
+	}
+	result := make(map[any]any, rv.Len())
+	iter := rv.MapRange()
+	for iter.Next() {
+		key, err := fac.convertDynamic(iter.Key().Interface())
+		if err != nil {
+			return nil, fmt.Errorf("key %v: %w", iter.Key(), err)
+		}
+		if key != nil && !reflect.TypeOf(key).Comparable() {
+			return nil, fmt.Errorf("key %v: unexpected key of type %T", iter.Key(), key)
+		}
+		if result[key], err = fac.convertDynamic(iter.Value().Interface()); err != nil {
+			return nil, fmt.Errorf("item %v: %w", iter.Key(), err)
+		}
+	}
+	return result, nil
+}
+
+// MakeNullableConverter extends the converter to a converter, that converts nil
+// and nil pointers to nil.
+func (NativeToTTConvFactory) MakeNullableConverter(
+	converter Converter[any, any]) Converter[any, any] {
+	return MakeFuncContextConverter(func(ctx context.Context, value any) (any, error) {
+		if _, ok := derefNative(value); !ok {
+			return nil, nil
+		}
+		return convertContext(ctx, converter, value)
+	})
+}
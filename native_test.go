@@ -0,0 +1,290 @@
+package tupleconv_test
+
+import (
+	"math"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	dec "github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tarantool/go-tarantool/v2/datetime"
+	"github.com/tarantool/go-tarantool/v2/decimal"
+
+	"github.com/tarantool/go-tupleconv"
+)
+
+type nativeStatus int8
+
+type nativeLevel int
+
+func (level nativeLevel) MarshalText() ([]byte, error) {
+	return []byte([]string{"low", "high"}[level]), nil
+}
+
+type nativeCode struct {
+	code string
+}
+
+func (code *nativeCode) MarshalText() ([]byte, error) {
+	return []byte("code-" + code.code), nil
+}
+
+func mustDecimal(t *testing.T, src string) decimal.Decimal {
+	result, err := decimal.MakeDecimalFromString(src)
+	require.NoError(t, err)
+	return result
+}
+
+func TestNativeToTTConvFactory(t *testing.T) {
+	fac := tupleconv.MakeNativeToTTConvFactory()
+	tm := time.Date(2023, 8, 30, 12, 6, 5, 0, time.UTC)
+	dt, err := datetime.MakeDatetime(tm)
+	require.NoError(t, err)
+	id := uuid.MustParse("09b56913-11f0-4fa4-b5d0-901b5efa532a")
+	bigInt, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	answer := 42
+	var nilInt *int
+
+	tests := []struct {
+		typ   tupleconv.TypeName
+		cases []convCase[any, any]
+	}{
+		{
+			typ: tupleconv.TypeBoolean,
+			cases: []convCase[any, any]{
+				{value: true, expected: true},
+				{value: 1, isErr: true},
+			},
+		},
+		{
+			typ: tupleconv.TypeString,
+			cases: []convCase[any, any]{
+				{value: "a", expected: "a"},
+				{value: net.IPv4(127, 0, 0, 1), expected: "127.0.0.1"},
+				{value: nativeLevel(1), expected: "high"},
+				{value: nativeCode{code: "x"}, expected: "code-x"},
+				{value: 1, isErr: true},
+			},
+		},
+		{
+			typ: tupleconv.TypeUnsigned,
+			cases: []convCase[any, any]{
+				{value: uint8(255), expected: uint64(255)},
+				{value: int16(7), expected: uint64(7)},
+				{value: nativeStatus(3), expected: uint64(3)},
+				{value: uint64(math.MaxUint64), expected: uint64(math.MaxUint64)},
+				{value: &answer, expected: uint64(42)},
+				{value: float32(2), expected: uint64(2)},
+				{value: big.NewInt(5), expected: uint64(5)},
+				{value: int8(-1), isErr: true},
+				{value: 1.5, isErr: true},
+				{value: bigInt, isErr: true},
+				{value: nilInt, isErr: true},
+				{value: nil, isErr: true},
+				{value: "1", isErr: true},
+			},
+		},
+		{
+			typ: tupleconv.TypeInteger,
+			cases: []convCase[any, any]{
+				{value: int32(-5), expected: int64(-5)},
+				{value: uint32(5), expected: uint64(5)},
+				{value: int64(math.MinInt64), expected: int64(math.MinInt64)},
+				{value: big.NewFloat(-3), expected: int64(-3)},
+				{value: big.NewFloat(0.5), isErr: true},
+				{value: float64(1 << 60), isErr: true}, // Precision loss.
+			},
+		},
+		{
+			typ: tupleconv.TypeDouble,
+			cases: []convCase[any, any]{
+				{value: float32(0.5), expected: 0.5},
+				{value: 2.5, expected: 2.5},
+				{value: 3, expected: float64(3)},
+				{value: big.NewFloat(1.25), expected: 1.25},
+				{value: uint64(1<<53 + 1), isErr: true},
+				{value: new(big.Float).SetPrec(200).Quo(big.NewFloat(1), big.NewFloat(3)),
+					isErr: true},
+			},
+		},
+		{
+			typ: tupleconv.TypeDecimal,
+			cases: []convCase[any, any]{
+				{value: bigInt, expected: mustDecimal(t, "123456789012345678901234567890")},
+				{value: big.NewFloat(1.5), expected: mustDecimal(t, "1.5")},
+				{value: float32(0.1), expected: mustDecimal(t, "0.1")},
+				{value: -7, expected: mustDecimal(t, "-7")},
+				{value: dec.RequireFromString("2.25"), expected: mustDecimal(t, "2.25")},
+				{value: mustDecimal(t, "3"), expected: mustDecimal(t, "3")},
+				{value: "1", isErr: true},
+			},
+		},
+		{
+			typ: tupleconv.TypeNumber,
+			cases: []convCase[any, any]{
+				{value: int8(-1), expected: int64(-1)},
+				{value: float32(1.5), expected: 1.5},
+				{value: big.NewInt(-1), expected: int64(-1)},
+				{value: bigInt, expected: mustDecimal(t, "123456789012345678901234567890")},
+				{value: true, isErr: true},
+			},
+		},
+		{
+			typ: tupleconv.TypeDatetime,
+			cases: []convCase[any, any]{
+				{value: tm, expected: dt},
+				{value: &tm, expected: dt},
+				{value: dt, expected: dt},
+				{value: "2023", isErr: true},
+			},
+		},
+		{
+			typ: tupleconv.TypeInterval,
+			cases: []convCase[any, any]{
+				{
+					value:    90*time.Minute + 5*time.Nanosecond,
+					expected: datetime.Interval{Sec: 5400, Nsec: 5},
+				},
+				{value: -time.Second, expected: datetime.Interval{Sec: -1}},
+				{value: datetime.Interval{Day: 1}, expected: datetime.Interval{Day: 1}},
+				{value: int64(1), isErr: true},
+			},
+		},
+		{
+			typ: tupleconv.TypeUUID,
+			cases: []convCase[any, any]{
+				{value: id, expected: id},
+				{value: [16]byte(id), expected: id},
+				{value: id.String(), isErr: true},
+				{value: id[:], isErr: true},
+			},
+		},
+		{
+			typ: tupleconv.TypeVarbinary,
+			cases: []convCase[any, any]{
+				{value: []byte{1, 2}, expected: []byte{1, 2}},
+				{value: net.IP{1, 2, 3, 4}, expected: []byte{1, 2, 3, 4}},
+				{value: "a", isErr: true},
+			},
+		},
+		{
+			typ: tupleconv.TypeArray,
+			cases: []convCase[any, any]{
+				{
+					value:    []any{int8(1), &tm, nil, []string{"a"}},
+					expected: []any{uint64(1), dt, nil, []any{"a"}},
+				},
+				{value: [2]float32{1, 2}, expected: []any{float64(1), float64(2)}},
+				{value: []any{struct{}{}}, isErr: true},
+				{value: 1, isErr: true},
+			},
+		},
+		{
+			typ: tupleconv.TypeMap,
+			cases: []convCase[any, any]{
+				{
+					value:    map[string]time.Duration{"a": time.Second},
+					expected: map[string]any{"a": datetime.Interval{Sec: 1}},
+				},
+				{
+					value:    map[int]nativeLevel{-1: 0},
+					expected: map[any]any{int64(-1): "low"},
+				},
+				{value: map[[1]int]int{{1}: 1}, isErr: true},
+				{value: []any{}, isErr: true},
+			},
+		},
+		{
+			typ: tupleconv.TypeScalar,
+			cases: []convCase[any, any]{
+				{value: nativeStatus(-2), expected: int64(-2)},
+				{value: "a", expected: "a"},
+				{value: id, expected: id},
+				{value: []byte("a"), expected: []byte("a")},
+				{value: nativeLevel(0), expected: "low"},
+				{value: []int{1}, isErr: true},
+				{value: map[string]int{}, isErr: true},
+			},
+		},
+		{
+			typ: tupleconv.TypeAny,
+			cases: []convCase[any, any]{
+				{value: tm, expected: dt},
+				{value: []uint16{1}, expected: []any{uint64(1)}},
+				{value: nil, expected: nil},
+				{value: nilInt, expected: nil},
+				{value: struct{}{}, isErr: true},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(string(test.typ), func(t *testing.T) {
+			conv, err := tupleconv.GetConverterByType[any](fac, test.typ)
+			require.NoError(t, err)
+			HelperTestConverter(t, conv, test.cases)
+		})
+	}
+}
+
+func TestNativeToTTConvFactory_mapper(t *testing.T) {
+	type user struct {
+		ID       uint32
+		Name     string
+		Balance  *big.Int
+		Created  time.Time
+		Timeout  time.Duration
+		Comment  *string
+		Verified *bool
+	}
+	spaceFmt := []tupleconv.SpaceField{
+		{Name: "id", Type: tupleconv.TypeUnsigned},
+		{Name: "name", Type: tupleconv.TypeString},
+		{Name: "balance", Type: tupleconv.TypeDecimal},
+		{Name: "created", Type: tupleconv.TypeDatetime},
+		{Name: "timeout", Type: tupleconv.TypeInterval},
+		{Name: "comment", Type: tupleconv.TypeString, IsNullable: true},
+		{Name: "verified", Type: tupleconv.TypeBoolean},
+	}
+	converters, err := tupleconv.MakeTypeToTTConverters[any](
+		tupleconv.MakeNativeToTTConvFactory(), spaceFmt)
+	require.NoError(t, err)
+	mapper := tupleconv.MakeMapper(converters)
+
+	comment, verified := "vip", true
+	value := user{
+		ID:       1,
+		Name:     "Alice",
+		Balance:  big.NewInt(100),
+		Created:  time.Date(2023, 8, 30, 12, 6, 5, 0, time.Local),
+		Timeout:  time.Minute,
+		Comment:  &comment,
+		Verified: &verified,
+	}
+	toTuple := func(u user) []any {
+		return []any{u.ID, u.Name, u.Balance, u.Created, u.Timeout, u.Comment, u.Verified}
+	}
+
+	tuple, err := mapper.Map(toTuple(value))
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), tuple[0])
+	assert.Equal(t, "Alice", tuple[1])
+	assert.Equal(t, mustDecimal(t, "100"), tuple[2])
+	assert.IsType(t, datetime.Datetime{}, tuple[3])
+	assert.Equal(t, datetime.Interval{Sec: 60}, tuple[4])
+	assert.Equal(t, "vip", tuple[5])
+	assert.Equal(t, true, tuple[6])
+
+	value.Comment = nil
+	tuple, err = mapper.Map(toTuple(value))
+	require.NoError(t, err)
+	assert.Nil(t, tuple[5])
+
+	value.Verified = nil
+	_, err = mapper.Map(toTuple(value))
+	assert.EqualError(t, err, `unexpected value <nil> for type "boolean"`)
+}
@@ -0,0 +1,547 @@
+package tupleconv
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// jsonPathToken is a token of the JSON path: the map key, the one-based array
+// index or any array element for multikey `[*]` tokens.
+type jsonPathToken struct {
+	key   string
+	index int
+	any   bool
+}
+
+// String returns the canonical form of the token.
+func (token jsonPathToken) String() string {
+	switch {
+	case token.any:
+		return "[*]"
+	case token.index != 0:
+		return "[" + strconv.Itoa(token.index) + "]"
+	}
+	return "." + token.key
+}
+
+// formatJSONPath returns the canonical form of the JSON path: `.key`, `[index]`
+// and `[*]` tokens without the leading dot.
+func formatJSONPath(tokens []jsonPathToken) string {
+	var builder strings.Builder
+	for _, token := range tokens {
+		builder.WriteString(token.String())
+	}
+	return strings.TrimPrefix(builder.String(), ".")
+}
+
+// parseJSONPath parses the JSON path of tarantool: `.key`, `["key"]`, `['key']`,
+// `[index]` and `[*]` tokens. The leading dot may be omitted.
+func parseJSONPath(path string) ([]jsonPathToken, error) {
+	var tokens []jsonPathToken
+	for rest := path; rest != ""; {
+		switch {
+		case strings.HasPrefix(rest, "[*]"):
+			tokens = append(tokens, jsonPathToken{any: true})
+			rest = rest[3:]
+		case strings.HasPrefix(rest, `["`), strings.HasPrefix(rest, "['"):
+			end := strings.Index(rest[2:], rest[1:2]+"]")
+			if end < 0 {
+				return nil, fmt.Errorf("path %q: unterminated key", path)
+			}
+			tokens = append(tokens, jsonPathToken{key: rest[2 : 2+end]})
+			rest = rest[2+end+2:]
+		case rest[0] == '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("path %q: unterminated index", path)
+			}
+			index, err := strconv.Atoi(rest[1:end])
+			if err != nil || index < 1 {
+				return nil, fmt.Errorf("path %q: invalid index %q", path, rest[1:end])
+			}
+			tokens = append(tokens, jsonPathToken{index: index})
+			rest = rest[end+1:]
+		default:
+			if rest[0] == '.' {
+				rest = rest[1:]
+			} else if len(tokens) != 0 {
+				return nil, fmt.Errorf("path %q: unexpected %q", path, rest[0])
+			}
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			if end == 0 {
+				return nil, fmt.Errorf("path %q: empty key", path)
+			}
+			tokens = append(tokens, jsonPathToken{key: rest[:end]})
+			rest = rest[end:]
+		}
+	}
+	return tokens, nil
+}
+
+// isMultikeyPath checks if the JSON path contains `[*]` tokens.
+func isMultikeyPath(tokens []jsonPathToken) bool {
+	for _, token := range tokens {
+		if token.any {
+			return true
+		}
+	}
+	return false
+}
+
+// lookupJSONPath returns the value by the JSON path without `[*]` tokens. ok is
+// false, if the path does not exist in the value.
+func lookupJSONPath(value any, tokens []jsonPathToken) (result any, ok bool) {
+	for _, token := range tokens {
+		if value, ok = lookupJSONPathToken(value, token); !ok {
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// lookupJSONPathToken returns the item of the container by the token. ok is
+// false, if there is no such item.
+func lookupJSONPathToken(value any, token jsonPathToken) (result any, ok bool) {
+	switch container := value.(type) {
+	case []any:
+		if token.index < 1 || token.index > len(container) {
+			return nil, false
+		}
+		return container[token.index-1], true
+	case map[string]any:
+		if token.index != 0 || token.any {
+			return nil, false
+		}
+		result, ok = container[token.key]
+		return result, ok
+	case map[any]any:
+		if token.any {
+			return nil, false
+		}
+		if token.index == 0 {
+			result, ok = container[token.key]
+		} else if result, ok = container[uint64(token.index)]; !ok {
+			result, ok = container[int64(token.index)]
+		}
+		return result, ok
+	}
+	return nil, false
+}
+
+// setJSONPathToken returns the copy of the container with the item by the token.
+func setJSONPathToken(value any, token jsonPathToken, item any) any {
+	switch container := value.(type) {
+	case []any:
+		result := append([]any{}, container...)
+		result[token.index-1] = item
+		return result
+	case map[string]any:
+		result := make(map[string]any, len(container))
+		for key, value := range container {
+			result[key] = value
+		}
+		result[token.key] = item
+		return result
+	case map[any]any:
+		result := make(map[any]any, len(container))
+		for key, value := range container {
+			result[key] = value
+		}
+		if token.index == 0 {
+			result[token.key] = item
+		} else if _, ok := result[int64(token.index)]; ok {
+			result[int64(token.index)] = item
+		} else {
+			result[uint64(token.index)] = item
+		}
+		return result
+	}
+	return value
+}
+
+// subField is the parsed sub-field definition.
+type subField struct {
+	// field is the description of the sub-field for validation and errors.
+	field SpaceField
+	// path is the parsed JSON path.
+	path []jsonPathToken
+	// conv converts values of other types to the sub-field type, if it is set.
+	conv Converter[any, any]
+}
+
+// SubField is a nested field of a map, array or any field, addressed by
+// the JSON path in the field, for example `address.city` or `items[*].sku`,
+// where `[*]` addresses every element of the array.
+type SubField struct {
+	Path       string   `msgpack:"path"`
+	Type       TypeName `msgpack:"type"`
+	IsNullable bool     `msgpack:"is_nullable,omitempty"`
+}
+
+// NestedFormat defines sub-fields of map, array and any fields of the space
+// format by the field names.
+type NestedFormat map[string][]SubField
+
+// nestedFieldTypes are the types of fields, that may have sub-fields.
+var nestedFieldTypes = map[TypeName]bool{
+	TypeMap:   true,
+	TypeArray: true,
+	TypeAny:   true,
+}
+
+// parseNestedFormat parses the sub-fields of the nested format. The result
+// contains the sub-fields of each field of the space format.
+func parseNestedFormat(spaceFmt []SpaceField, nested NestedFormat) ([][]subField, error) {
+	result := make([][]subField, len(spaceFmt))
+	if len(nested) == 0 {
+		return result, nil
+	}
+	found := make(map[string]bool, len(nested))
+	for pos, field := range spaceFmt {
+		definitions, ok := nested[field.Name]
+		if !ok {
+			continue
+		}
+		found[field.Name] = true
+		var err error
+		if result[pos], err = parseSubFields(field, definitions); err != nil {
+			return nil, err
+		}
+	}
+	if len(found) != len(nested) {
+		unknown := make([]string, 0, len(nested)-len(found))
+		for name := range nested {
+			if !found[name] {
+				unknown = append(unknown, name)
+			}
+		}
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("sub-fields of unknown field %q", unknown[0])
+	}
+	return result, nil
+}
+
+// parseSubFields parses the sub-field definitions of the field.
+func parseSubFields(field SpaceField, definitions []SubField) ([]subField, error) {
+	if len(definitions) == 0 {
+		return nil, nil
+	}
+	if !nestedFieldTypes[field.Type] {
+		return nil, fmt.Errorf("field %q: %s fields can't have sub-fields",
+			field.Name, field.Type)
+	}
+	subFields := make([]subField, len(definitions))
+	for i, sub := range definitions {
+		path, err := parseJSONPath(sub.Path)
+		if err != nil {
+			return nil, fmt.Errorf("field %q: %w", field.Name, err)
+		}
+		if len(path) == 0 {
+			return nil, fmt.Errorf("field %q: sub-field path is empty", field.Name)
+		}
+		if !isKnownType(sub.Type) {
+			return nil, fmt.Errorf("field %q: sub-field %q: unexpected type: %s",
+				field.Name, sub.Path, sub.Type)
+		}
+		subFields[i] = subField{
+			field: SpaceField{Type: sub.Type, IsNullable: sub.IsNullable},
+			path:  path,
+		}
+	}
+	return subFields, nil
+}
+
+// setSubFieldConverters sets the converters of the sub-field values to
+// the sub-field types by the factory.
+func setSubFieldConverters[Type any](fac TTConvFactory[Type], subFields []subField) error {
+	var anyFac TTConvFactory[any]
+	switch configured := any(fac).(type) {
+	case TTConvFactory[any]:
+		anyFac = configured
+	case anyConvFactory:
+		anyFac = configured.anyFactory()
+	}
+	for i, sub := range subFields {
+		var err error
+		if subFields[i].conv, err = makeSubFieldConverter(fac, anyFac,
+			sub.field.Type); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateNestedValue checks if the value can be stored in the field with
+// the sub-fields.
+func validateNestedValue(field SpaceField, subFields []subField, value any) error {
+	if err := validateTTValue(field, value); err != nil {
+		return err
+	}
+	if value == nil || len(subFields) == 0 {
+		return nil
+	}
+	_, err := applySubFields(value, subFields)
+	return err
+}
+
+// makeSubFieldConverter makes the converter of the nested values to the type.
+// Values of the source type are converted by the factory, other values are
+// converted by the factory of decoded values, if it is set, or only validated.
+func makeSubFieldConverter[Type any](fac TTConvFactory[Type], anyFac TTConvFactory[any],
+	typ TypeName) (Converter[any, any], error) {
+	conv, err := GetConverterByType(fac, typ)
+	if err != nil {
+		return nil, err
+	}
+	var anyConv Converter[any, any]
+	if anyFac != nil {
+		if anyConv, err = GetConverterByType(anyFac, typ); err != nil {
+			return nil, err
+		}
+	}
+	return MakeFuncContextConverter(func(ctx context.Context, value any) (any, error) {
+		if src, ok := value.(Type); ok {
+			return convertContext(ctx, conv, src)
+		}
+		if anyConv != nil {
+			return convertContext(ctx, anyConv, value)
+		}
+		return value, validateTTValue(SpaceField{Type: typ}, value)
+	}), nil
+}
+
+// applySubFields checks the values of the sub-fields and converts them to
+// the sub-field types, if the converters are set. The containers on the paths
+// are copied, the value is not changed.
+func applySubFields(value any, subFields []subField) (any, error) {
+	for _, sub := range subFields {
+		var err error
+		if value, err = applySubField(value, sub, sub.path); err != nil {
+			return nil, err
+		}
+	}
+	return value, nil
+}
+
+// applySubField checks and converts the values of the sub-field by the rest
+// of its path.
+func applySubField(value any, sub subField, path []jsonPathToken) (any, error) {
+	if len(path) == 0 {
+		err := validateTTValue(sub.field, value)
+		if err != nil && value != nil && sub.conv != nil {
+			value, err = sub.conv.Convert(value)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("path %q: %w", formatJSONPath(sub.path), err)
+		}
+		return value, nil
+	}
+	token := path[0]
+	if token.any {
+		array, ok := value.([]any)
+		if !ok {
+			return applyMissingSubField(value, sub)
+		}
+		var result []any
+		for i, item := range array {
+			converted, err := applySubField(item, sub, path[1:])
+			if err != nil {
+				return nil, err
+			}
+			if sub.conv != nil {
+				if result == nil {
+					result = append([]any{}, array...)
+				}
+				result[i] = converted
+			}
+		}
+		if result == nil {
+			return value, nil
+		}
+		return result, nil
+	}
+	item, ok := lookupJSONPathToken(value, token)
+	if !ok {
+		return applyMissingSubField(value, sub)
+	}
+	converted, err := applySubField(item, sub, path[1:])
+	if err != nil {
+		return nil, err
+	}
+	if sub.conv == nil {
+		return value, nil
+	}
+	return setJSONPathToken(value, token, converted), nil
+}
+
+// applyMissingSubField checks, that the missing sub-field is nullable.
+func applyMissingSubField(value any, sub subField) (any, error) {
+	if !sub.field.IsNullable {
+		return nil, fmt.Errorf("path %q is missing", formatJSONPath(sub.path))
+	}
+	return value, nil
+}
+
+// columnTarget is the target of the source column: the field and the path of
+// the sub-field in it.
+type columnTarget struct {
+	pos  int
+	path []jsonPathToken
+}
+
+// isPrefixOf checks if the target is the prefix of the other target.
+func (target columnTarget) isPrefixOf(other columnTarget) bool {
+	if target.pos != other.pos || len(target.path) > len(other.path) {
+		return false
+	}
+	for i, token := range target.path {
+		if token != other.path[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// findSubField returns the sub-field by the path.
+func findSubField(subFields []subField, path []jsonPathToken) (subField, bool) {
+	canonical := formatJSONPath(path)
+	for _, sub := range subFields {
+		if formatJSONPath(sub.path) == canonical {
+			return sub, true
+		}
+	}
+	return subField{}, false
+}
+
+// MakeTypeToTTColumnsMapper creates a mapper from Type to tarantool types by
+// the factory, the space format and the sub-fields of its fields, where
+// the source columns target fields by their names or sub-fields by their JSON
+// paths, for example `id` and `data.address.city`. Paths of the columns may
+// contain only map keys. Each sub-field must be defined in the nested format,
+// it is converted by its type.
+//
+// Fields, targeted by sub-field columns, are maps, built from non-null values
+// of the columns. Values of fields with sub-fields, targeted by sub-field or
+// whole field columns, are validated by their sub-field definitions. Converted
+// tuples have all fields of the space format, fields, that are not targeted
+// by columns, are null, so they must be nullable or of the any type.
+func MakeTypeToTTColumnsMapper[Type any](fac TTConvFactory[Type], spaceFmt []SpaceField,
+	nested NestedFormat, columns []string) (Mapper[Type, any], error) {
+	names, err := MakeFieldNames(spaceFmt)
+	if err != nil {
+		return Mapper[Type, any]{}, err
+	}
+	subFields, err := parseNestedFormat(spaceFmt, nested)
+	if err != nil {
+		return Mapper[Type, any]{}, err
+	}
+	targets := make([]columnTarget, len(columns))
+	converters := make([]Converter[Type, any], len(columns))
+	isTargeted := make([]bool, len(spaceFmt))
+	for i, column := range columns {
+		path, err := parseJSONPath(column)
+		if err != nil {
+			return Mapper[Type, any]{}, fmt.Errorf("column %q: %w", column, err)
+		}
+		if len(path) == 0 || path[0].key == "" {
+			return Mapper[Type, any]{}, fmt.Errorf("column %q: field name is expected",
+				column)
+		}
+		pos, ok := names.Position(path[0].key)
+		if !ok {
+			return Mapper[Type, any]{}, fmt.Errorf("column %q: unknown field %q",
+				column, path[0].key)
+		}
+		target := spaceFmt[pos]
+		targetNested := NestedFormat{}
+		if len(path) == 1 && len(subFields[pos]) != 0 {
+			targetNested[target.Name] = nested[target.Name]
+		}
+		if len(path) > 1 {
+			for _, token := range path[1:] {
+				if token.key == "" {
+					return Mapper[Type, any]{}, fmt.Errorf(
+						"column %q: only map keys are supported in column paths", column)
+				}
+			}
+			sub, ok := findSubField(subFields[pos], path[1:])
+			if !ok {
+				return Mapper[Type, any]{}, fmt.Errorf(
+					"column %q: sub-field %q is not defined in the nested format",
+					column, formatJSONPath(path[1:]))
+			}
+			target = SpaceField{Name: column, Type: sub.field.Type,
+				IsNullable: sub.field.IsNullable}
+		}
+		targets[i] = columnTarget{pos: pos, path: path[1:]}
+		for j, prev := range targets[:i] {
+			if prev.isPrefixOf(targets[i]) || targets[i].isPrefixOf(prev) {
+				return Mapper[Type, any]{}, fmt.Errorf("column %q conflicts with column %q",
+					column, columns[j])
+			}
+		}
+		fieldConverters, err := MakeNestedTypeToTTConverters(fac, []SpaceField{target},
+			targetNested)
+		if err != nil {
+			return Mapper[Type, any]{}, fmt.Errorf("column %q: %w", column, err)
+		}
+		converters[i] = fieldConverters[0]
+		isTargeted[pos] = true
+	}
+	for pos, field := range spaceFmt {
+		if !isTargeted[pos] && !field.IsNullable && field.Type != TypeAny {
+			return Mapper[Type, any]{}, fmt.Errorf(
+				"non-nullable field %q is not targeted by any column", field.Name)
+		}
+	}
+	return MakeMapper(converters).WithPostTransform(MakeFuncConverter(
+		func(tuple []any) ([]any, error) {
+			result := make([]any, len(spaceFmt))
+			isNested := make([]bool, len(spaceFmt))
+			for i, value := range tuple {
+				target := targets[i]
+				if len(target.path) == 0 {
+					result[target.pos] = value
+					continue
+				}
+				if !isNested[target.pos] {
+					isNested[target.pos] = true
+					result[target.pos] = map[string]any{}
+				}
+				if value != nil {
+					setNestedValue(result[target.pos].(map[string]any), target.path, value)
+				}
+			}
+			for pos, nested := range isNested {
+				if !nested {
+					continue
+				}
+				err := validateNestedValue(spaceFmt[pos], subFields[pos], result[pos])
+				if err != nil {
+					return nil, fmt.Errorf("field #%d (%q): %w", pos+1,
+						spaceFmt[pos].Name, err)
+				}
+			}
+			return result, nil
+		})), nil
+}
+
+// setNestedValue sets the value by the path of map keys, creating the maps on
+// the path.
+func setNestedValue(container map[string]any, path []jsonPathToken, value any) {
+	for _, token := range path[:len(path)-1] {
+		next, ok := container[token.key].(map[string]any)
+		if !ok {
+			next = map[string]any{}
+			container[token.key] = next
+		}
+		container = next
+	}
+	container[path[len(path)-1].key] = value
+}
@@ -0,0 +1,275 @@
+package tupleconv_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tarantool/go-tupleconv"
+)
+
+// nestedSpaceFmt is the format of a space with nested fields.
+var nestedSpaceFmt = []tupleconv.SpaceField{
+	{Name: "id", Type: tupleconv.TypeUnsigned},
+	{Name: "data", Type: tupleconv.TypeMap},
+	{Name: "items", Type: tupleconv.TypeArray, IsNullable: true},
+}
+
+// nestedFormat is the nested format of nestedSpaceFmt.
+var nestedFormat = tupleconv.NestedFormat{
+	"data": {
+		{Path: "address.city", Type: tupleconv.TypeString},
+		{Path: "address.zip", Type: tupleconv.TypeString, IsNullable: true},
+		{Path: "age", Type: tupleconv.TypeUnsigned, IsNullable: true},
+		{Path: `["created"]`, Type: tupleconv.TypeDatetime, IsNullable: true},
+	},
+	"items": {
+		{Path: "[*].sku", Type: tupleconv.TypeString},
+		{Path: "[*].qty", Type: tupleconv.TypeUnsigned},
+	},
+}
+
+func TestMakeNestedTypeToTTConverters(t *testing.T) {
+	fac := tupleconv.MakeStringToTTConvFactory()
+	converters, err := tupleconv.MakeNestedTypeToTTConverters[string](fac, nestedSpaceFmt,
+		nestedFormat)
+	require.NoError(t, err)
+	mapper := tupleconv.MakeMapper(converters)
+
+	tuple, err := mapper.Map([]string{
+		"1",
+		`{"address": {"city": "Berlin"}, "age": 42, "created": "2023-08-30T15:06:05+0300"}`,
+		`[{"sku": "a-1", "qty": 2, "note": 1.5}, {"sku": "b-2", "qty": 1}]`,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []any{
+		uint64(1),
+		map[string]any{
+			"address": map[string]any{"city": "Berlin"},
+			"age":     uint64(42),
+			"created": mustDatetime(t, time.Date(2023, 8, 30, 15, 6, 5, 0,
+				time.FixedZone("", 3*60*60))),
+		},
+		[]any{
+			map[string]any{"sku": "a-1", "qty": uint64(2), "note": 1.5},
+			map[string]any{"sku": "b-2", "qty": uint64(1)},
+		},
+	}, tuple)
+
+	cases := []struct {
+		name  string
+		tuple []string
+		err   string
+	}{
+		{"missing", []string{"1", `{"address": {}}`},
+			`unexpected value {"address": {}}: path "address.city" is missing`},
+		{"missing parent", []string{"1", `{}`},
+			`unexpected value {}: path "address.city" is missing`},
+		{"wrong type", []string{"1", `{"address": {"city": 1}}`},
+			`unexpected value {"address": {"city": 1}}: path "address.city": ` +
+				"unexpected value 1 of type float64 for string"},
+		{"negative", []string{"1", `{"address": {"city": "A"}, "age": -1}`},
+			`unexpected value {"address": {"city": "A"}, "age": -1}: path "age": ` +
+				"negative value for unsigned"},
+		{"null", []string{"1", `{"address": {"city": null}}`},
+			`unexpected value {"address": {"city": null}}: path "address.city": ` +
+				"unexpected null value for non-nullable field"},
+		{"multikey", []string{"1", `{"address": {"city": "A"}}`, `[{"sku": "a"}]`},
+			`unexpected value [{"sku": "a"}]: path "[*].qty" is missing`},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := mapper.Map(tc.tuple)
+			assert.EqualError(t, err, tc.err)
+		})
+	}
+}
+
+// fixedDoubleFactory converts all strings of double fields to 0.5.
+type fixedDoubleFactory struct {
+	tupleconv.StringToTTConvFactory
+}
+
+func (fixedDoubleFactory) GetDoubleConverter() tupleconv.Converter[string, any] {
+	return tupleconv.MakeFuncConverter(func(string) (any, error) {
+		return 0.5, nil
+	})
+}
+
+func TestMakeNestedTypeToTTConverters_factory(t *testing.T) {
+	spaceFmt := []tupleconv.SpaceField{{Name: "data", Type: tupleconv.TypeMap}}
+	nested := tupleconv.NestedFormat{"data": {
+		{Path: "price", Type: tupleconv.TypeDouble},
+		{Path: "count", Type: tupleconv.TypeDouble, IsNullable: true},
+	}}
+	strFac := tupleconv.MakeStringToTTConvFactory().WithDecimalSeparators(",")
+
+	converters, err := tupleconv.MakeNestedTypeToTTConverters[string](strFac, spaceFmt, nested)
+	require.NoError(t, err)
+	value, err := converters[0].Convert(`{"price": "1,5", "count": 2}`)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"price": 1.5, "count": 2.0}, value)
+
+	converters, err = tupleconv.MakeNestedTypeToTTConverters[string](
+		fixedDoubleFactory{strFac}, spaceFmt, nested)
+	require.NoError(t, err)
+	value, err = converters[0].Convert(`{"price": "1,5", "count": 2}`)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"price": 0.5, "count": 2.0}, value)
+
+	anyConverters, err := tupleconv.MakeNestedTypeToTTConverters[any](
+		tupleconv.MakeAnyToTTConvFactory(strFac), spaceFmt, nested)
+	require.NoError(t, err)
+	value, err = anyConverters[0].Convert(map[string]any{"price": "2,5"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"price": 2.5}, value)
+
+	bytesConverters, err := tupleconv.MakeNestedTypeToTTConverters[[]byte](
+		tupleconv.MakeBytesToTTConvFactory(strFac), spaceFmt, nested)
+	require.NoError(t, err)
+	value, err = bytesConverters[0].Convert([]byte(`{"price": "3,5"}`))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"price": 3.5}, value)
+}
+
+func TestMakeNestedTypeToTTConverters_invalid(t *testing.T) {
+	cases := []struct {
+		name  string
+		field tupleconv.SpaceField
+		sub   tupleconv.SubField
+		err   string
+	}{
+		{"scalar field", tupleconv.SpaceField{Name: "f", Type: tupleconv.TypeString},
+			tupleconv.SubField{Path: "a", Type: tupleconv.TypeString},
+			`field "f": string fields can't have sub-fields`},
+		{"invalid path", tupleconv.SpaceField{Name: "f", Type: tupleconv.TypeMap},
+			tupleconv.SubField{Path: "a[x]", Type: tupleconv.TypeString},
+			`field "f": path "a[x]": invalid index "x"`},
+		{"empty path", tupleconv.SpaceField{Name: "f", Type: tupleconv.TypeMap},
+			tupleconv.SubField{Type: tupleconv.TypeString},
+			`field "f": sub-field path is empty`},
+		{"unknown type", tupleconv.SpaceField{Name: "f", Type: tupleconv.TypeMap},
+			tupleconv.SubField{Path: "a", Type: "text"},
+			`field "f": sub-field "a": unexpected type: text`},
+		{"unknown field", tupleconv.SpaceField{Name: "g", Type: tupleconv.TypeMap},
+			tupleconv.SubField{Path: "a", Type: tupleconv.TypeString},
+			`sub-fields of unknown field "f"`},
+	}
+	fac := tupleconv.MakeStringToTTConvFactory()
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			spaceFmt := []tupleconv.SpaceField{tc.field}
+			nested := tupleconv.NestedFormat{"f": {tc.sub}}
+			_, err := tupleconv.MakeNestedTypeToTTConverters[string](fac, spaceFmt, nested)
+			assert.EqualError(t, err, tc.err)
+			_, err = tupleconv.MakeNestedFormatValidator(spaceFmt, nested)
+			assert.EqualError(t, err, tc.err)
+		})
+	}
+}
+
+func TestMakeFormatValidator_subFields(t *testing.T) {
+	validator, err := tupleconv.MakeNestedFormatValidator(nestedSpaceFmt, nestedFormat)
+	require.NoError(t, err)
+	data := map[string]any{"address": map[string]any{"city": "Berlin"}}
+	items := []any{map[string]any{"sku": "a", "qty": uint64(1)}}
+
+	_, err = validator.Convert([]any{uint64(1), data, items})
+	assert.NoError(t, err)
+	_, err = validator.Convert([]any{uint64(1), data, nil})
+	assert.NoError(t, err)
+
+	_, err = validator.Convert([]any{uint64(1), map[string]any{}})
+	assert.EqualError(t, err, `field #2 ("data"): path "address.city" is missing`)
+	items = []any{map[string]any{"sku": "a", "qty": int64(-1)}}
+	_, err = validator.Convert([]any{uint64(1), data, items})
+	assert.EqualError(t, err,
+		`field #3 ("items"): path "[*].qty": unexpected integer value for unsigned field`)
+}
+
+func TestMakeTypeToTTColumnsMapper(t *testing.T) {
+	fac := tupleconv.MakeStringToTTConvFactory()
+	mapper, err := tupleconv.MakeTypeToTTColumnsMapper[string](fac, nestedSpaceFmt, nestedFormat,
+		[]string{"id", "data.address.city", `data["address"].zip`, "data.age"})
+	require.NoError(t, err)
+
+	tuple, err := mapper.Map([]string{"1", "Berlin", "10115", "42"})
+	require.NoError(t, err)
+	assert.Equal(t, []any{
+		uint64(1),
+		map[string]any{
+			"address": map[string]any{"city": "Berlin", "zip": "10115"},
+			"age":     uint64(42),
+		},
+		nil,
+	}, tuple)
+
+	// Null values are omitted.
+	tuple, err = mapper.Map([]string{"2", "Paris", "", ""})
+	require.NoError(t, err)
+	assert.Equal(t, []any{
+		uint64(2),
+		map[string]any{"address": map[string]any{"city": "Paris"}},
+		nil,
+	}, tuple)
+
+	_, err = mapper.Map([]string{"3", "Rome", "", "old"})
+	assert.EqualError(t, err, `unexpected value old for type "unsigned"`)
+
+	// Sub-fields, that are not targeted by columns, are validated.
+	mapper, err = tupleconv.MakeTypeToTTColumnsMapper[string](fac, nestedSpaceFmt, nestedFormat,
+		[]string{"id", "data.age"})
+	require.NoError(t, err)
+	_, err = mapper.Map([]string{"3", "42"})
+	assert.EqualError(t, err, `field #2 ("data"): path "address.city" is missing`)
+
+	// Fields after the last targeted one are kept.
+	mapper, err = tupleconv.MakeTypeToTTColumnsMapper[string](fac, nestedSpaceFmt, nestedFormat,
+		[]string{"data", "id"})
+	require.NoError(t, err)
+	tuple, err = mapper.Map([]string{`{"address": {"city": "Oslo"}}`, "4"})
+	require.NoError(t, err)
+	assert.Equal(t, []any{
+		uint64(4),
+		map[string]any{"address": map[string]any{"city": "Oslo"}},
+		nil,
+	}, tuple)
+
+	// Whole fields are validated by their sub-fields.
+	_, err = mapper.Map([]string{`{"address": {"city": 5}}`, "5"})
+	assert.EqualError(t, err, `unexpected value {"address": {"city": 5}}: `+
+		`path "address.city": unexpected value 5 of type float64 for string`)
+}
+
+func TestMakeTypeToTTColumnsMapper_errors(t *testing.T) {
+	cases := []struct {
+		name    string
+		columns []string
+		err     string
+	}{
+		{"unknown field", []string{"id", "name"}, `column "name": unknown field "name"`},
+		{"no field name", []string{"[1]"}, `column "[1]": field name is expected`},
+		{"invalid path", []string{"data..age"}, `column "data..age": path "data..age": ` +
+			"empty key"},
+		{"undefined sub-field", []string{"data.address"},
+			`column "data.address": sub-field "address" is not defined in the nested format`},
+		{"array index", []string{"items[1].sku"},
+			`column "items[1].sku": only map keys are supported in column paths`},
+		{"duplicate", []string{"data.age", "data.age"},
+			`column "data.age" conflicts with column "data.age"`},
+		{"whole and nested", []string{"data", "data.age"},
+			`column "data.age" conflicts with column "data"`},
+		{"untargeted field", []string{"id"},
+			`non-nullable field "data" is not targeted by any column`},
+	}
+	fac := tupleconv.MakeStringToTTConvFactory()
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := tupleconv.MakeTypeToTTColumnsMapper[string](fac, nestedSpaceFmt, nestedFormat,
+				tc.columns)
+			assert.EqualError(t, err, tc.err)
+		})
+	}
+}
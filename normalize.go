@@ -0,0 +1,169 @@
+package tupleconv
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+)
+
+// NormalizationForm is a Unicode normalization form.
+type NormalizationForm string
+
+// Supported Unicode normalization forms.
+const (
+	// NormalizationNone disables Unicode normalization.
+	NormalizationNone NormalizationForm = ""
+	// NormalizationNFC is the canonical composition.
+	NormalizationNFC NormalizationForm = "NFC"
+	// NormalizationNFKC is the compatibility composition.
+	NormalizationNFKC NormalizationForm = "NFKC"
+)
+
+// unicodeCICollation is the name of the case-insensitive tarantool collation.
+const unicodeCICollation = "unicode_ci"
+
+// byteOrderMark is the Unicode byte order mark.
+const byteOrderMark = "\uFEFF"
+
+// NormalizeOptions are options of the string normalization.
+// Steps are applied in the following order: StripBOM, StripControl, Form,
+// CollapseSpaces, Trim, FoldCase.
+type NormalizeOptions struct {
+	// StripBOM removes the leading byte order mark.
+	StripBOM bool `json:"strip_bom,omitempty" yaml:"strip_bom,omitempty"`
+	// StripControl removes control characters, except white space ones.
+	StripControl bool `json:"strip_control,omitempty" yaml:"strip_control,omitempty"`
+	// Form is the Unicode normalization form.
+	Form NormalizationForm `json:"form,omitempty" yaml:"form,omitempty"`
+	// CollapseSpaces replaces each sequence of white space characters
+	// (including non-breaking spaces) with a single space.
+	CollapseSpaces bool `json:"collapse_spaces,omitempty" yaml:"collapse_spaces,omitempty"`
+	// Trim removes leading and trailing white space characters
+	// (including non-breaking spaces).
+	Trim bool `json:"trim,omitempty" yaml:"trim,omitempty"`
+	// FoldCase folds the case of the string.
+	// StringToTTConvFactory applies it only to string fields with
+	// `unicode_ci` collation.
+	FoldCase bool `json:"fold_case,omitempty" yaml:"fold_case,omitempty"`
+}
+
+// isEmpty checks if the options don't change anything.
+func (opts NormalizeOptions) isEmpty() bool {
+	return opts == NormalizeOptions{}
+}
+
+// StringNormalizer is a converter from string to normalized string.
+type StringNormalizer struct {
+	opts NormalizeOptions
+}
+
+// MakeStringNormalizer creates StringNormalizer.
+func MakeStringNormalizer(opts NormalizeOptions) StringNormalizer {
+	return StringNormalizer{opts: opts}
+}
+
+var _ Converter[string, string] = (*StringNormalizer)(nil)
+
+// Convert is the implementation of Converter[string, string] for StringNormalizer.
+// The source string is returned as is, if there is nothing to normalize.
+func (conv StringNormalizer) Convert(src string) (string, error) {
+	opts := conv.opts
+	if opts.StripBOM {
+		src = strings.TrimPrefix(src, byteOrderMark)
+	}
+	if opts.StripControl {
+		src = stripControl(src)
+	}
+	switch opts.Form {
+	case NormalizationNone:
+	case NormalizationNFC:
+		if !isASCII(src) {
+			src = norm.NFC.String(src)
+		}
+	case NormalizationNFKC:
+		if !isASCII(src) {
+			src = norm.NFKC.String(src)
+		}
+	default:
+		return "", fmt.Errorf("unexpected normalization form: %s", opts.Form)
+	}
+	if opts.CollapseSpaces {
+		src = collapseSpaces(src)
+	}
+	if opts.Trim {
+		src = strings.TrimSpace(src)
+	}
+	if opts.FoldCase {
+		src = cases.Fold().String(src)
+	}
+	return src, nil
+}
+
+// isASCII checks if the string contains only ASCII characters. Such strings are
+// already in any normalization form.
+func isASCII(src string) bool {
+	for i := 0; i < len(src); i++ {
+		if src[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+// isStrippedControl checks if the rune is removed by the StripControl option.
+func isStrippedControl(r rune) bool {
+	return unicode.IsControl(r) && !unicode.IsSpace(r)
+}
+
+// stripControl removes control characters, except white space ones.
+func stripControl(src string) string {
+	if strings.IndexFunc(src, isStrippedControl) < 0 {
+		return src
+	}
+	return strings.Map(func(r rune) rune {
+		if isStrippedControl(r) {
+			return -1
+		}
+		return r
+	}, src)
+}
+
+// needsCollapse checks if the string has a white space sequence, or a white space
+// character other than a space.
+func needsCollapse(src string) bool {
+	prevSpace := false
+	for _, r := range src {
+		isSpace := unicode.IsSpace(r)
+		if isSpace && (prevSpace || r != ' ') {
+			return true
+		}
+		prevSpace = isSpace
+	}
+	return false
+}
+
+// collapseSpaces replaces each sequence of white space characters with a single space.
+func collapseSpaces(src string) string {
+	if !needsCollapse(src) {
+		return src
+	}
+	var builder strings.Builder
+	builder.Grow(len(src))
+	prevSpace := false
+	for _, r := range src {
+		if unicode.IsSpace(r) {
+			if !prevSpace {
+				builder.WriteByte(' ')
+			}
+			prevSpace = true
+			continue
+		}
+		prevSpace = false
+		builder.WriteRune(r)
+	}
+	return builder.String()
+}
@@ -0,0 +1,77 @@
+package tupleconv_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tarantool/go-tupleconv"
+)
+
+func TestStringNormalizer(t *testing.T) {
+	tests := map[tupleconv.NormalizeOptions][]convCase[string, string]{
+		{}: {
+			{value: " 42 ", expected: " 42 "},
+			{value: "\uFEFFa\x00b", expected: "\uFEFFa\x00b"},
+		},
+		{Trim: true}: {
+			{value: " 42 ", expected: "42"},
+			{value: "\u00a042\u00a0", expected: "42"},
+			{value: "\t4 2\n", expected: "4 2"},
+			{value: "", expected: ""},
+		},
+		{CollapseSpaces: true}: {
+			{value: "a  b", expected: "a b"},
+			{value: " a \t\u00a0b ", expected: " a b "},
+			{value: "a b", expected: "a b"},
+		},
+		{CollapseSpaces: true, Trim: true}: {
+			{value: "  New   York  ", expected: "New York"},
+		},
+		{Form: tupleconv.NormalizationNFC}: {
+			{value: "e\u0301", expected: "\u00e9"},
+			{value: "\u00e9", expected: "\u00e9"},
+			{value: "\ufb01", expected: "\ufb01"},
+		},
+		{Form: tupleconv.NormalizationNFKC}: {
+			{value: "e\u0301", expected: "\u00e9"},
+			{value: "\ufb01", expected: "fi"},
+			{value: "\uff14\uff12", expected: "42"},
+		},
+		{Form: "NFX"}: {
+			{value: "a", isErr: true},
+		},
+		{StripControl: true}: {
+			{value: "a\x00b\x1fc\x7f", expected: "abc"},
+			{value: "a\tb\nc", expected: "a\tb\nc"},
+		},
+		{StripBOM: true}: {
+			{value: "\uFEFF42", expected: "42"},
+			{value: "4\uFEFF2", expected: "4\uFEFF2"},
+		},
+		{FoldCase: true}: {
+			{value: "HeLLo", expected: "hello"},
+			{value: "Stra\u00dfe", expected: "strasse"},
+		},
+		{StripBOM: true, StripControl: true, Trim: true}: {
+			{value: "\uFEFF\x00 42 ", expected: "42"},
+		},
+	}
+
+	for opts, cases := range tests {
+		HelperTestConverter[string, string](t, tupleconv.MakeStringNormalizer(opts), cases)
+	}
+}
+
+func TestStringNormalizer_unchanged(t *testing.T) {
+	normalizer := tupleconv.MakeStringNormalizer(tupleconv.NormalizeOptions{
+		StripBOM:       true,
+		StripControl:   true,
+		Form:           tupleconv.NormalizationNFC,
+		CollapseSpaces: true,
+		Trim:           true,
+	})
+	allocs := testing.AllocsPerRun(100, func() {
+		_, _ = normalizer.Convert("already normalized string")
+	})
+	assert.Zero(t, allocs)
+}
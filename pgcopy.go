@@ -0,0 +1,550 @@
+package tupleconv
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/tarantool/go-tarantool/v2/datetime"
+)
+
+const (
+	// pgCopyNull is the representation of NULL in the PostgreSQL COPY text format.
+	pgCopyNull = `\N`
+	// pgCopyEnd is the end-of-data marker of the PostgreSQL COPY text format.
+	pgCopyEnd = `\.`
+	// pgDatetimeLayout is the layout of datetime values for PostgreSQL.
+	pgDatetimeLayout = "2006-01-02 15:04:05.999999999-07:00"
+	// pgByteaHexPrefix is the prefix of bytea values in the hex format.
+	pgByteaHexPrefix = `\x`
+)
+
+// PGCopyToTTConvFactory is a TTConvFactory for unescaped values of the PostgreSQL
+// COPY text format. Values are converted by the converters of
+// StringToTTConvFactory, except varbinary values, that are decoded from bytea
+// in the hex (`\x0102`) or the escape format. Timestamps, dates and booleans in
+// the PostgreSQL output format are accepted by default.
+//
+// NULL is represented by `\N` before unescaping, so it is handled by
+// PGCopyReader, and the nullable converters of the factory don't change
+// the converters.
+type PGCopyToTTConvFactory struct {
+	// strFac is the factory of the converters of values.
+	strFac StringToTTConvFactory
+}
+
+// MakePGCopyToTTConvFactory creates PGCopyToTTConvFactory.
+func MakePGCopyToTTConvFactory() PGCopyToTTConvFactory {
+	return PGCopyToTTConvFactory{
+		strFac: MakeStringToTTConvFactory().WithDatetimeLayouts(sqlDatetimeLayouts...),
+	}
+}
+
+var (
+	_ TTConvFactory[string] = (*PGCopyToTTConvFactory)(nil)
+	_ anyConvFactory        = (*PGCopyToTTConvFactory)(nil)
+)
+
+// anyFactory is the implementation of anyConvFactory for PGCopyToTTConvFactory.
+func (fac PGCopyToTTConvFactory) anyFactory() TTConvFactory[any] {
+	return MakeAnyToTTConvFactory(fac.strFac)
+}
+
+// WithStringFactory sets the factory of the converters of values. Its null values
+// are not used.
+func (fac PGCopyToTTConvFactory) WithStringFactory(
+	strFac StringToTTConvFactory) PGCopyToTTConvFactory {
+	fac.strFac = strFac
+	return fac
+}
+
+func (fac PGCopyToTTConvFactory) GetBooleanConverter() Converter[string, any] {
+	return fac.strFac.GetBooleanConverter()
+}
+
+func (fac PGCopyToTTConvFactory) GetStringConverter() Converter[string, any] {
+	return fac.strFac.GetStringConverter()
+}
+
+func (fac PGCopyToTTConvFactory) GetUnsignedConverter() Converter[string, any] {
+	return fac.strFac.GetUnsignedConverter()
+}
+
+func (fac PGCopyToTTConvFactory) GetDatetimeConverter() Converter[string, any] {
+	return fac.strFac.GetDatetimeConverter()
+}
+
+func (fac PGCopyToTTConvFactory) GetUUIDConverter() Converter[string, any] {
+	return fac.strFac.GetUUIDConverter()
+}
+
+func (fac PGCopyToTTConvFactory) GetMapConverter() Converter[string, any] {
+	return fac.strFac.GetMapConverter()
+}
+
+func (fac PGCopyToTTConvFactory) GetArrayConverter() Converter[string, any] {
+	return fac.strFac.GetArrayConverter()
+}
+
+// GetVarbinaryConverter returns a converter from bytea in the hex or the escape
+// format to varbinary.
+func (PGCopyToTTConvFactory) GetVarbinaryConverter() Converter[string, any] {
+	return MakeFuncConverter(func(src string) (any, error) {
+		return decodeBytea(src)
+	})
+}
+
+func (fac PGCopyToTTConvFactory) GetDoubleConverter() Converter[string, any] {
+	return fac.strFac.GetDoubleConverter()
+}
+
+func (fac PGCopyToTTConvFactory) GetDecimalConverter() Converter[string, any] {
+	return fac.strFac.GetDecimalConverter()
+}
+
+func (fac PGCopyToTTConvFactory) GetIntegerConverter() Converter[string, any] {
+	return fac.strFac.GetIntegerConverter()
+}
+
+func (fac PGCopyToTTConvFactory) GetNumberConverter() Converter[string, any] {
+	return fac.strFac.GetNumberConverter()
+}
+
+func (fac PGCopyToTTConvFactory) GetAnyConverter() Converter[string, any] {
+	return fac.strFac.GetAnyConverter()
+}
+
+func (fac PGCopyToTTConvFactory) GetScalarConverter() Converter[string, any] {
+	return fac.strFac.GetScalarConverter()
+}
+
+func (fac PGCopyToTTConvFactory) GetIntervalConverter() Converter[string, any] {
+	return fac.strFac.GetIntervalConverter()
+}
+
+// MakeNullableConverter returns the converter as is, NULL is handled by
+// PGCopyReader.
+func (PGCopyToTTConvFactory) MakeNullableConverter(
+	converter Converter[string, any]) Converter[string, any] {
+	return converter
+}
+
+// TTToPGCopyConvFactory is a TTToTypeConvFactory for values of the PostgreSQL COPY
+// text format, the reverse of PGCopyToTTConvFactory. Values are converted by
+// the converters of TTToStringConvFactory, except datetime values, that are
+// converted to timestamps with offsets, and varbinary values, that are converted
+// to bytea in the hex format. Values are escaped by PGCopyWriter.
+type TTToPGCopyConvFactory struct {
+	// strFac is the factory of the converters of values.
+	strFac TTToStringConvFactory
+}
+
+// MakeTTToPGCopyConvFactory creates TTToPGCopyConvFactory.
+func MakeTTToPGCopyConvFactory() TTToPGCopyConvFactory {
+	return TTToPGCopyConvFactory{strFac: MakeTTToStringConvFactory()}
+}
+
+var _ TTToTypeConvFactory[string] = (*TTToPGCopyConvFactory)(nil)
+
+func (fac TTToPGCopyConvFactory) GetBooleanConverter() Converter[any, string] {
+	return fac.strFac.GetBooleanConverter()
+}
+
+func (fac TTToPGCopyConvFactory) GetStringConverter() Converter[any, string] {
+	return fac.strFac.GetStringConverter()
+}
+
+func (fac TTToPGCopyConvFactory) GetUnsignedConverter() Converter[any, string] {
+	return fac.strFac.GetUnsignedConverter()
+}
+
+// GetDatetimeConverter returns a converter from datetime to a timestamp with
+// the offset.
+func (TTToPGCopyConvFactory) GetDatetimeConverter() Converter[any, string] {
+	return makeTTToStringConverter(TypeDatetime, func(value datetime.Datetime) (string, error) {
+		return value.ToTime().Format(pgDatetimeLayout), nil
+	})
+}
+
+func (fac TTToPGCopyConvFactory) GetUUIDConverter() Converter[any, string] {
+	return fac.strFac.GetUUIDConverter()
+}
+
+func (fac TTToPGCopyConvFactory) GetMapConverter() Converter[any, string] {
+	return fac.strFac.GetMapConverter()
+}
+
+func (fac TTToPGCopyConvFactory) GetArrayConverter() Converter[any, string] {
+	return fac.strFac.GetArrayConverter()
+}
+
+// GetVarbinaryConverter returns a converter from varbinary to bytea in the hex
+// format.
+func (TTToPGCopyConvFactory) GetVarbinaryConverter() Converter[any, string] {
+	return makeTTToStringConverter(TypeVarbinary, func(value []byte) (string, error) {
+		return pgByteaHexPrefix + hex.EncodeToString(value), nil
+	})
+}
+
+func (fac TTToPGCopyConvFactory) GetDoubleConverter() Converter[any, string] {
+	return fac.strFac.GetDoubleConverter()
+}
+
+func (fac TTToPGCopyConvFactory) GetDecimalConverter() Converter[any, string] {
+	return fac.strFac.GetDecimalConverter()
+}
+
+func (fac TTToPGCopyConvFactory) GetIntegerConverter() Converter[any, string] {
+	return fac.strFac.GetIntegerConverter()
+}
+
+func (fac TTToPGCopyConvFactory) GetNumberConverter() Converter[any, string] {
+	return fac.strFac.GetNumberConverter()
+}
+
+func (fac TTToPGCopyConvFactory) GetAnyConverter() Converter[any, string] {
+	return fac.strFac.GetAnyConverter()
+}
+
+func (fac TTToPGCopyConvFactory) GetScalarConverter() Converter[any, string] {
+	return fac.strFac.GetScalarConverter()
+}
+
+func (fac TTToPGCopyConvFactory) GetIntervalConverter() Converter[any, string] {
+	return fac.strFac.GetIntervalConverter()
+}
+
+// MakeNullableConverter returns the converter as is, NULL is handled by
+// PGCopyWriter.
+func (TTToPGCopyConvFactory) MakeNullableConverter(
+	converter Converter[any, string]) Converter[any, string] {
+	return converter
+}
+
+// decodeBytea decodes bytea in the hex or the escape format.
+func decodeBytea(src string) ([]byte, error) {
+	if strings.HasPrefix(src, pgByteaHexPrefix) {
+		return hex.DecodeString(src[len(pgByteaHexPrefix):])
+	}
+	result := make([]byte, 0, len(src))
+	for i := 0; i < len(src); i++ {
+		if src[i] != '\\' {
+			result = append(result, src[i])
+			continue
+		}
+		if i+1 < len(src) && src[i+1] == '\\' {
+			result = append(result, '\\')
+			i++
+			continue
+		}
+		value, ok := parseOctalByte(src[i+1:])
+		if !ok {
+			return nil, fmt.Errorf("invalid bytea escape at %d", i)
+		}
+		result = append(result, value)
+		i += 3
+	}
+	return result, nil
+}
+
+// isOctalDigit returns true, if the character is an octal digit.
+func isOctalDigit(char byte) bool {
+	return char >= '0' && char <= '7'
+}
+
+// parseOctalByte parses 3 octal digits at the start of the string. ok is false,
+// if there are no 3 digits or the value is greater than 0377.
+func parseOctalByte(src string) (value byte, ok bool) {
+	if len(src) < 3 || !isOctalDigit(src[0]) || !isOctalDigit(src[1]) ||
+		!isOctalDigit(src[2]) {
+		return 0, false
+	}
+	number := int(src[0]-'0')<<6 | int(src[1]-'0')<<3 | int(src[2]-'0')
+	if number > 0xff {
+		return 0, false
+	}
+	return byte(number), true
+}
+
+// unescapePGCopy unescapes the value of the PostgreSQL COPY text format. Octal
+// escapes greater than \377 are rejected.
+func unescapePGCopy(src []byte) (string, error) {
+	if bytes.IndexByte(src, '\\') < 0 {
+		return string(src), nil
+	}
+	var sb strings.Builder
+	sb.Grow(len(src))
+	for i := 0; i < len(src); i++ {
+		char := src[i]
+		if char != '\\' || i+1 == len(src) {
+			sb.WriteByte(char)
+			continue
+		}
+		i++
+		char = src[i]
+		switch char {
+		case 'b':
+			sb.WriteByte('\b')
+		case 'f':
+			sb.WriteByte('\f')
+		case 'n':
+			sb.WriteByte('\n')
+		case 'r':
+			sb.WriteByte('\r')
+		case 't':
+			sb.WriteByte('\t')
+		case 'v':
+			sb.WriteByte('\v')
+		case '0', '1', '2', '3', '4', '5', '6', '7':
+			start := i - 1
+			value := int(char - '0')
+			for j := 0; j < 2 && i+1 < len(src) && isOctalDigit(src[i+1]); j++ {
+				i++
+				value = value<<3 | int(src[i]-'0')
+			}
+			if value > 0xff {
+				return "", fmt.Errorf("invalid octal escape %s", src[start:i+1])
+			}
+			sb.WriteByte(byte(value))
+		case 'x':
+			if i+1 < len(src) && isHexDigit(src[i+1]) {
+				i++
+				value := hexDigit(src[i])
+				if i+1 < len(src) && isHexDigit(src[i+1]) {
+					i++
+					value = value<<4 | hexDigit(src[i])
+				}
+				sb.WriteByte(value)
+			} else {
+				sb.WriteByte(char)
+			}
+		default:
+			sb.WriteByte(char)
+		}
+	}
+	return sb.String(), nil
+}
+
+// isHexDigit returns true, if the character is a hexadecimal digit.
+func isHexDigit(char byte) bool {
+	return char >= '0' && char <= '9' || char >= 'a' && char <= 'f' || char >= 'A' && char <= 'F'
+}
+
+// hexDigit returns the value of the hexadecimal digit.
+func hexDigit(char byte) byte {
+	switch {
+	case char >= 'a':
+		return char - 'a' + 10
+	case char >= 'A':
+		return char - 'A' + 10
+	}
+	return char - '0'
+}
+
+// appendPGCopyEscaped appends the value, escaped for the PostgreSQL COPY text
+// format, to the buffer.
+func appendPGCopyEscaped(buf []byte, value string) []byte {
+	for i := 0; i < len(value); i++ {
+		switch char := value[i]; char {
+		case '\\':
+			buf = append(buf, '\\', '\\')
+		case '\b':
+			buf = append(buf, '\\', 'b')
+		case '\f':
+			buf = append(buf, '\\', 'f')
+		case '\n':
+			buf = append(buf, '\\', 'n')
+		case '\r':
+			buf = append(buf, '\\', 'r')
+		case '\t':
+			buf = append(buf, '\\', 't')
+		case '\v':
+			buf = append(buf, '\\', 'v')
+		default:
+			buf = append(buf, char)
+		}
+	}
+	return buf
+}
+
+// errPGCopyNull is returned for NULL in non-nullable fields.
+var errPGCopyNull = errors.New("NULL in non-nullable field")
+
+// notNullableFormat returns the copy of the space format with non-nullable
+// fields.
+func notNullableFormat(spaceFmt []SpaceField) []SpaceField {
+	result := make([]SpaceField, len(spaceFmt))
+	for i, field := range spaceFmt {
+		result[i] = field
+		result[i].IsNullable = false
+	}
+	return result
+}
+
+// PGCopyReader reads rows of the PostgreSQL COPY text format: fields are
+// separated by tabs, rows by newlines, `\N` is NULL, and special characters are
+// escaped by backslashes. Rows are converted to tuples by the space format.
+type PGCopyReader struct {
+	reader     *bufio.Reader
+	spaceFmt   []SpaceField
+	converters []Converter[string, any]
+	// line is the buffer of the current row.
+	line []byte
+	// row is the number of the current row, starting from 1.
+	row int
+}
+
+// MakePGCopyReader creates PGCopyReader with the converters, made by the factory
+// for the space format. NULL is converted to nil for nullable fields and is an
+// error for other fields.
+func MakePGCopyReader(reader io.Reader, fac TTConvFactory[string],
+	spaceFmt []SpaceField) (*PGCopyReader, error) {
+	converters, err := MakeTypeToTTConverters(fac, notNullableFormat(spaceFmt))
+	if err != nil {
+		return nil, err
+	}
+	return &PGCopyReader{
+		reader:     bufio.NewReader(reader),
+		spaceFmt:   spaceFmt,
+		converters: converters,
+	}, nil
+}
+
+// readLine reads the next line without the line terminator.
+func (reader *PGCopyReader) readLine() ([]byte, error) {
+	reader.line = reader.line[:0]
+	for {
+		chunk, err := reader.reader.ReadSlice('\n')
+		reader.line = append(reader.line, chunk...)
+		if err == bufio.ErrBufferFull {
+			continue
+		}
+		if err == io.EOF && len(reader.line) > 0 {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		break
+	}
+	line := reader.line
+	if len(line) > 0 && line[len(line)-1] == '\n' {
+		line = line[:len(line)-1]
+	}
+	if len(line) > 0 && line[len(line)-1] == '\r' {
+		line = line[:len(line)-1]
+	}
+	return line, nil
+}
+
+// ReadTuple reads the next row and converts it to a tuple. io.EOF is returned,
+// when there are no more rows or the end-of-data marker `\.` is read.
+// Conversion errors are returned as *RecordError.
+func (reader *PGCopyReader) ReadTuple() ([]any, error) {
+	line, err := reader.readLine()
+	if err != nil {
+		return nil, err
+	}
+	if string(line) == pgCopyEnd {
+		return nil, io.EOF
+	}
+	reader.row++
+	tuple := make([]any, 0, len(reader.spaceFmt))
+	for {
+		value, rest, found := bytes.Cut(line, []byte{'\t'})
+		pos := len(tuple)
+		if pos == len(reader.spaceFmt) {
+			return nil, &RecordError{Record: reader.row,
+				Err: fmt.Errorf("more than %d fields", len(reader.spaceFmt))}
+		}
+		result, err := reader.convert(pos, value)
+		if err != nil {
+			return nil, &RecordError{Record: reader.row,
+				Err: fmt.Errorf("column %q: %w", reader.spaceFmt[pos].Name, err)}
+		}
+		tuple = append(tuple, result)
+		if !found {
+			break
+		}
+		line = rest
+	}
+	if len(tuple) != len(reader.spaceFmt) {
+		return nil, &RecordError{Record: reader.row,
+			Err: fmt.Errorf("%d fields, expected %d", len(tuple), len(reader.spaceFmt))}
+	}
+	return tuple, nil
+}
+
+// convert converts the raw value of the field.
+func (reader *PGCopyReader) convert(pos int, value []byte) (any, error) {
+	if string(value) == pgCopyNull {
+		if !reader.spaceFmt[pos].IsNullable {
+			return nil, errPGCopyNull
+		}
+		return nil, nil
+	}
+	unescaped, err := unescapePGCopy(value)
+	if err != nil {
+		return nil, err
+	}
+	return reader.converters[pos].Convert(unescaped)
+}
+
+// PGCopyWriter writes tuples in the PostgreSQL COPY text format. Tuples are
+// converted to the rows by the space format.
+type PGCopyWriter struct {
+	writer     io.Writer
+	spaceFmt   []SpaceField
+	converters []Converter[any, string]
+	// buf is the buffer of the current row.
+	buf []byte
+}
+
+// MakePGCopyWriter creates PGCopyWriter with the converters, made by the factory
+// for the space format. nil is written as NULL for nullable fields and is an
+// error for other fields.
+func MakePGCopyWriter(writer io.Writer, fac TTToTypeConvFactory[string],
+	spaceFmt []SpaceField) (*PGCopyWriter, error) {
+	converters, err := MakeTTToTypeConverters(fac, notNullableFormat(spaceFmt))
+	if err != nil {
+		return nil, err
+	}
+	return &PGCopyWriter{writer: writer, spaceFmt: spaceFmt, converters: converters}, nil
+}
+
+// WriteTuple converts the tuple and writes it as a row. Missing trailing fields
+// are NULL.
+func (writer *PGCopyWriter) WriteTuple(tuple []any) error {
+	if len(tuple) > len(writer.spaceFmt) {
+		return fmt.Errorf("%d fields, expected %d", len(tuple), len(writer.spaceFmt))
+	}
+	writer.buf = writer.buf[:0]
+	for pos, field := range writer.spaceFmt {
+		if pos > 0 {
+			writer.buf = append(writer.buf, '\t')
+		}
+		var value any
+		if pos < len(tuple) {
+			value = tuple[pos]
+		}
+		if value == nil && field.IsNullable {
+			writer.buf = append(writer.buf, pgCopyNull...)
+			continue
+		}
+		if value == nil {
+			return fmt.Errorf("column %q: %w", field.Name, errPGCopyNull)
+		}
+		str, err := writer.converters[pos].Convert(value)
+		if err != nil {
+			return fmt.Errorf("column %q: %w", field.Name, err)
+		}
+		writer.buf = appendPGCopyEscaped(writer.buf, str)
+	}
+	writer.buf = append(writer.buf, '\n')
+	_, err := writer.writer.Write(writer.buf)
+	return err
+}
@@ -0,0 +1,180 @@
+package tupleconv_test
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tarantool/go-tarantool/v2/datetime"
+
+	"github.com/tarantool/go-tupleconv"
+)
+
+var pgCopyFormat = []tupleconv.SpaceField{
+	{Name: "id", Type: tupleconv.TypeUnsigned},
+	{Name: "name", Type: tupleconv.TypeString, IsNullable: true},
+	{Name: "data", Type: tupleconv.TypeVarbinary, IsNullable: true},
+	{Name: "created", Type: tupleconv.TypeDatetime},
+	{Name: "active", Type: tupleconv.TypeBoolean},
+}
+
+func mustDatetime(t *testing.T, tm time.Time) datetime.Datetime {
+	dt, err := datetime.MakeDatetime(tm)
+	require.NoError(t, err)
+	return dt
+}
+
+func TestPGCopyReader(t *testing.T) {
+	input := "1\tAlice\t\\\\x00ff0a\t2023-08-30 12:06:05.123456+03\tt\n" +
+		"2\ta\\tb\\nc\\\\d\\101\\x42\t\\N\t2023-08-30 12:06:05\tf\r\n" +
+		"3\t\\\\N\t\\\\001\\\\\\\\a\\\\377\t2023-08-30\tfalse\n" +
+		"4\t\t\\N\t2023-08-30 12:06:05+00\ttrue\n" +
+		"\\.\n" +
+		"5\tignored\n"
+	reader, err := tupleconv.MakePGCopyReader(strings.NewReader(input),
+		tupleconv.MakePGCopyToTTConvFactory(), pgCopyFormat)
+	require.NoError(t, err)
+
+	var tuples [][]any
+	for {
+		tuple, err := reader.ReadTuple()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		tuples = append(tuples, tuple)
+	}
+	created := time.Date(2023, 8, 30, 12, 6, 5, 0, time.UTC)
+	assert.Equal(t, [][]any{
+		{
+			uint64(1), "Alice", []byte{0, 0xff, 0x0a},
+			mustDatetime(t, time.Date(2023, 8, 30, 12, 6, 5, 123456000,
+				time.FixedZone("", 3*60*60))),
+			true,
+		},
+		{uint64(2), "a\tb\nc\\dAB", nil, mustDatetime(t, created), false},
+		{
+			uint64(3), `\N`, []byte{1, '\\', 'a', 0xff},
+			mustDatetime(t, time.Date(2023, 8, 30, 0, 0, 0, 0, time.UTC)), false,
+		},
+		{
+			uint64(4), "", nil,
+			mustDatetime(t, time.Date(2023, 8, 30, 12, 6, 5, 0, time.FixedZone("", 0))), true,
+		},
+	}, tuples)
+}
+
+func TestPGCopyReader_errors(t *testing.T) {
+	cases := []struct {
+		input string
+		err   string
+	}{
+		{
+			input: "\\N\ta\t\\N\t2023-08-30\tt\n",
+			err:   `record 1: column "id": NULL in non-nullable field`,
+		},
+		{
+			input: "1\ta\t\\N\t2023-08-30\n",
+			err:   `record 1: 4 fields, expected 5`,
+		},
+		{
+			input: "1\ta\t\\N\t2023-08-30\tt\tx\n",
+			err:   `record 1: more than 5 fields`,
+		},
+		{
+			input: "1\ta\t\\\\xzz\t2023-08-30\tt\n",
+			err:   `record 1: column "data": unexpected value \xzz for type "varbinary"`,
+		},
+		{
+			input: "1\ta\t\\\\9\t2023-08-30\tt\n",
+			err:   `record 1: column "data": unexpected value \9 for type "varbinary"`,
+		},
+		{
+			input: "1\ta\t\\\\777\t2023-08-30\tt\n",
+			err:   `record 1: column "data": unexpected value \777 for type "varbinary"`,
+		},
+		{
+			input: "1\ta\t\\\\400\t2023-08-30\tt\n",
+			err:   `record 1: column "data": unexpected value \400 for type "varbinary"`,
+		},
+		{
+			input: "1\ta\t\\\\12\t2023-08-30\tt\n",
+			err:   `record 1: column "data": unexpected value \12 for type "varbinary"`,
+		},
+		{
+			input: "1\ta\t\\\\\t2023-08-30\tt\n",
+			err:   `record 1: column "data": unexpected value \ for type "varbinary"`,
+		},
+		{
+			input: "1\t\\777\t\\N\t2023-08-30\tt\n",
+			err:   `record 1: column "name": invalid octal escape \777`,
+		},
+		{
+			input: "1\t\\400\t\\N\t2023-08-30\tt\n",
+			err:   `record 1: column "name": invalid octal escape \400`,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.input, func(t *testing.T) {
+			reader, err := tupleconv.MakePGCopyReader(strings.NewReader(tc.input),
+				tupleconv.MakePGCopyToTTConvFactory(), pgCopyFormat)
+			require.NoError(t, err)
+			_, err = reader.ReadTuple()
+			var recordErr *tupleconv.RecordError
+			require.ErrorAs(t, err, &recordErr)
+			assert.EqualError(t, err, tc.err)
+		})
+	}
+}
+
+func TestPGCopyWriter(t *testing.T) {
+	var buf bytes.Buffer
+	writer, err := tupleconv.MakePGCopyWriter(&buf, tupleconv.MakeTTToPGCopyConvFactory(),
+		pgCopyFormat)
+	require.NoError(t, err)
+
+	created := mustDatetime(t, time.Date(2023, 8, 30, 12, 6, 5, 123456000,
+		time.FixedZone("", 3*60*60)))
+	tuples := [][]any{
+		{uint64(1), "a\tb\nc\\d\r", []byte{0, 0xff, '\\'}, created, true},
+		{uint64(2), `\N`, nil, created, false},
+		{uint64(3), nil, []byte{}, created, false},
+	}
+	for _, tuple := range tuples {
+		require.NoError(t, writer.WriteTuple(tuple))
+	}
+	assert.Equal(t,
+		"1\ta\\tb\\nc\\\\d\\r\t\\\\x00ff5c\t2023-08-30 12:06:05.123456+03:00\ttrue\n"+
+			"2\t\\\\N\t\\N\t2023-08-30 12:06:05.123456+03:00\tfalse\n"+
+			"3\t\\N\t\\\\x\t2023-08-30 12:06:05.123456+03:00\tfalse\n",
+		buf.String())
+
+	reader, err := tupleconv.MakePGCopyReader(&buf, tupleconv.MakePGCopyToTTConvFactory(),
+		pgCopyFormat)
+	require.NoError(t, err)
+	for _, expected := range tuples {
+		tuple, err := reader.ReadTuple()
+		require.NoError(t, err)
+		assert.Equal(t, expected, tuple)
+	}
+	_, err = reader.ReadTuple()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestPGCopyWriter_errors(t *testing.T) {
+	writer, err := tupleconv.MakePGCopyWriter(io.Discard, tupleconv.MakeTTToPGCopyConvFactory(),
+		pgCopyFormat)
+	require.NoError(t, err)
+
+	err = writer.WriteTuple([]any{nil})
+	assert.EqualError(t, err, `column "id": NULL in non-nullable field`)
+	err = writer.WriteTuple([]any{uint64(1), "a", nil, "2023", true})
+	assert.EqualError(t, err,
+		`column "created": unexpected value 2023 of type string for datetime`)
+	err = writer.WriteTuple(make([]any, 6))
+	assert.EqualError(t, err, "6 fields, expected 5")
+}
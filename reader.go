@@ -0,0 +1,309 @@
+package tupleconv
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/tarantool/go-tarantool/v2/datetime"
+	"github.com/tarantool/go-tarantool/v2/decimal"
+	"github.com/vmihailenco/msgpack/v5"
+	"github.com/vmihailenco/msgpack/v5/msgpcode"
+)
+
+// MsgpackTupleReader decodes MessagePack tuples by the space format, validates
+// the field types and converts the fields to Type. Fields beyond the space format
+// are treated as nullable fields of the `any` type.
+type MsgpackTupleReader[Type any] struct {
+	spaceFmt       []SpaceField
+	converters     []Converter[any, Type]
+	extraConverter Converter[any, Type]
+	// subFields are the sub-fields of the fields of the space format.
+	subFields [][]subField
+}
+
+// MakeMsgpackTupleReader creates MsgpackTupleReader.
+func MakeMsgpackTupleReader[Type any](
+	fac TTToTypeConvFactory[Type],
+	spaceFmt []SpaceField) (MsgpackTupleReader[Type], error) {
+	converters, err := MakeTTToTypeConverters(fac, spaceFmt)
+	if err != nil {
+		return MsgpackTupleReader[Type]{}, err
+	}
+	return MsgpackTupleReader[Type]{
+		spaceFmt:       spaceFmt,
+		converters:     converters,
+		extraConverter: fac.MakeNullableConverter(fac.GetAnyConverter()),
+		subFields:      make([][]subField, len(spaceFmt)),
+	}, nil
+}
+
+// WithNestedFormat returns the reader, that checks the sub-fields of the decoded
+// fields by the nested format.
+func (reader MsgpackTupleReader[Type]) WithNestedFormat(
+	nested NestedFormat) (MsgpackTupleReader[Type], error) {
+	subFields, err := parseNestedFormat(reader.spaceFmt, nested)
+	if err != nil {
+		return MsgpackTupleReader[Type]{}, err
+	}
+	reader.subFields = subFields
+	return reader, nil
+}
+
+// DecodeTuple decodes and converts a tuple.
+func (reader MsgpackTupleReader[Type]) DecodeTuple(decoder *msgpack.Decoder) ([]Type, error) {
+	tupleLen, err := decoder.DecodeArrayLen()
+	if err != nil {
+		return nil, err
+	}
+	if tupleLen < 0 {
+		return nil, fmt.Errorf("tuple expected, got nil")
+	}
+	for i := tupleLen; i < len(reader.spaceFmt); i++ {
+		if !reader.spaceFmt[i].IsNullable {
+			return nil, fmt.Errorf("field #%d (%q) is missing", i+1, reader.spaceFmt[i].Name)
+		}
+	}
+
+	result := make([]Type, 0, preallocatedLen(tupleLen))
+	for i := 0; i < tupleLen; i++ {
+		field := SpaceField{Type: TypeAny, IsNullable: true}
+		converter := reader.extraConverter
+		var subFields []subField
+		if i < len(reader.spaceFmt) {
+			field, converter = reader.spaceFmt[i], reader.converters[i]
+			subFields = reader.subFields[i]
+		}
+		value, err := decodeTTValue(decoder)
+		if err != nil {
+			return nil, fmt.Errorf("field #%d (%q): %w", i+1, field.Name, err)
+		}
+		if err := validateNestedValue(field, subFields, value); err != nil {
+			return nil, fmt.Errorf("field #%d (%q): %w", i+1, field.Name, err)
+		}
+		converted, err := converter.Convert(value)
+		if err != nil {
+			return nil, fmt.Errorf("field #%d (%q): %w", i+1, field.Name, err)
+		}
+		result = append(result, converted)
+	}
+	return result, nil
+}
+
+// DecodeTuples decodes and converts an array of tuples.
+func (reader MsgpackTupleReader[Type]) DecodeTuples(decoder *msgpack.Decoder) ([][]Type, error) {
+	count, err := decoder.DecodeArrayLen()
+	if err != nil {
+		return nil, err
+	}
+	if count < 0 {
+		return nil, nil
+	}
+	tuples := make([][]Type, 0, preallocatedLen(count))
+	for i := 0; i < count; i++ {
+		tuple, err := reader.DecodeTuple(decoder)
+		if err != nil {
+			return nil, fmt.Errorf("tuple #%d: %w", i+1, err)
+		}
+		tuples = append(tuples, tuple)
+	}
+	return tuples, nil
+}
+
+// ReadTuple decodes and converts a tuple from the MessagePack data.
+func (reader MsgpackTupleReader[Type]) ReadTuple(data []byte) ([]Type, error) {
+	decoder := msgpack.GetDecoder()
+	defer msgpack.PutDecoder(decoder)
+	decoder.Reset(bytes.NewReader(data))
+	return reader.DecodeTuple(decoder)
+}
+
+// MsgpackTuples is a msgpack.CustomDecoder of an array of tuples, that can be used
+// as a result of go-tarantool requests:
+//
+//	tuples := tupleconv.MsgpackTuples[string]{Reader: reader}
+//	err := conn.Do(req).GetTyped(&tuples)
+type MsgpackTuples[Type any] struct {
+	// Reader is the reader of tuples.
+	Reader MsgpackTupleReader[Type]
+	// Tuples are the decoded tuples.
+	Tuples [][]Type
+}
+
+var _ msgpack.CustomDecoder = (*MsgpackTuples[string])(nil)
+
+// DecodeMsgpack is the implementation of msgpack.CustomDecoder for MsgpackTuples.
+func (tuples *MsgpackTuples[Type]) DecodeMsgpack(decoder *msgpack.Decoder) error {
+	var err error
+	tuples.Tuples, err = tuples.Reader.DecodeTuples(decoder)
+	return err
+}
+
+// decodeTTValue decodes a value. Integers are decoded to uint64 if they are
+// not negative and to int64 otherwise, floats are decoded to float64, maps are
+// decoded to map[string]any if all keys are strings and to map[any]any otherwise.
+func decodeTTValue(decoder *msgpack.Decoder) (any, error) {
+	code, err := decoder.PeekCode()
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case code == msgpcode.Nil:
+		return nil, decoder.DecodeNil()
+	case code == msgpcode.True || code == msgpcode.False:
+		return decoder.DecodeBool()
+	case msgpcode.IsFixedNum(code) || isIntCode(code):
+		value, err := decoder.DecodeInt64()
+		if err != nil || value < 0 {
+			return value, err
+		}
+		return uint64(value), nil
+	case isUintCode(code):
+		return decoder.DecodeUint64()
+	case code == msgpcode.Float || code == msgpcode.Double:
+		return decoder.DecodeFloat64()
+	case msgpcode.IsString(code):
+		return decoder.DecodeString()
+	case msgpcode.IsBin(code):
+		return decoder.DecodeBytes()
+	case msgpcode.IsFixedArray(code) || code == msgpcode.Array16 || code == msgpcode.Array32:
+		return decodeTTArray(decoder)
+	case msgpcode.IsFixedMap(code) || code == msgpcode.Map16 || code == msgpcode.Map32:
+		return decodeTTMap(decoder)
+	case msgpcode.IsExt(code):
+		return decoder.DecodeInterface()
+	}
+	return nil, fmt.Errorf("unexpected MessagePack code 0x%x", code)
+}
+
+// isIntCode checks if the code is a signed integer code.
+func isIntCode(code byte) bool {
+	return code == msgpcode.Int8 || code == msgpcode.Int16 || code == msgpcode.Int32 ||
+		code == msgpcode.Int64
+}
+
+// isUintCode checks if the code is an unsigned integer code.
+func isUintCode(code byte) bool {
+	return code == msgpcode.Uint8 || code == msgpcode.Uint16 || code == msgpcode.Uint32 ||
+		code == msgpcode.Uint64
+}
+
+// maxPreallocatedLen is the maximum number of items, that are allocated by
+// the length of a MessagePack array or map before they are decoded.
+const maxPreallocatedLen = 1024
+
+// preallocatedLen returns the capacity to allocate for the decoded length.
+// Lengths are read from the input and can't be trusted, so larger arrays and
+// maps grow while their items are decoded.
+func preallocatedLen(length int) int {
+	if length > maxPreallocatedLen {
+		return maxPreallocatedLen
+	}
+	return length
+}
+
+// decodeTTArray decodes an array.
+func decodeTTArray(decoder *msgpack.Decoder) (any, error) {
+	arrayLen, err := decoder.DecodeArrayLen()
+	if err != nil {
+		return nil, err
+	}
+	result := make([]any, 0, preallocatedLen(arrayLen))
+	for i := 0; i < arrayLen; i++ {
+		item, err := decodeTTValue(decoder)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, item)
+	}
+	return result, nil
+}
+
+// decodeTTMap decodes a map.
+func decodeTTMap(decoder *msgpack.Decoder) (any, error) {
+	mapLen, err := decoder.DecodeMapLen()
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]any, 0, preallocatedLen(mapLen))
+	values := make([]any, 0, preallocatedLen(mapLen))
+	stringKeys := true
+	for i := 0; i < mapLen; i++ {
+		key, err := decodeTTValue(decoder)
+		if err != nil {
+			return nil, err
+		}
+		value, err := decodeTTValue(decoder)
+		if err != nil {
+			return nil, err
+		}
+		keys, values = append(keys, key), append(values, value)
+		_, isString := key.(string)
+		stringKeys = stringKeys && isString
+	}
+	if stringKeys {
+		result := make(map[string]any, mapLen)
+		for i, key := range keys {
+			result[key.(string)] = values[i]
+		}
+		return result, nil
+	}
+	result := make(map[any]any, mapLen)
+	for i, key := range keys {
+		switch key.(type) {
+		case []any, map[string]any, map[any]any, []byte:
+			return nil, fmt.Errorf("unsupported map key type %T", key)
+		}
+		result[key] = values[i]
+	}
+	return result, nil
+}
+
+// valueTypeName returns the most specific tarantool type of the decoded value.
+func valueTypeName(value any) (TypeName, bool) {
+	switch value.(type) {
+	case bool:
+		return TypeBoolean, true
+	case uint64:
+		return TypeUnsigned, true
+	case int64:
+		return TypeInteger, true
+	case float64:
+		return TypeDouble, true
+	case string:
+		return TypeString, true
+	case []byte:
+		return TypeVarbinary, true
+	case decimal.Decimal:
+		return TypeDecimal, true
+	case uuid.UUID:
+		return TypeUUID, true
+	case datetime.Datetime:
+		return TypeDatetime, true
+	case datetime.Interval:
+		return TypeInterval, true
+	case []any:
+		return TypeArray, true
+	case map[string]any, map[any]any:
+		return TypeMap, true
+	}
+	return "", false
+}
+
+// validateTTValue checks if the decoded value can be stored in the field.
+func validateTTValue(field SpaceField, value any) error {
+	if value == nil {
+		if field.IsNullable || field.Type == TypeAny {
+			return nil
+		}
+		return fmt.Errorf("unexpected null value for non-nullable field")
+	}
+	typ, ok := valueTypeName(value)
+	if !ok {
+		return fmt.Errorf("unexpected value of type %T", value)
+	}
+	if !isTypeAssignable(field.Type, typ) {
+		return fmt.Errorf("unexpected %s value for %s field", typ, field.Type)
+	}
+	return nil
+}
@@ -0,0 +1,168 @@
+package tupleconv_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vmihailenco/msgpack/v5"
+
+	"github.com/tarantool/go-tupleconv"
+)
+
+func makeTestTupleReader(t *testing.T,
+	spaceFmt []tupleconv.SpaceField) tupleconv.MsgpackTupleReader[string] {
+	reader, err := tupleconv.MakeMsgpackTupleReader[string](
+		tupleconv.MakeTTToStringConvFactory(), spaceFmt)
+	require.NoError(t, err)
+	return reader
+}
+
+func TestMsgpackTupleReader_roundTrip(t *testing.T) {
+	msgpackMapper := tupleconv.MakeMsgpackMapper(makeMsgpackTestMapper(t))
+	data, err := msgpackMapper.AppendTuple(nil, msgpackTestTuple)
+	require.NoError(t, err)
+
+	reader := makeTestTupleReader(t, msgpackTestFormat)
+	result, err := reader.ReadTuple(data)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"100500", "-42", "hello", "1.5", "true", "12345.678",
+		"09b56913-11f0-4fa4-b5d0-901b5efa532a", "2023-08-30T12:06:05.12+0000",
+		"1,2,0,0,0,0,0,0,1", `[1,"a",[]]`, `{"a":{"b":null}}`, "bin", "",
+	}, result)
+}
+
+func TestMsgpackTupleReader_DecodeTuples(t *testing.T) {
+	spaceFmt := []tupleconv.SpaceField{
+		{Name: "id", Type: tupleconv.TypeUnsigned},
+		{Name: "value", Type: tupleconv.TypeScalar, IsNullable: true},
+	}
+	data, err := msgpack.Marshal([][]any{
+		{1, "a"},
+		{2},
+		{3, nil, map[int]string{1: "x"}},
+		{4, -1.5},
+	})
+	require.NoError(t, err)
+
+	tuples := tupleconv.MsgpackTuples[string]{
+		Reader: makeTestTupleReader(t, spaceFmt),
+	}
+	require.NoError(t, msgpack.Unmarshal(data, &tuples))
+	assert.Equal(t, [][]string{
+		{"1", "a"},
+		{"2"},
+		{"3", "", `{"1":"x"}`},
+		{"4", "-1.5"},
+	}, tuples.Tuples)
+}
+
+func TestMsgpackTupleReader_errors(t *testing.T) {
+	spaceFmt := []tupleconv.SpaceField{
+		{Name: "id", Type: tupleconv.TypeUnsigned},
+		{Name: "count", Type: tupleconv.TypeInteger},
+		{Name: "value", Type: tupleconv.TypeScalar, IsNullable: true},
+	}
+	cases := []struct {
+		name       string
+		tuple      any
+		errMessage string
+	}{
+		{
+			name:       "not a tuple",
+			tuple:      "abc",
+			errMessage: "msgpack: invalid code",
+		},
+		{
+			name:       "missing field",
+			tuple:      []any{1},
+			errMessage: `field #2 ("count") is missing`,
+		},
+		{
+			name:       "negative unsigned",
+			tuple:      []any{-1, 1},
+			errMessage: `field #1 ("id"): unexpected integer value for unsigned field`,
+		},
+		{
+			name:       "string for integer",
+			tuple:      []any{1, "1"},
+			errMessage: `field #2 ("count"): unexpected string value for integer field`,
+		},
+		{
+			name:       "null for non-nullable",
+			tuple:      []any{1, nil},
+			errMessage: `field #2 ("count"): unexpected null value for non-nullable field`,
+		},
+		{
+			name:       "array for scalar",
+			tuple:      []any{1, 1, []any{}},
+			errMessage: `field #3 ("value"): unexpected array value for scalar field`,
+		},
+	}
+
+	reader := makeTestTupleReader(t, spaceFmt)
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := msgpack.Marshal(tc.tuple)
+			require.NoError(t, err)
+			_, err = reader.ReadTuple(data)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.errMessage)
+		})
+	}
+
+	decoder := msgpack.NewDecoder(bytes.NewReader([]byte{0x91, 0x93, 0xa1, 'x', 0x01, 0x01}))
+	_, err := reader.DecodeTuples(decoder)
+	assert.EqualError(t, err,
+		`tuple #1: field #1 ("id"): unexpected string value for unsigned field`)
+}
+
+func TestMsgpackTupleReader_truncatedHeaders(t *testing.T) {
+	spaceFmt := []tupleconv.SpaceField{{Name: "value", Type: tupleconv.TypeAny}}
+	reader := makeTestTupleReader(t, spaceFmt)
+	// Headers of an array32 and a map32 with 2^32-1 items without the items.
+	array32 := []byte{0xdd, 0xff, 0xff, 0xff, 0xff}
+	map32 := []byte{0xdf, 0xff, 0xff, 0xff, 0xff}
+
+	cases := []struct {
+		name string
+		data []byte
+		err  string
+	}{
+		{"tuple", array32, `field #1 ("value"): EOF`},
+		{"array", append([]byte{0x91}, array32...), `field #1 ("value"): EOF`},
+		{"map", append([]byte{0x91}, map32...), `field #1 ("value"): EOF`},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := reader.ReadTuple(tc.data)
+			assert.EqualError(t, err, tc.err)
+		})
+	}
+
+	_, err := reader.DecodeTuples(msgpack.NewDecoder(bytes.NewReader(array32)))
+	assert.EqualError(t, err, "tuple #1: EOF")
+}
+
+func TestMsgpackTupleReader_WithNestedFormat(t *testing.T) {
+	reader, err := makeTestTupleReader(t, nestedSpaceFmt).WithNestedFormat(nestedFormat)
+	require.NoError(t, err)
+
+	data, err := msgpack.Marshal([]any{1, map[string]any{"address": map[string]any{}}})
+	require.NoError(t, err)
+	_, err = reader.ReadTuple(data)
+	assert.EqualError(t, err, `field #2 ("data"): path "address.city" is missing`)
+
+	data, err = msgpack.Marshal([]any{1, map[string]any{"address": map[string]any{
+		"city": "Berlin"}}})
+	require.NoError(t, err)
+	tuple, err := reader.ReadTuple(data)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1", `{"address":{"city":"Berlin"}}`}, tuple)
+
+	_, err = makeTestTupleReader(t, nestedSpaceFmt).WithNestedFormat(
+		tupleconv.NestedFormat{"id": {{Path: "a", Type: tupleconv.TypeString}}})
+	assert.EqualError(t, err, `field "id": unsigned fields can't have sub-fields`)
+}
@@ -0,0 +1,571 @@
+package tupleconv
+
+import (
+	"bufio"
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// FieldRef is a reference to a space field by its name or, if the name is
+// empty, by its zero-based number.
+type FieldRef struct {
+	Name string
+	No   int
+}
+
+// String returns the quoted name or the number of the field, starting from 1,
+// for example `"id"` or `#1`.
+func (ref FieldRef) String() string {
+	if ref.Name != "" {
+		return strconv.Quote(ref.Name)
+	}
+	return fmt.Sprintf("#%d", ref.No+1)
+}
+
+// ForeignKeyField is a local field of the foreign key and the field of
+// the referenced space, that it references.
+type ForeignKeyField struct {
+	Local   FieldRef
+	Foreign FieldRef
+}
+
+// ForeignKey is a reference constraint of the space: non-null values of its
+// local fields must exist in the referenced fields of the referenced space.
+// A field foreign key has a single field, its values are the values of
+// the field. A tuple foreign key has several fields, its values are arrays of
+// the values of the fields in the order of Fields, and it is not checked, if
+// any of the values is null. The referenced space is set by its name or, if
+// the name is empty, by its id.
+//
+// Foreign keys are not decoded with the space format: build them by
+// the foreign_key definitions of the fields and of the space.
+type ForeignKey struct {
+	// Name is the name of the constraint.
+	Name string
+	// Space is the name of the referenced space.
+	Space string
+	// SpaceId is the id of the referenced space, if the name is empty.
+	SpaceId uint32
+	// Fields are the local and the referenced fields.
+	Fields []ForeignKeyField
+}
+
+// String returns the referenced space and fields, for example `"users"."id"`
+// or `512.("id", "region")`. Foreign keys with equal strings reference the same
+// values.
+func (key ForeignKey) String() string {
+	space := strconv.Quote(key.Space)
+	if key.Space == "" {
+		space = strconv.FormatUint(uint64(key.SpaceId), 10)
+	}
+	if len(key.Fields) == 1 {
+		return space + "." + key.Fields[0].Foreign.String()
+	}
+	fields := make([]string, 0, len(key.Fields))
+	for _, field := range key.Fields {
+		fields = append(fields, field.Foreign.String())
+	}
+	return fmt.Sprintf("%s.(%s)", space, strings.Join(fields, ", "))
+}
+
+// Lookup checks, that the referenced values exist. It must be safe for
+// concurrent use.
+type Lookup interface {
+	// Exists returns, whether each of the values exists in the referenced fields.
+	Exists(ctx context.Context, key ForeignKey, values []any) ([]bool, error)
+}
+
+// LookupFunc is a Lookup by the function, for example, a select from the
+// referenced space.
+type LookupFunc func(ctx context.Context, key ForeignKey, values []any) ([]bool, error)
+
+// Exists is the implementation of Lookup for LookupFunc.
+func (lookup LookupFunc) Exists(ctx context.Context, key ForeignKey,
+	values []any) ([]bool, error) {
+	return lookup(ctx, key, values)
+}
+
+// valueSet is a set of converted values, that are equal, if Compare returns 0.
+// Each value has a flag.
+type valueSet struct {
+	buckets map[uint64][]valueSetEntry
+	size    int
+}
+
+// valueSetEntry is a value of the set with the flag.
+type valueSetEntry struct {
+	value any
+	flag  bool
+}
+
+// makeValueSet creates an empty set.
+func makeValueSet() *valueSet {
+	return &valueSet{buckets: map[uint64][]valueSetEntry{}}
+}
+
+// get returns the flag of the value. ok is false, if there is no such value.
+func (set *valueSet) get(value any) (flag bool, ok bool, err error) {
+	hash, err := Hash(value)
+	if err != nil {
+		return false, false, err
+	}
+	for _, entry := range set.buckets[hash] {
+		if result, _ := Compare(entry.value, value); result == 0 {
+			return entry.flag, true, nil
+		}
+	}
+	return false, false, nil
+}
+
+// put adds the value with the flag or replaces the flag of the existing value.
+func (set *valueSet) put(value any, flag bool) error {
+	hash, err := Hash(value)
+	if err != nil {
+		return err
+	}
+	bucket := set.buckets[hash]
+	for i, entry := range bucket {
+		if result, _ := Compare(entry.value, value); result == 0 {
+			bucket[i].flag = flag
+			return nil
+		}
+	}
+	set.buckets[hash] = append(bucket, valueSetEntry{value: value, flag: flag})
+	set.size++
+	return nil
+}
+
+// SetLookup is a Lookup by in-memory sets of the referenced values. Values are
+// equal, if Compare returns 0, for example, unsigned 1 and double 1. Values
+// must not be added concurrently with lookups.
+type SetLookup struct {
+	sets map[string]*valueSet
+}
+
+var _ Lookup = SetLookup{}
+
+// MakeSetLookup creates SetLookup without values.
+func MakeSetLookup() SetLookup {
+	return SetLookup{sets: map[string]*valueSet{}}
+}
+
+// Add adds the converted values of the referenced fields. Values of tuple
+// foreign keys are arrays of the values of the fields.
+func (lookup SetLookup) Add(key ForeignKey, values ...any) error {
+	set, ok := lookup.sets[key.String()]
+	if !ok {
+		set = makeValueSet()
+		lookup.sets[key.String()] = set
+	}
+	for _, value := range values {
+		if err := set.put(value, true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// maxReferenceLineSize is the maximum size of a line, read by AddFile.
+const maxReferenceLineSize = 1 << 20
+
+// AddFile adds values of the referenced field from the file with a value per
+// line, converted by the converter. Empty lines are skipped, lines must not be
+// longer than 1 MiB. Only field foreign keys are supported.
+func (lookup SetLookup) AddFile(key ForeignKey, path string,
+	conv Converter[string, any]) error {
+	if len(key.Fields) != 1 {
+		return fmt.Errorf("%s: values of tuple foreign keys can't be read from files", key)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, maxReferenceLineSize)
+	line := 1
+	for ; scanner.Scan(); line++ {
+		if scanner.Text() == "" {
+			continue
+		}
+		value, err := conv.Convert(scanner.Text())
+		if err == nil {
+			err = lookup.Add(key, value)
+		}
+		if err != nil {
+			return fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return fmt.Errorf("%s:%d: line is longer than %d bytes", path, line,
+				maxReferenceLineSize)
+		}
+		return fmt.Errorf("%s:%d: %w", path, line, err)
+	}
+	return nil
+}
+
+// Exists is the implementation of Lookup for SetLookup.
+func (lookup SetLookup) Exists(_ context.Context, key ForeignKey,
+	values []any) ([]bool, error) {
+	result := make([]bool, len(values))
+	set, ok := lookup.sets[key.String()]
+	if !ok {
+		return result, nil
+	}
+	for i, value := range values {
+		var err error
+		if result[i], _, err = set.get(value); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// ReferenceError is an error of the value, that is not found by the foreign key.
+type ReferenceError struct {
+	// Field is the zero-based number of the first local field of the foreign key.
+	Field int
+	// Name is the name of the field.
+	Name string
+	// ForeignKey is the violated foreign key.
+	ForeignKey ForeignKey
+	// Value is the value, that is not found: the value of the field or, for
+	// tuple foreign keys, the array of the values of the local fields.
+	Value any
+}
+
+// Error is the implementation of error for ReferenceError.
+func (err *ReferenceError) Error() string {
+	return fmt.Sprintf("field #%d (%q): value %v is not found in %s", err.Field+1,
+		err.Name, err.Value, err.ForeignKey)
+}
+
+// reference is the foreign key with the positions of its local fields.
+type reference struct {
+	key       ForeignKey
+	target    string
+	positions []int
+}
+
+// value returns the value of the foreign key in the tuple. ok is false, if any
+// of the local fields is null or missing.
+func (ref reference) value(tuple []any) (value any, ok bool) {
+	values := make([]any, 0, len(ref.positions))
+	for _, pos := range ref.positions {
+		if pos >= len(tuple) || tuple[pos] == nil {
+			return nil, false
+		}
+		values = append(values, tuple[pos])
+	}
+	if len(values) == 1 {
+		return values[0], true
+	}
+	return values, true
+}
+
+// referenceCache caches found values of the referenced fields. When the cache
+// is full, the least recently used value is evicted.
+type referenceCache struct {
+	mutex sync.Mutex
+	// index is the cached values by the referenced fields and their hashes.
+	index map[string]map[uint64][]*list.Element
+	// order is the list of *referenceCacheEntry, the most recently used first.
+	order *list.List
+	limit int
+}
+
+// referenceCacheEntry is a cached value of the referenced fields.
+type referenceCacheEntry struct {
+	target string
+	hash   uint64
+	value  any
+}
+
+// makeReferenceCache creates an empty cache with the maximum number of values.
+func makeReferenceCache(limit int) *referenceCache {
+	return &referenceCache{
+		index: map[string]map[uint64][]*list.Element{},
+		order: list.New(),
+		limit: limit,
+	}
+}
+
+// defaultReferenceBatchSize is the default maximum number of values in a lookup.
+const defaultReferenceBatchSize = 1000
+
+// defaultReferenceCacheSize is the default maximum number of cached values.
+const defaultReferenceCacheSize = 100000
+
+// ReferenceValidator validates references of converted tuples by the foreign
+// keys of the space. Lookups are batched: values of the tuples are looked up
+// once for each foreign key, in chunks of the batch size. Found values are
+// cached, values, that are not found, are looked up again by each validation,
+// so referenced tuples may be inserted during the import. ReferenceValidator
+// is safe for concurrent use.
+type ReferenceValidator struct {
+	spaceFmt  []SpaceField
+	refs      []reference
+	lookup    Lookup
+	batchSize int
+	cache     *referenceCache
+}
+
+// MakeReferenceValidator creates ReferenceValidator by the space format,
+// the foreign keys of the space and the lookup with the batch size of 1000 and
+// the cache of 100000 values. Foreign keys are checked in the order of the slice.
+func MakeReferenceValidator(spaceFmt []SpaceField, foreignKeys []ForeignKey,
+	lookup Lookup) (ReferenceValidator, error) {
+	fieldPos := make(map[string]int, len(spaceFmt))
+	for pos, field := range spaceFmt {
+		fieldPos[field.Name] = pos
+	}
+	refs := make([]reference, 0, len(foreignKeys))
+	for _, key := range foreignKeys {
+		if len(key.Fields) == 0 {
+			return ReferenceValidator{}, fmt.Errorf("foreign key %q: no fields", key.Name)
+		}
+		ref := reference{key: key, target: key.String()}
+		for _, field := range key.Fields {
+			pos, ok := fieldPos[field.Local.Name]
+			if field.Local.Name == "" {
+				pos, ok = field.Local.No, field.Local.No >= 0 && field.Local.No < len(spaceFmt)
+			}
+			if !ok {
+				return ReferenceValidator{}, fmt.Errorf("foreign key %q: unknown field %s",
+					key.Name, field.Local)
+			}
+			ref.positions = append(ref.positions, pos)
+		}
+		refs = append(refs, ref)
+	}
+	return ReferenceValidator{
+		spaceFmt:  spaceFmt,
+		refs:      refs,
+		lookup:    lookup,
+		batchSize: defaultReferenceBatchSize,
+		cache:     makeReferenceCache(defaultReferenceCacheSize),
+	}, nil
+}
+
+// WithBatchSize sets the maximum number of values in a lookup. The batch size
+// less than 1 means no limit.
+func (validator ReferenceValidator) WithBatchSize(size int) ReferenceValidator {
+	validator.batchSize = size
+	return validator
+}
+
+// WithCacheSize sets the maximum number of cached values. When the cache is
+// full, the least recently used value is evicted. A negative size means no
+// limit, 0 disables the cache. The validator gets a new empty cache.
+func (validator ReferenceValidator) WithCacheSize(size int) ReferenceValidator {
+	validator.cache = makeReferenceCache(size)
+	return validator
+}
+
+// find returns the element of the cached value.
+func (cache *referenceCache) find(target string, hash uint64, value any) *list.Element {
+	for _, elem := range cache.index[target][hash] {
+		if result, _ := Compare(elem.Value.(*referenceCacheEntry).value, value); result == 0 {
+			return elem
+		}
+	}
+	return nil
+}
+
+// contains returns true, if the value is cached.
+func (cache *referenceCache) contains(target string, value any) bool {
+	hash, err := Hash(value)
+	if err != nil {
+		return false
+	}
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	elem := cache.find(target, hash, value)
+	if elem == nil {
+		return false
+	}
+	cache.order.MoveToFront(elem)
+	return true
+}
+
+// put caches the found value.
+func (cache *referenceCache) put(target string, value any) {
+	if cache.limit == 0 {
+		return
+	}
+	hash, err := Hash(value)
+	if err != nil {
+		return
+	}
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	if elem := cache.find(target, hash, value); elem != nil {
+		cache.order.MoveToFront(elem)
+		return
+	}
+	buckets, ok := cache.index[target]
+	if !ok {
+		buckets = map[uint64][]*list.Element{}
+		cache.index[target] = buckets
+	}
+	buckets[hash] = append(buckets[hash], cache.order.PushFront(&referenceCacheEntry{
+		target: target,
+		hash:   hash,
+		value:  value,
+	}))
+	if cache.limit > 0 && cache.order.Len() > cache.limit {
+		cache.evict(cache.order.Back())
+	}
+}
+
+// evict removes the element from the cache.
+func (cache *referenceCache) evict(elem *list.Element) {
+	cache.order.Remove(elem)
+	entry := elem.Value.(*referenceCacheEntry)
+	buckets := cache.index[entry.target]
+	bucket := buckets[entry.hash]
+	for i := range bucket {
+		if bucket[i] == elem {
+			bucket = append(bucket[:i], bucket[i+1:]...)
+			break
+		}
+	}
+	if len(bucket) != 0 {
+		buckets[entry.hash] = bucket
+		return
+	}
+	delete(buckets, entry.hash)
+	if len(buckets) == 0 {
+		delete(cache.index, entry.target)
+	}
+}
+
+// resolve looks up the values of the foreign key, that are not cached, and
+// returns the results for all the values.
+func (validator ReferenceValidator) resolve(ctx context.Context, ref reference,
+	values []any) (*valueSet, error) {
+	results := makeValueSet()
+	var missing []any
+	for _, value := range values {
+		if _, ok, err := results.get(value); err != nil || ok {
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if validator.cache.contains(ref.target, value) {
+			if err := results.put(value, true); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if err := results.put(value, false); err != nil {
+			return nil, err
+		}
+		missing = append(missing, value)
+	}
+	for len(missing) != 0 {
+		chunk := missing
+		if validator.batchSize > 0 && len(chunk) > validator.batchSize {
+			chunk = chunk[:validator.batchSize]
+		}
+		missing = missing[len(chunk):]
+		exists, err := validator.lookup.Exists(ctx, ref.key, chunk)
+		if err != nil {
+			return nil, fmt.Errorf("lookup in %s: %w", ref.target, err)
+		}
+		if len(exists) != len(chunk) {
+			return nil, fmt.Errorf("lookup in %s: %d results for %d values", ref.target,
+				len(exists), len(chunk))
+		}
+		for i, value := range chunk {
+			if err := results.put(value, exists[i]); err != nil {
+				return nil, err
+			}
+			if exists[i] {
+				validator.cache.put(ref.target, value)
+			}
+		}
+	}
+	return results, nil
+}
+
+// ValidateBatch validates references of the tuples. Values of each foreign key
+// are looked up at once. The first error of each invalid tuple is returned as
+// *ReferenceError in *RowError, all the errors are returned as *BatchError.
+// Null values and missing fields are not checked.
+func (validator ReferenceValidator) ValidateBatch(ctx context.Context,
+	tuples [][]any) error {
+	rowErrors := make([]error, len(tuples))
+	for _, ref := range validator.refs {
+		values := make([]any, len(tuples))
+		found := make([]bool, len(tuples))
+		var lookups []any
+		for row, tuple := range tuples {
+			if values[row], found[row] = ref.value(tuple); found[row] {
+				lookups = append(lookups, values[row])
+			}
+		}
+		if len(lookups) == 0 {
+			continue
+		}
+		results, err := validator.resolve(ctx, ref, lookups)
+		if err != nil {
+			return err
+		}
+		for row := range tuples {
+			if rowErrors[row] != nil || !found[row] {
+				continue
+			}
+			if exists, _, _ := results.get(values[row]); !exists {
+				rowErrors[row] = &ReferenceError{
+					Field:      ref.positions[0],
+					Name:       validator.spaceFmt[ref.positions[0]].Name,
+					ForeignKey: ref.key,
+					Value:      values[row],
+				}
+			}
+		}
+	}
+	var batchErr BatchError
+	for row, err := range rowErrors {
+		if err != nil {
+			batchErr.Errors = append(batchErr.Errors, &RowError{Row: row, Err: err})
+		}
+	}
+	if len(batchErr.Errors) != 0 {
+		return &batchErr
+	}
+	return nil
+}
+
+// Validate validates references of the tuple. The first error is returned as
+// *ReferenceError.
+func (validator ReferenceValidator) Validate(ctx context.Context, tuple []any) error {
+	err := validator.ValidateBatch(ctx, [][]any{tuple})
+	if batchErr, ok := err.(*BatchError); ok {
+		return batchErr.Errors[0].Err
+	}
+	return err
+}
+
+// Transform returns the tuple transform, that validates references of
+// the converted tuple, for example, as the last post-transform of a mapper.
+// The tuple is not changed. Tuples are looked up one by one, so ValidateBatch
+// is preferable for large imports.
+func (validator ReferenceValidator) Transform() Converter[[]any, []any] {
+	return MakeFuncContextConverter(func(ctx context.Context, tuple []any) ([]any, error) {
+		if err := validator.Validate(ctx, tuple); err != nil {
+			return nil, err
+		}
+		return tuple, nil
+	})
+}
@@ -0,0 +1,304 @@
+package tupleconv_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tarantool/go-tupleconv"
+)
+
+// ordersFormat is the format of a space with foreign keys.
+var ordersFormat = []tupleconv.SpaceField{
+	{Name: "id", Type: tupleconv.TypeUnsigned},
+	{Name: "user_id", Type: tupleconv.TypeUnsigned},
+	{Name: "sku", Type: tupleconv.TypeString, IsNullable: true},
+}
+
+var (
+	usersID = tupleconv.ForeignKey{Name: "user", Space: "users",
+		Fields: []tupleconv.ForeignKeyField{
+			{Local: tupleconv.FieldRef{Name: "user_id"}, Foreign: tupleconv.FieldRef{Name: "id"}},
+		}}
+	productsID = tupleconv.ForeignKey{Name: "product", Space: "products",
+		Fields: []tupleconv.ForeignKeyField{
+			{Local: tupleconv.FieldRef{No: 2}, Foreign: tupleconv.FieldRef{Name: "sku"}},
+		}}
+	// ordersKeys are the foreign keys of ordersFormat.
+	ordersKeys = []tupleconv.ForeignKey{usersID, productsID}
+)
+
+// makeOrdersValidator creates ReferenceValidator of ordersFormat.
+func makeOrdersValidator(t *testing.T, lookup tupleconv.Lookup) tupleconv.ReferenceValidator {
+	validator, err := tupleconv.MakeReferenceValidator(ordersFormat, ordersKeys, lookup)
+	require.NoError(t, err)
+	return validator
+}
+
+// countingLookup is a Lookup, that records lookups.
+type countingLookup struct {
+	mutex   sync.Mutex
+	lookup  tupleconv.Lookup
+	lookups [][]any
+}
+
+func (lookup *countingLookup) Exists(ctx context.Context, key tupleconv.ForeignKey,
+	values []any) ([]bool, error) {
+	lookup.mutex.Lock()
+	lookup.lookups = append(lookup.lookups, values)
+	lookup.mutex.Unlock()
+	return lookup.lookup.Exists(ctx, key, values)
+}
+
+func makeOrdersLookup(t *testing.T) tupleconv.SetLookup {
+	lookup := tupleconv.MakeSetLookup()
+	require.NoError(t, lookup.Add(usersID, uint64(1), uint64(2)))
+	require.NoError(t, lookup.Add(productsID, "a-1"))
+	return lookup
+}
+
+func TestReferenceValidator_ValidateBatch(t *testing.T) {
+	lookup := &countingLookup{lookup: makeOrdersLookup(t)}
+	validator := makeOrdersValidator(t, lookup)
+
+	ctx := context.Background()
+	err := validator.ValidateBatch(ctx, [][]any{
+		{uint64(1), uint64(1), "a-1"},
+		{uint64(2), int64(2), nil},
+		{uint64(3), float64(1)},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, [][]any{{uint64(1), int64(2)}, {"a-1"}}, lookup.lookups)
+
+	err = validator.ValidateBatch(ctx, [][]any{
+		{uint64(4), uint64(1), "b-2"},
+		{uint64(5), uint64(3), "a-1"},
+		{uint64(6), uint64(3), "b-2"},
+		{uint64(7), uint64(2), "a-1"},
+	})
+	var batchErr *tupleconv.BatchError
+	require.ErrorAs(t, err, &batchErr)
+	assert.EqualError(t, err, `3 rows failed: `+
+		`row 0: field #3 ("sku"): value b-2 is not found in "products"."sku"; `+
+		`row 1: field #2 ("user_id"): value 3 is not found in "users"."id"; `+
+		`row 2: field #2 ("user_id"): value 3 is not found in "users"."id"`)
+
+	var refErr *tupleconv.ReferenceError
+	require.ErrorAs(t, batchErr.Errors[0], &refErr)
+	assert.Equal(t, &tupleconv.ReferenceError{
+		Field:      2,
+		Name:       "sku",
+		ForeignKey: productsID,
+		Value:      "b-2",
+	}, refErr)
+
+	// Cached values are not looked up again.
+	assert.Equal(t, [][]any{{uint64(1), int64(2)}, {"a-1"}, {uint64(3)}, {"b-2"}},
+		lookup.lookups)
+}
+
+func TestReferenceValidator_batchSize(t *testing.T) {
+	lookup := &countingLookup{lookup: makeOrdersLookup(t)}
+	validator := makeOrdersValidator(t, lookup).
+		WithBatchSize(2).WithCacheSize(0)
+
+	tuples := [][]any{
+		{uint64(1), uint64(1)},
+		{uint64(2), uint64(2)},
+		{uint64(3), uint64(1)},
+		{uint64(4), uint64(3)},
+	}
+	for i := 0; i < 2; i++ {
+		err := validator.ValidateBatch(context.Background(), tuples)
+		assert.EqualError(t, err,
+			`1 rows failed: row 3: field #2 ("user_id"): value 3 is not found in "users"."id"`)
+	}
+	// Without the cache values are looked up by each validation.
+	assert.Equal(t, [][]any{
+		{uint64(1), uint64(2)}, {uint64(3)},
+		{uint64(1), uint64(2)}, {uint64(3)},
+	}, lookup.lookups)
+}
+
+func TestReferenceValidator_cacheSize(t *testing.T) {
+	users := makeOrdersLookup(t)
+	require.NoError(t, users.Add(usersID, uint64(3)))
+	lookup := &countingLookup{lookup: users}
+	validator := makeOrdersValidator(t, lookup).WithCacheSize(2)
+
+	ctx := context.Background()
+	for _, id := range []uint64{1, 2, 1, 3, 1} {
+		require.NoError(t, validator.Validate(ctx, []any{uint64(0), id}))
+	}
+	// The least recently used value 2 is evicted, when 3 is cached.
+	assert.Equal(t, [][]any{{uint64(1)}, {uint64(2)}, {uint64(3)}}, lookup.lookups)
+
+	lookup.lookups = nil
+	for _, id := range []uint64{1, 3, 2} {
+		require.NoError(t, validator.Validate(ctx, []any{uint64(0), id}))
+	}
+	assert.Equal(t, [][]any{{uint64(2)}}, lookup.lookups)
+}
+
+func TestReferenceValidator_notFoundNotCached(t *testing.T) {
+	users := makeOrdersLookup(t)
+	lookup := &countingLookup{lookup: users}
+	validator := makeOrdersValidator(t, lookup)
+
+	ctx := context.Background()
+	assert.Error(t, validator.Validate(ctx, []any{uint64(0), uint64(5)}))
+	// The referenced tuple is inserted later during the import.
+	require.NoError(t, users.Add(usersID, uint64(5)))
+	assert.NoError(t, validator.Validate(ctx, []any{uint64(0), uint64(5)}))
+	assert.NoError(t, validator.Validate(ctx, []any{uint64(0), uint64(5)}))
+	assert.Equal(t, [][]any{{uint64(5)}, {uint64(5)}}, lookup.lookups)
+}
+
+func TestReferenceValidator_Transform(t *testing.T) {
+	validator := makeOrdersValidator(t, makeOrdersLookup(t))
+	converters, err := tupleconv.MakeTypeToTTConverters[string](
+		tupleconv.MakeStringToTTConvFactory(), ordersFormat)
+	require.NoError(t, err)
+	mapper := tupleconv.MakeMapper(converters).WithPostTransform(validator.Transform())
+
+	tuple, err := mapper.Map([]string{"1", "2", "a-1"})
+	require.NoError(t, err)
+	assert.Equal(t, []any{uint64(1), uint64(2), "a-1"}, tuple)
+
+	_, err = mapper.Map([]string{"1", "5", ""})
+	var refErr *tupleconv.ReferenceError
+	require.ErrorAs(t, err, &refErr)
+	assert.EqualError(t, refErr,
+		`field #2 ("user_id"): value 5 is not found in "users"."id"`)
+}
+
+func TestReferenceValidator_lookupErrors(t *testing.T) {
+	lookupErr := errors.New("connection refused")
+	cases := []struct {
+		name   string
+		lookup tupleconv.LookupFunc
+		err    string
+	}{
+		{"error", func(context.Context, tupleconv.ForeignKey, []any) ([]bool, error) {
+			return nil, lookupErr
+		}, `lookup in "users"."id": connection refused`},
+		{"results", func(context.Context, tupleconv.ForeignKey, []any) ([]bool, error) {
+			return []bool{true, true}, nil
+		}, `lookup in "users"."id": 2 results for 1 values`},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			validator := makeOrdersValidator(t, tc.lookup)
+			err := validator.Validate(context.Background(), []any{uint64(1), uint64(1)})
+			assert.EqualError(t, err, tc.err)
+		})
+	}
+}
+
+func TestReferenceValidator_tupleForeignKey(t *testing.T) {
+	spaceFmt := []tupleconv.SpaceField{
+		{Name: "id", Type: tupleconv.TypeUnsigned},
+		{Name: "city", Type: tupleconv.TypeString, IsNullable: true},
+		{Name: "country", Type: tupleconv.TypeString},
+	}
+	cities := tupleconv.ForeignKey{Name: "city", SpaceId: 512, Fields: []tupleconv.ForeignKeyField{
+		{Local: tupleconv.FieldRef{Name: "country"}, Foreign: tupleconv.FieldRef{No: 0}},
+		{Local: tupleconv.FieldRef{Name: "city"}, Foreign: tupleconv.FieldRef{Name: "name"}},
+	}}
+	assert.Equal(t, `512.(#1, "name")`, cities.String())
+
+	lookup := tupleconv.MakeSetLookup()
+	require.NoError(t, lookup.Add(cities, []any{"DE", "Berlin"}, []any{"FR", "Paris"}))
+	validator, err := tupleconv.MakeReferenceValidator(spaceFmt, []tupleconv.ForeignKey{cities},
+		lookup)
+	require.NoError(t, err)
+
+	err = validator.ValidateBatch(context.Background(), [][]any{
+		{uint64(1), "Berlin", "DE"},
+		{uint64(2), nil, "IT"},
+		{uint64(3), "Paris", "DE"},
+	})
+	assert.EqualError(t, err, `1 rows failed: row 2: field #3 ("country"): `+
+		`value [DE Paris] is not found in 512.(#1, "name")`)
+}
+
+func TestMakeReferenceValidator_errors(t *testing.T) {
+	cases := []struct {
+		name string
+		key  tupleconv.ForeignKey
+		err  string
+	}{
+		{"no fields", tupleconv.ForeignKey{Name: "fk", Space: "users"},
+			`foreign key "fk": no fields`},
+		{"unknown name", tupleconv.ForeignKey{Name: "fk", Space: "users",
+			Fields: []tupleconv.ForeignKeyField{{Local: tupleconv.FieldRef{Name: "user"}}}},
+			`foreign key "fk": unknown field "user"`},
+		{"out of range", tupleconv.ForeignKey{Name: "fk", Space: "users",
+			Fields: []tupleconv.ForeignKeyField{{Local: tupleconv.FieldRef{No: 3}}}},
+			`foreign key "fk": unknown field #4`},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := tupleconv.MakeReferenceValidator(ordersFormat,
+				[]tupleconv.ForeignKey{tc.key}, tupleconv.MakeSetLookup())
+			assert.EqualError(t, err, tc.err)
+		})
+	}
+}
+
+func TestLookupFunc(t *testing.T) {
+	lookup := tupleconv.LookupFunc(func(ctx context.Context, key tupleconv.ForeignKey,
+		values []any) ([]bool, error) {
+		assert.Equal(t, usersID, key)
+		result := make([]bool, len(values))
+		for i, value := range values {
+			result[i] = value == uint64(1)
+		}
+		return result, nil
+	})
+	validator := makeOrdersValidator(t, lookup)
+	assert.NoError(t, validator.Validate(context.Background(), []any{uint64(1), uint64(1)}))
+	assert.Error(t, validator.Validate(context.Background(), []any{uint64(1), uint64(2)}))
+}
+
+func TestSetLookup_AddFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "users.txt")
+	require.NoError(t, os.WriteFile(path, []byte("1\n\n2\n"), 0o600))
+
+	fac := tupleconv.MakeStringToTTConvFactory()
+	lookup := tupleconv.MakeSetLookup()
+	require.NoError(t, lookup.AddFile(usersID, path, fac.GetUnsignedConverter()))
+
+	exists, err := lookup.Exists(context.Background(), usersID,
+		[]any{uint64(1), float64(2), uint64(3)})
+	require.NoError(t, err)
+	assert.Equal(t, []bool{true, true, false}, exists)
+
+	exists, err = lookup.Exists(context.Background(), productsID, []any{uint64(1)})
+	require.NoError(t, err)
+	assert.Equal(t, []bool{false}, exists)
+
+	require.NoError(t, os.WriteFile(path, []byte("1\nx\n"), 0o600))
+	err = lookup.AddFile(usersID, path, fac.GetUnsignedConverter())
+	assert.EqualError(t, err, path+`:2: strconv.ParseUint: parsing "x": invalid syntax`)
+
+	longLine := strings.Repeat("1", 1<<20+1)
+	require.NoError(t, os.WriteFile(path, []byte("1\n"+longLine+"\n"), 0o600))
+	err = lookup.AddFile(usersID, path, fac.GetUnsignedConverter())
+	assert.EqualError(t, err, path+`:2: line is longer than 1048576 bytes`)
+
+	tupleKey := tupleconv.ForeignKey{Space: "users", Fields: make([]tupleconv.ForeignKeyField, 2)}
+	err = lookup.AddFile(tupleKey, path, fac.GetUnsignedConverter())
+	assert.EqualError(t, err, `"users".(#1, #1): values of tuple foreign keys can't be read `+
+		"from files")
+
+	err = lookup.Add(usersID, struct{}{})
+	assert.EqualError(t, err, "unexpected value of type struct {}")
+}
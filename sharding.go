@@ -0,0 +1,304 @@
+package tupleconv
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"math"
+	"strconv"
+
+	"github.com/google/uuid"
+)
+
+// BucketIDFieldName is the name of the field, that stores the bucket id of
+// the tuple in a sharded space.
+const BucketIDFieldName = "bucket_id"
+
+// ShardingFunc computes the bucket id from 1 to bucketCount by the sharding key.
+type ShardingFunc func(key []any, bucketCount uint64) (uint64, error)
+
+var castagnoliTable = crc32.MakeTable(crc32.Castagnoli)
+
+// crc32Update updates the crc32 of tarantool `digest.crc32` by the data.
+// Tarantool calculates CRC-32C with the initial value 0xFFFFFFFF and without
+// the final inversion, unlike hash/crc32.
+func crc32Update(crc uint32, data []byte) uint32 {
+	return ^crc32.Update(^crc, castagnoliTable, data)
+}
+
+// bucketID computes the bucket id from the crc32 of the sharding key.
+func bucketID(crc uint32, bucketCount uint64) (uint64, error) {
+	if bucketCount == 0 {
+		return 0, fmt.Errorf("bucket count must be positive")
+	}
+	return uint64(crc)%bucketCount + 1, nil
+}
+
+// luaNumberString formats the number the same way, as `tostring` of LuaJIT.
+func luaNumberString(value float64) string {
+	switch {
+	case math.IsNaN(value):
+		return "nan"
+	case math.IsInf(value, 1):
+		return "inf"
+	case math.IsInf(value, -1):
+		return "-inf"
+	}
+	return strconv.FormatFloat(value, 'g', 14, 64)
+}
+
+// maxLuaInteger is the maximum absolute value of integers, that tarantool
+// decodes to Lua numbers. Greater values are decoded to int64_t or uint64_t
+// cdata.
+const maxLuaInteger = 1 << 53
+
+// strKeyPart returns the key part the same way, as `tostring` of the value,
+// decoded by tarantool, returns it.
+func strKeyPart(value any) ([]byte, error) {
+	switch value := value.(type) {
+	case string:
+		return []byte(value), nil
+	case []byte:
+		return value, nil
+	case bool:
+		return []byte(strconv.FormatBool(value)), nil
+	case int:
+		return strKeyPart(int64(value))
+	case int64:
+		if value >= 0 {
+			return strKeyPart(uint64(value))
+		}
+		if value < -maxLuaInteger {
+			return []byte(strconv.FormatInt(value, 10) + "LL"), nil
+		}
+		return []byte(luaNumberString(float64(value))), nil
+	case uint64:
+		if value > maxLuaInteger {
+			return []byte(strconv.FormatUint(value, 10) + "ULL"), nil
+		}
+		return []byte(luaNumberString(float64(value))), nil
+	case float64:
+		return []byte(luaNumberString(value)), nil
+	default:
+		return nil, fmt.Errorf("unsupported value %v of type %T", value, value)
+	}
+}
+
+// BucketIDStrCRC32 computes the bucket id the same way, as vshard
+// `router.bucket_id_strcrc32` does: by the crc32 of the key parts, converted
+// to strings by Lua `tostring`. Supported key parts are strings, varbinary,
+// booleans, integers and doubles.
+func BucketIDStrCRC32(key []any, bucketCount uint64) (uint64, error) {
+	crc := ^uint32(0)
+	for i, value := range key {
+		data, err := strKeyPart(value)
+		if err != nil {
+			return 0, fmt.Errorf("key part #%d: %w", i+1, err)
+		}
+		crc = crc32Update(crc, data)
+	}
+	return bucketID(crc, bucketCount)
+}
+
+// appendMsgpackInt appends the integer in the most compact MessagePack encoding.
+func appendMsgpackInt(buf []byte, value int64) []byte {
+	switch {
+	case value >= 0:
+		return appendMsgpackUint(buf, uint64(value))
+	case value >= -32:
+		return append(buf, byte(value))
+	case value >= math.MinInt8:
+		return append(buf, 0xd0, byte(value))
+	case value >= math.MinInt16:
+		return binary.BigEndian.AppendUint16(append(buf, 0xd1), uint16(value))
+	case value >= math.MinInt32:
+		return binary.BigEndian.AppendUint32(append(buf, 0xd2), uint32(value))
+	default:
+		return binary.BigEndian.AppendUint64(append(buf, 0xd3), uint64(value))
+	}
+}
+
+// appendMsgpackUint appends the unsigned integer in the most compact MessagePack
+// encoding.
+func appendMsgpackUint(buf []byte, value uint64) []byte {
+	switch {
+	case value <= math.MaxInt8:
+		return append(buf, byte(value))
+	case value <= math.MaxUint8:
+		return append(buf, 0xcc, byte(value))
+	case value <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(buf, 0xcd), uint16(value))
+	case value <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(buf, 0xce), uint32(value))
+	default:
+		return binary.BigEndian.AppendUint64(append(buf, 0xcf), value)
+	}
+}
+
+// mpKeyPart returns the key part the same way, as vshard hashes it in
+// `bucket_id_mpcrc32`: strings as is, other values as `msgpack.encode` of
+// the value, decoded by tarantool, returns it.
+func mpKeyPart(value any) ([]byte, error) {
+	switch value := value.(type) {
+	case string:
+		return []byte(value), nil
+	case nil:
+		return []byte{0xc0}, nil
+	case bool:
+		if value {
+			return []byte{0xc3}, nil
+		}
+		return []byte{0xc2}, nil
+	case int:
+		return appendMsgpackInt(nil, int64(value)), nil
+	case int64:
+		return appendMsgpackInt(nil, value), nil
+	case uint64:
+		return appendMsgpackUint(nil, value), nil
+	case float64:
+		// Lua numbers with integral values are encoded as integers.
+		if value == math.Trunc(value) && !math.IsInf(value, 0) {
+			if value >= 0 && value < math.Exp2(64) {
+				return appendMsgpackUint(nil, uint64(value)), nil
+			}
+			if value < 0 && value >= math.MinInt64 {
+				return appendMsgpackInt(nil, int64(value)), nil
+			}
+		}
+		return binary.BigEndian.AppendUint64([]byte{0xcb}, math.Float64bits(value)), nil
+	case uuid.UUID:
+		return append([]byte{0xd8, 0x02}, value[:]...), nil
+	default:
+		return nil, fmt.Errorf("unsupported value %v of type %T", value, value)
+	}
+}
+
+// BucketIDMpCRC32 computes the bucket id the same way, as vshard
+// `router.bucket_id_mpcrc32` does: by the crc32 of the string key parts and of
+// the MessagePack encoding of the other key parts. Supported key parts are
+// strings, nil, booleans, integers, doubles and uuids. Varbinary key parts are
+// not supported: recent tarantool versions pass them to Lua not as strings,
+// and their hash is not known.
+func BucketIDMpCRC32(key []any, bucketCount uint64) (uint64, error) {
+	crc := ^uint32(0)
+	for i, value := range key {
+		data, err := mpKeyPart(value)
+		if err != nil {
+			return 0, fmt.Errorf("key part #%d: %w", i+1, err)
+		}
+		crc = crc32Update(crc, data)
+	}
+	return bucketID(crc, bucketCount)
+}
+
+// Sharding computes bucket ids of tuples of a sharded space by the sharding key.
+type Sharding struct {
+	// spaceFmt is the space format.
+	spaceFmt []SpaceField
+	// keyPositions are positions of the sharding key fields.
+	keyPositions []int
+	// bucketPos is the position of the bucket id field.
+	bucketPos int
+	// bucketCount is the total bucket count of the cluster.
+	bucketCount uint64
+	// shardingFunc computes the bucket id by the sharding key.
+	shardingFunc ShardingFunc
+}
+
+// MakeSharding creates Sharding by the space format, the names of the sharding
+// key fields and the total bucket count. The bucket id is stored in the field,
+// named `bucket_id`. By default, BucketIDStrCRC32 is used, like in crud and ddl.
+func MakeSharding(spaceFmt []SpaceField, shardingKey []string,
+	bucketCount uint64) (Sharding, error) {
+	if bucketCount == 0 {
+		return Sharding{}, fmt.Errorf("bucket count must be positive")
+	}
+	if len(shardingKey) == 0 {
+		return Sharding{}, fmt.Errorf("sharding key is empty")
+	}
+	names, err := MakeFieldNames(spaceFmt)
+	if err != nil {
+		return Sharding{}, err
+	}
+	bucketPos, ok := names.Position(BucketIDFieldName)
+	if !ok {
+		return Sharding{}, fmt.Errorf("field %q is not found in the space format",
+			BucketIDFieldName)
+	}
+	keyPositions := make([]int, len(shardingKey))
+	for i, name := range shardingKey {
+		pos, ok := names.Position(name)
+		if !ok {
+			return Sharding{}, fmt.Errorf("sharding key field %q is not found "+
+				"in the space format", name)
+		}
+		if pos == bucketPos {
+			return Sharding{}, fmt.Errorf("sharding key contains field %q",
+				BucketIDFieldName)
+		}
+		if containsPosition(keyPositions[:i], pos) {
+			return Sharding{}, fmt.Errorf("duplicate sharding key field %q", name)
+		}
+		keyPositions[i] = pos
+	}
+	return Sharding{
+		spaceFmt:     spaceFmt,
+		keyPositions: keyPositions,
+		bucketPos:    bucketPos,
+		bucketCount:  bucketCount,
+		shardingFunc: BucketIDStrCRC32,
+	}, nil
+}
+
+// WithShardingFunc sets the function, that computes the bucket id by the
+// sharding key, for example, BucketIDMpCRC32.
+func (sharding Sharding) WithShardingFunc(shardingFunc ShardingFunc) Sharding {
+	sharding.shardingFunc = shardingFunc
+	return sharding
+}
+
+// BucketID computes the bucket id of the converted tuple.
+func (sharding Sharding) BucketID(tuple []any) (uint64, error) {
+	key := make([]any, len(sharding.keyPositions))
+	for i, pos := range sharding.keyPositions {
+		if pos >= len(tuple) {
+			return 0, fmt.Errorf("field #%d (%q) is missing", pos+1,
+				sharding.spaceFmt[pos].Name)
+		}
+		key[i] = tuple[pos]
+	}
+	id, err := sharding.shardingFunc(key, sharding.bucketCount)
+	if err != nil {
+		return 0, fmt.Errorf("sharding key: %w", err)
+	}
+	return id, nil
+}
+
+// GeneratedField returns the bucket id field for MakeTypeToTTMapper, that is
+// generated by the sharding key.
+func (sharding Sharding) GeneratedField() GeneratedField {
+	return GeneratedField{
+		Pos: sharding.bucketPos,
+		Generator: MakeFuncConverter(func(tuple []any) (any, error) {
+			return sharding.BucketID(tuple)
+		}),
+	}
+}
+
+// Transform returns the tuple transform, that fills in the bucket id field of
+// the converted tuple. The previous value of the field is replaced.
+func (sharding Sharding) Transform() Converter[[]any, []any] {
+	return MakeFuncConverter(func(tuple []any) ([]any, error) {
+		if sharding.bucketPos >= len(tuple) {
+			return nil, fmt.Errorf("field #%d (%q) is missing", sharding.bucketPos+1,
+				BucketIDFieldName)
+		}
+		id, err := sharding.BucketID(tuple)
+		if err != nil {
+			return nil, err
+		}
+		result := append([]any{}, tuple...)
+		result[sharding.bucketPos] = id
+		return result, nil
+	})
+}
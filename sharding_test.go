@@ -0,0 +1,228 @@
+package tupleconv_test
+
+import (
+	"context"
+	"math"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tarantool/go-tarantool/v2"
+	"github.com/tarantool/go-tarantool/v2/test_helpers"
+
+	"github.com/tarantool/go-tupleconv"
+)
+
+// vshardKeys are the sharding keys, that TestBucketID_vshard computes by
+// vshard routers.
+var vshardKeys = [][]any{
+	{"abc"},
+	{""},
+	{"привет"},
+	{uint64(0)},
+	{uint64(1)},
+	{uint64(18374927634039)},
+	{uint64(123456789012345)},
+	{uint64(9007199254740992)},
+	{uint64(9007199254740993)},
+	{uint64(math.MaxUint64)},
+	{int64(-1)},
+	{int64(-100)},
+	{int64(-40000)},
+	{int64(-9007199254740993)},
+	{0.1},
+	{1.5},
+	{2.0},
+	{-1e300},
+	{true},
+	{false},
+	{uint64(1), "abc"},
+	{"user", int64(-7), true},
+}
+
+// vshardMpKeys are the sharding keys, that only BucketIDMpCRC32 supports.
+var vshardMpKeys = [][]any{
+	{nil},
+	{uuid.MustParse("c8f0fa1f-da29-438c-a040-393f1126ad39")},
+	{"a", nil, uuid.MustParse("00000000-0000-0000-0000-000000000001")},
+}
+
+// vshardEnv is the environment variable, that enables TestBucketID_vshard.
+// tarantool and vshard must be installed, vshard into the rocks of
+// the repository:
+//
+//	tarantoolctl rocks install vshard
+//	TEST_TNT_VSHARD=1 go test -run TestBucketID_vshard
+const vshardEnv = "TEST_TNT_VSHARD"
+
+// TestBucketID_vshard compares bucket ids with the bucket ids, computed by
+// vshard_bucket_id of testdata/config.lua in tarantool. The keys are sent
+// the same way, as tuples are inserted by the connector. Without the test
+// BucketIDStrCRC32 and BucketIDMpCRC32 are not verified against vshard.
+func TestBucketID_vshard(t *testing.T) {
+	if os.Getenv(vshardEnv) == "" {
+		t.Skipf("%s is not set", vshardEnv)
+	}
+	inst, err := test_helpers.StartTarantool(test_helpers.StartOpts{
+		Dialer:       dialer,
+		InitScript:   "testdata/config.lua",
+		Listen:       server,
+		WorkDir:      workDir,
+		WaitStart:    100 * time.Millisecond,
+		ConnectRetry: 3,
+		RetryTimeout: 500 * time.Millisecond,
+	})
+	defer test_helpers.StopTarantoolWithCleanup(inst)
+	require.NoError(t, err)
+	conn, err := tarantool.Connect(context.Background(), dialer, opts)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	check := func(name string, shardingFunc tupleconv.ShardingFunc, keys [][]any) {
+		for _, key := range keys {
+			for _, bucketCount := range []uint64{1, 3000, 30000} {
+				req := tarantool.NewCallRequest("vshard_bucket_id").
+					Args([]any{name, key, bucketCount})
+				var ids []uint64
+				require.NoError(t, conn.Do(req).GetTyped(&ids))
+				require.Len(t, ids, 1)
+				id, err := shardingFunc(key, bucketCount)
+				require.NoError(t, err)
+				assert.Equal(t, ids[0], id, "%s %v %d", name, key, bucketCount)
+			}
+		}
+	}
+	check("strcrc32", tupleconv.BucketIDStrCRC32, vshardKeys)
+	check("mpcrc32", tupleconv.BucketIDMpCRC32, append(vshardKeys, vshardMpKeys...))
+}
+
+func TestBucketID(t *testing.T) {
+	for name, shardingFunc := range map[string]tupleconv.ShardingFunc{
+		"strcrc32": tupleconv.BucketIDStrCRC32,
+		"mpcrc32":  tupleconv.BucketIDMpCRC32,
+	} {
+		t.Run(name, func(t *testing.T) {
+			// Integers of different types and integral doubles are equal.
+			expected, err := shardingFunc([]any{uint64(42)}, 3000)
+			require.NoError(t, err)
+			for _, value := range []any{int64(42), 42, float64(42)} {
+				id, err := shardingFunc([]any{value}, 3000)
+				require.NoError(t, err)
+				assert.Equal(t, expected, id)
+			}
+
+			_, err = shardingFunc([]any{"key"}, 0)
+			assert.EqualError(t, err, "bucket count must be positive")
+			_, err = shardingFunc([]any{"key", map[string]any{}}, 3000)
+			assert.EqualError(t, err, "key part #2: unsupported value map[] of type "+
+				"map[string]interface {}")
+		})
+	}
+
+	// Binary and string key parts are equal.
+	id, err := tupleconv.BucketIDStrCRC32([]any{[]byte("key")}, 3000)
+	require.NoError(t, err)
+	expected, err := tupleconv.BucketIDStrCRC32([]any{"key"}, 3000)
+	require.NoError(t, err)
+	assert.Equal(t, expected, id)
+	_, err = tupleconv.BucketIDMpCRC32([]any{[]byte("key")}, 3000)
+	assert.EqualError(t, err, "key part #1: unsupported value [107 101 121] of type []uint8")
+
+	id, err = tupleconv.BucketIDStrCRC32([]any{math.Inf(1)}, 3000)
+	require.NoError(t, err)
+	expected, err = tupleconv.BucketIDStrCRC32([]any{"inf"}, 3000)
+	require.NoError(t, err)
+	assert.Equal(t, expected, id)
+
+	_, err = tupleconv.BucketIDStrCRC32([]any{nil}, 3000)
+	assert.EqualError(t, err, "key part #1: unsupported value <nil> of type <nil>")
+}
+
+// shardedFormat is the format of a sharded space.
+var shardedFormat = []tupleconv.SpaceField{
+	{Name: "id", Type: tupleconv.TypeUnsigned},
+	{Name: "bucket_id", Type: tupleconv.TypeUnsigned},
+	{Name: "region", Type: tupleconv.TypeString},
+	{Name: "name", Type: tupleconv.TypeString},
+}
+
+func TestMakeSharding(t *testing.T) {
+	cases := []struct {
+		name        string
+		spaceFmt    []tupleconv.SpaceField
+		key         []string
+		bucketCount uint64
+		err         string
+	}{
+		{"zero bucket count", shardedFormat, []string{"id"}, 0,
+			"bucket count must be positive"},
+		{"empty key", shardedFormat, nil, 3000, "sharding key is empty"},
+		{"no bucket id", shardedFormat[2:], []string{"name"}, 3000,
+			`field "bucket_id" is not found in the space format`},
+		{"unknown field", shardedFormat, []string{"id", "unknown"}, 3000,
+			`sharding key field "unknown" is not found in the space format`},
+		{"bucket id in key", shardedFormat, []string{"bucket_id"}, 3000,
+			`sharding key contains field "bucket_id"`},
+		{"duplicate field", shardedFormat, []string{"id", "id"}, 3000,
+			`duplicate sharding key field "id"`},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := tupleconv.MakeSharding(tc.spaceFmt, tc.key, tc.bucketCount)
+			assert.EqualError(t, err, tc.err)
+		})
+	}
+}
+
+func TestSharding(t *testing.T) {
+	sharding, err := tupleconv.MakeSharding(shardedFormat, []string{"region", "id"}, 3000)
+	require.NoError(t, err)
+	strID, err := tupleconv.BucketIDStrCRC32([]any{"eu", uint64(1)}, 3000)
+	require.NoError(t, err)
+	mpID, err := tupleconv.BucketIDMpCRC32([]any{"eu", uint64(1)}, 3000)
+	require.NoError(t, err)
+	require.NotEqual(t, strID, mpID)
+
+	t.Run("bucket id", func(t *testing.T) {
+		id, err := sharding.BucketID([]any{uint64(1), nil, "eu", "alice"})
+		require.NoError(t, err)
+		assert.Equal(t, strID, id)
+
+		id, err = sharding.WithShardingFunc(tupleconv.BucketIDMpCRC32).
+			BucketID([]any{uint64(1), nil, "eu", "alice"})
+		require.NoError(t, err)
+		assert.Equal(t, mpID, id)
+
+		_, err = sharding.BucketID([]any{uint64(1), nil})
+		assert.EqualError(t, err, `field #3 ("region") is missing`)
+		_, err = sharding.BucketID([]any{map[string]any{}, nil, "eu"})
+		assert.EqualError(t, err, "sharding key: key part #2: unsupported value map[] "+
+			"of type map[string]interface {}")
+	})
+
+	t.Run("transform", func(t *testing.T) {
+		fac := tupleconv.MakeStringToTTConvFactory()
+		converters, err := tupleconv.MakeTypeToTTConverters[string](fac, shardedFormat)
+		require.NoError(t, err)
+		mapper := tupleconv.MakeMapper(converters).WithPostTransform(sharding.Transform())
+		tuple, err := mapper.Map([]string{"1", "0", "eu", "alice"})
+		require.NoError(t, err)
+		assert.Equal(t, []any{uint64(1), strID, "eu", "alice"}, tuple)
+
+		_, err = sharding.Transform().Convert([]any{uint64(1)})
+		assert.EqualError(t, err, `field #2 ("bucket_id") is missing`)
+	})
+
+	t.Run("generated field", func(t *testing.T) {
+		fac := tupleconv.MakeStringToTTConvFactory()
+		mapper, err := tupleconv.MakeTypeToTTMapper[string](fac, shardedFormat,
+			sharding.GeneratedField())
+		require.NoError(t, err)
+		tuple, err := mapper.Map([]string{"1", "eu", "alice"})
+		require.NoError(t, err)
+		assert.Equal(t, []any{uint64(1), strID, "eu", "alice"}, tuple)
+	})
+}
@@ -0,0 +1,283 @@
+package tupleconv
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// MappingSpec is a declarative specification of a Mapper from strings to tarantool
+// types. It can be loaded from JSON or YAML.
+type MappingSpec struct {
+	// Factory are options of StringToTTConvFactory.
+	Factory FactorySpec `json:"factory,omitempty" yaml:"factory"`
+	// Columns are specifications of the tuple columns in order.
+	Columns []ColumnSpec `json:"columns" yaml:"columns"`
+	// DefaultType is the type of columns beyond Columns. If it is empty,
+	// such columns are errors.
+	DefaultType TypeName `json:"default_type,omitempty" yaml:"default_type"`
+}
+
+// FactorySpec is a specification of StringToTTConvFactory options.
+// Unset options have default values.
+type FactorySpec struct {
+	// ThousandSeparators are thousands separators for numeric types.
+	ThousandSeparators *string `json:"thousand_separators,omitempty" yaml:"thousand_separators"`
+	// DecimalSeparators are additional decimal separators for numeric types.
+	DecimalSeparators *string `json:"decimal_separators,omitempty" yaml:"decimal_separators"`
+	// NullValues are values, that are interpreted as null.
+	NullValues []string `json:"null_values,omitempty" yaml:"null_values"`
+	// DatetimeLayouts are additional time layouts for datetime.
+	DatetimeLayouts []string `json:"datetime_layouts,omitempty" yaml:"datetime_layouts"`
+	// Normalization are options of the string normalization.
+	Normalization NormalizeOptions `json:"normalization,omitempty" yaml:"normalization"`
+}
+
+// ColumnSpec is a specification of a tuple column.
+type ColumnSpec struct {
+	// Name is the field name.
+	Name string `json:"name,omitempty" yaml:"name"`
+	// Type is the field type.
+	Type TypeName `json:"type" yaml:"type"`
+	// IsNullable is true for nullable fields.
+	IsNullable bool `json:"is_nullable,omitempty" yaml:"is_nullable"`
+	// Collation is the field collation.
+	Collation string `json:"collation,omitempty" yaml:"collation"`
+	// NullValues override the factory null values for the column.
+	NullValues []string `json:"null_values,omitempty" yaml:"null_values"`
+	// Normalization overrides the factory normalization options for the column.
+	Normalization *NormalizeOptions `json:"normalization,omitempty" yaml:"normalization"`
+	// Transforms are names of registered string transforms, that are applied
+	// in order before the conversion.
+	Transforms []string `json:"transforms,omitempty" yaml:"transforms"`
+	// Converter is the name of a registered converter, that is used instead of
+	// the factory converter for the column type.
+	Converter string `json:"converter,omitempty" yaml:"converter"`
+}
+
+// SpaceField returns the space field, described by the column.
+func (column ColumnSpec) SpaceField() SpaceField {
+	return SpaceField{
+		Name:       column.Name,
+		Type:       column.Type,
+		IsNullable: column.IsNullable,
+		Collation:  column.Collation,
+	}
+}
+
+// ParseMappingSpec parses MappingSpec from JSON or YAML and validates it.
+// Unknown keys are errors.
+func ParseMappingSpec(data []byte) (MappingSpec, error) {
+	var spec MappingSpec
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&spec); err != nil {
+		return MappingSpec{}, fmt.Errorf("can't parse mapping spec: %w", err)
+	}
+	if err := spec.validate(); err != nil {
+		return MappingSpec{}, err
+	}
+	return spec, nil
+}
+
+// LoadMappingSpec parses MappingSpec from JSON or YAML and builds a Mapper from it.
+// All errors of the specification are reported on load.
+func LoadMappingSpec(data []byte, registry ConverterRegistry) (Mapper[string, any], error) {
+	spec, err := ParseMappingSpec(data)
+	if err != nil {
+		return Mapper[string, any]{}, err
+	}
+	return spec.Build(registry)
+}
+
+// SpaceFormat returns the space format, described by the specification.
+func (spec MappingSpec) SpaceFormat() []SpaceField {
+	spaceFmt := make([]SpaceField, len(spec.Columns))
+	for i, column := range spec.Columns {
+		spaceFmt[i] = column.SpaceField()
+	}
+	return spaceFmt
+}
+
+// columnError returns an error about the column.
+func columnError(pos int, column ColumnSpec, format string, args ...any) error {
+	return fmt.Errorf("column #%d (%q): %s", pos+1, column.Name, fmt.Sprintf(format, args...))
+}
+
+// validate validates the specification without the registry.
+func (spec MappingSpec) validate() error {
+	if spec.DefaultType != "" && !isKnownType(spec.DefaultType) {
+		return fmt.Errorf("unexpected default type: %s", spec.DefaultType)
+	}
+	names := make(map[string]struct{}, len(spec.Columns))
+	for i, column := range spec.Columns {
+		if !isKnownType(column.Type) {
+			return columnError(i, column, "unexpected type: %s", column.Type)
+		}
+		if column.Name == "" {
+			continue
+		}
+		if _, ok := names[column.Name]; ok {
+			return columnError(i, column, "duplicate column name")
+		}
+		names[column.Name] = struct{}{}
+	}
+	return nil
+}
+
+// Validate validates the specification against the registry: all transforms and
+// converters must be registered, and converter types must match column types.
+func (spec MappingSpec) Validate(registry ConverterRegistry) error {
+	if err := spec.validate(); err != nil {
+		return err
+	}
+	for i, column := range spec.Columns {
+		for _, name := range column.Transforms {
+			if _, ok := registry.transforms[name]; !ok {
+				return columnError(i, column, "unknown transform %q", name)
+			}
+		}
+		if column.Converter == "" {
+			continue
+		}
+		registered, ok := registry.converters[column.Converter]
+		if !ok {
+			return columnError(i, column, "unknown converter %q", column.Converter)
+		}
+		if !isTypeAssignable(column.Type, registered.typ) {
+			return columnError(i, column, "converter %q produces %s, but %s is expected",
+				column.Converter, registered.typ, column.Type)
+		}
+	}
+	return nil
+}
+
+// makeFactory creates StringToTTConvFactory by the specification.
+func (spec FactorySpec) makeFactory() StringToTTConvFactory {
+	fac := MakeStringToTTConvFactory().
+		WithDatetimeLayouts(spec.DatetimeLayouts...).
+		WithNormalization(spec.Normalization)
+	if spec.ThousandSeparators != nil {
+		fac = fac.WithThousandSeparators(*spec.ThousandSeparators)
+	}
+	if spec.DecimalSeparators != nil {
+		fac = fac.WithDecimalSeparators(*spec.DecimalSeparators)
+	}
+	if spec.NullValues != nil {
+		fac = fac.WithNullValues(spec.NullValues...)
+	}
+	return fac
+}
+
+// Build validates the specification against the registry and builds a Mapper.
+func (spec MappingSpec) Build(registry ConverterRegistry) (Mapper[string, any], error) {
+	if err := spec.Validate(registry); err != nil {
+		return Mapper[string, any]{}, err
+	}
+	fac := spec.Factory.makeFactory()
+	converters := make([]Converter[string, any], len(spec.Columns))
+	for i, column := range spec.Columns {
+		columnFac := fac
+		if column.NullValues != nil {
+			columnFac = columnFac.WithFieldNullValues(column.Name, column.NullValues...)
+		}
+		if column.Normalization != nil {
+			columnFac = columnFac.WithFieldNormalization(column.Name, *column.Normalization)
+		}
+
+		var conv Converter[string, any]
+		if column.Converter != "" {
+			conv = registry.converters[column.Converter].converter
+		} else {
+			var err error
+			if conv, err = GetConverterByType[string](columnFac, column.Type); err != nil {
+				return Mapper[string, any]{}, columnError(i, column, "%s", err)
+			}
+		}
+		conv = makeFieldConverter[string](columnFac, column.SpaceField(), conv)
+		for j := len(column.Transforms) - 1; j >= 0; j-- {
+			conv = MakeChainConverter(registry.transforms[column.Transforms[j]], conv)
+		}
+		converters[i] = conv
+	}
+
+	mapper := MakeMapper(converters)
+	if spec.DefaultType != "" {
+		conv, err := GetConverterByType[string](fac, spec.DefaultType)
+		if err != nil {
+			return Mapper[string, any]{}, err
+		}
+		mapper = mapper.WithDefaultConverter(
+			makeFieldConverter[string](fac, SpaceField{Type: spec.DefaultType}, conv))
+	}
+	return mapper, nil
+}
+
+// registeredConverter is a converter in ConverterRegistry.
+type registeredConverter struct {
+	typ       TypeName
+	converter Converter[string, any]
+}
+
+// ConverterRegistry is a registry of named transforms and converters,
+// that can be referenced from MappingSpec.
+type ConverterRegistry struct {
+	transforms map[string]Converter[string, string]
+	converters map[string]registeredConverter
+}
+
+// Built-in transforms of ConverterRegistry.
+var builtinTransforms = map[string]NormalizeOptions{
+	"trim":            {Trim: true},
+	"collapse_spaces": {CollapseSpaces: true},
+	"nfc":             {Form: NormalizationNFC},
+	"nfkc":            {Form: NormalizationNFKC},
+	"strip_control":   {StripControl: true},
+	"strip_bom":       {StripBOM: true},
+	"fold_case":       {FoldCase: true},
+}
+
+// MakeConverterRegistry creates ConverterRegistry with built-in transforms:
+// `trim`, `collapse_spaces`, `nfc`, `nfkc`, `strip_control`, `strip_bom`,
+// `fold_case`, `lower` and `upper`.
+func MakeConverterRegistry() ConverterRegistry {
+	registry := ConverterRegistry{
+		transforms: make(map[string]Converter[string, string]),
+		converters: make(map[string]registeredConverter),
+	}
+	for name, opts := range builtinTransforms {
+		registry.transforms[name] = MakeStringNormalizer(opts)
+	}
+	registry.transforms["lower"] = MakeFuncConverter(func(src string) (string, error) {
+		return strings.ToLower(src), nil
+	})
+	registry.transforms["upper"] = MakeFuncConverter(func(src string) (string, error) {
+		return strings.ToUpper(src), nil
+	})
+	return registry
+}
+
+// RegisterTransform registers a named string transform.
+func (registry ConverterRegistry) RegisterTransform(
+	name string, transform Converter[string, string]) error {
+	if _, ok := registry.transforms[name]; ok {
+		return fmt.Errorf("transform %q is already registered", name)
+	}
+	registry.transforms[name] = transform
+	return nil
+}
+
+// RegisterConverter registers a named converter, that produces values of the type.
+func (registry ConverterRegistry) RegisterConverter(
+	name string, typ TypeName, converter Converter[string, any]) error {
+	if !isKnownType(typ) {
+		return fmt.Errorf("unexpected type: %s", typ)
+	}
+	if _, ok := registry.converters[name]; ok {
+		return fmt.Errorf("converter %q is already registered", name)
+	}
+	registry.converters[name] = registeredConverter{typ: typ, converter: converter}
+	return nil
+}
@@ -0,0 +1,168 @@
+package tupleconv_test
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tarantool/go-tupleconv"
+)
+
+const testYAMLSpec = `
+factory:
+  thousand_separators: " "
+  decimal_separators: ","
+  null_values: ["NULL"]
+columns:
+  - name: id
+    type: unsigned
+  - name: name
+    type: string
+    transforms: [trim, upper]
+  - name: score
+    type: double
+    is_nullable: true
+    null_values: ["n/a"]
+  - name: code
+    type: integer
+    converter: hex
+default_type: string
+`
+
+const testJSONSpec = `{
+  "factory": {"thousand_separators": " ", "decimal_separators": ",", "null_values": ["NULL"]},
+  "columns": [
+    {"name": "id", "type": "unsigned"},
+    {"name": "name", "type": "string", "transforms": ["trim", "upper"]},
+    {"name": "score", "type": "double", "is_nullable": true, "null_values": ["n/a"]},
+    {"name": "code", "type": "integer", "converter": "hex"}
+  ],
+  "default_type": "string"
+}`
+
+func makeTestRegistry(t *testing.T) tupleconv.ConverterRegistry {
+	registry := tupleconv.MakeConverterRegistry()
+	err := registry.RegisterConverter("hex", tupleconv.TypeUnsigned,
+		tupleconv.MakeFuncConverter(func(src string) (any, error) {
+			return strconv.ParseUint(strings.TrimPrefix(src, "0x"), 16, 64)
+		}))
+	require.NoError(t, err)
+	return registry
+}
+
+func TestLoadMappingSpec(t *testing.T) {
+	for name, data := range map[string]string{"yaml": testYAMLSpec, "json": testJSONSpec} {
+		t.Run(name, func(t *testing.T) {
+			mapper, err := tupleconv.LoadMappingSpec([]byte(data), makeTestRegistry(t))
+			require.NoError(t, err)
+
+			result, err := mapper.Map([]string{"1 000", " alice ", "1,5", "0x10", "extra"})
+			require.NoError(t, err)
+			assert.Equal(t, []any{uint64(1000), "ALICE", 1.5, uint64(16), "extra"}, result)
+
+			result, err = mapper.Map([]string{"1", "bob", "n/a", "0x1"})
+			require.NoError(t, err)
+			assert.Equal(t, []any{uint64(1), "BOB", nil, uint64(1)}, result)
+
+			// Column null values override the factory ones.
+			_, err = mapper.Map([]string{"1", "bob", "NULL", "0x1"})
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestMappingSpec_SpaceFormat(t *testing.T) {
+	spec, err := tupleconv.ParseMappingSpec([]byte(testYAMLSpec))
+	require.NoError(t, err)
+	assert.Equal(t, []tupleconv.SpaceField{
+		{Name: "id", Type: tupleconv.TypeUnsigned},
+		{Name: "name", Type: tupleconv.TypeString},
+		{Name: "score", Type: tupleconv.TypeDouble, IsNullable: true},
+		{Name: "code", Type: tupleconv.TypeInteger},
+	}, spec.SpaceFormat())
+}
+
+func TestMappingSpec_columnNormalization(t *testing.T) {
+	data := `
+factory:
+  normalization: {trim: true}
+columns:
+  - {name: a, type: string}
+  - {name: b, type: string, normalization: {collapse_spaces: true}}
+`
+	mapper, err := tupleconv.LoadMappingSpec([]byte(data), tupleconv.MakeConverterRegistry())
+	require.NoError(t, err)
+	result, err := mapper.Map([]string{" a  b ", " a  b "})
+	require.NoError(t, err)
+	assert.Equal(t, []any{"a  b", " a b "}, result)
+}
+
+func TestLoadMappingSpec_errors(t *testing.T) {
+	cases := []struct {
+		name       string
+		data       string
+		errMessage string
+	}{
+		{
+			name:       "unknown key",
+			data:       "columns:\n  - {name: a, type: string, nullable: true}",
+			errMessage: "can't parse mapping spec",
+		},
+		{
+			name:       "unknown type",
+			data:       "columns:\n  - {name: a, type: text}",
+			errMessage: `column #1 ("a"): unexpected type: text`,
+		},
+		{
+			name:       "unknown default type",
+			data:       "columns: []\ndefault_type: text",
+			errMessage: "unexpected default type: text",
+		},
+		{
+			name:       "duplicate name",
+			data:       "columns:\n  - {name: a, type: string}\n  - {name: a, type: string}",
+			errMessage: `column #2 ("a"): duplicate column name`,
+		},
+		{
+			name:       "unknown transform",
+			data:       "columns:\n  - {name: a, type: string, transforms: [reverse]}",
+			errMessage: `column #1 ("a"): unknown transform "reverse"`,
+		},
+		{
+			name:       "unknown converter",
+			data:       "columns:\n  - {name: a, type: string, converter: oct}",
+			errMessage: `column #1 ("a"): unknown converter "oct"`,
+		},
+		{
+			name: "incompatible converter",
+			data: "columns:\n  - {name: a, type: string, converter: hex}",
+			errMessage: `column #1 ("a"): converter "hex" produces unsigned, ` +
+				`but string is expected`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := tupleconv.LoadMappingSpec([]byte(tc.data), makeTestRegistry(t))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.errMessage)
+		})
+	}
+}
+
+func TestConverterRegistry_duplicates(t *testing.T) {
+	registry := makeTestRegistry(t)
+	err := registry.RegisterTransform("trim", tupleconv.MakeStringNormalizer(
+		tupleconv.NormalizeOptions{Trim: true}))
+	assert.EqualError(t, err, `transform "trim" is already registered`)
+
+	err = registry.RegisterConverter("hex", tupleconv.TypeUnsigned,
+		tupleconv.MakeStringToTTConvFactory().GetUnsignedConverter())
+	assert.EqualError(t, err, `converter "hex" is already registered`)
+
+	err = registry.RegisterConverter("bad", "text",
+		tupleconv.MakeStringToTTConvFactory().GetStringConverter())
+	assert.EqualError(t, err, "unexpected type: text")
+}
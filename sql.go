@@ -0,0 +1,439 @@
+package tupleconv
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// sqlDatetimeLayouts are layouts of date and time values in the text form,
+// returned by SQL drivers.
+var sqlDatetimeLayouts = []string{
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999Z07",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999Z07:00",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02",
+}
+
+// SQLToTTConvFactory is a TTConvFactory for values of database/sql: values of
+// driver.Valuer types, such as sql.NullString, are converted by their values,
+// []byte and string values in the text form are parsed by the converters of
+// StringToTTConvFactory, other values are converted by NativeToTTConvFactory.
+//
+// Additionally, 0 and 1 are accepted for boolean fields, 16 bytes are accepted for
+// uuid fields. Date and time values in the SQL text form are accepted for
+// datetime fields.
+type SQLToTTConvFactory struct {
+	// strFac is the factory of the converters of values in the text form.
+	strFac StringToTTConvFactory
+	// nativeFac is the factory of the converters of other values.
+	nativeFac NativeToTTConvFactory
+}
+
+// MakeSQLToTTConvFactory creates SQLToTTConvFactory.
+func MakeSQLToTTConvFactory() SQLToTTConvFactory {
+	return SQLToTTConvFactory{
+		strFac:    MakeStringToTTConvFactory().WithDatetimeLayouts(sqlDatetimeLayouts...),
+		nativeFac: MakeNativeToTTConvFactory(),
+	}
+}
+
+var _ TTConvFactory[any] = (*SQLToTTConvFactory)(nil)
+
+// WithStringFactory sets the factory of the converters of values in the text form.
+func (fac SQLToTTConvFactory) WithStringFactory(strFac StringToTTConvFactory) SQLToTTConvFactory {
+	fac.strFac = strFac
+	return fac
+}
+
+// sqlValue returns the value of the driver.Valuer value.
+func sqlValue(value any) (any, error) {
+	if valuer, ok := value.(driver.Valuer); ok {
+		return valuer.Value()
+	}
+	return value, nil
+}
+
+// makeSQLConverter makes a converter, that converts values in the text form with
+// the string converter and other values with the native converter.
+func makeSQLConverter(strConv Converter[string, any],
+	nativeConv Converter[any, any]) Converter[any, any] {
+	return MakeFuncConverter(func(value any) (any, error) {
+		value, err := sqlValue(value)
+		if err != nil {
+			return nil, err
+		}
+		switch value := value.(type) {
+		case []byte:
+			return strConv.Convert(string(value))
+		case sql.RawBytes:
+			return strConv.Convert(string(value))
+		case string:
+			return strConv.Convert(value)
+		}
+		return nativeConv.Convert(value)
+	})
+}
+
+func (fac SQLToTTConvFactory) GetBooleanConverter() Converter[any, any] {
+	boolConv := makeSQLConverter(fac.strFac.GetBooleanConverter(),
+		fac.nativeFac.GetBooleanConverter())
+	return MakeFuncConverter(func(value any) (any, error) {
+		value, err := sqlValue(value)
+		if err != nil {
+			return nil, err
+		}
+		switch value {
+		case int64(0):
+			return false, nil
+		case int64(1):
+			return true, nil
+		}
+		return boolConv.Convert(value)
+	})
+}
+
+func (fac SQLToTTConvFactory) GetStringConverter() Converter[any, any] {
+	return makeSQLConverter(fac.strFac.GetStringConverter(), fac.nativeFac.GetStringConverter())
+}
+
+func (fac SQLToTTConvFactory) GetUnsignedConverter() Converter[any, any] {
+	return makeSQLConverter(fac.strFac.GetUnsignedConverter(),
+		fac.nativeFac.GetUnsignedConverter())
+}
+
+func (fac SQLToTTConvFactory) GetDatetimeConverter() Converter[any, any] {
+	return makeSQLConverter(fac.strFac.GetDatetimeConverter(),
+		fac.nativeFac.GetDatetimeConverter())
+}
+
+func (fac SQLToTTConvFactory) GetUUIDConverter() Converter[any, any] {
+	uuidConv := makeSQLConverter(fac.strFac.GetUUIDConverter(), fac.nativeFac.GetUUIDConverter())
+	return MakeFuncConverter(func(value any) (any, error) {
+		value, err := sqlValue(value)
+		if err != nil {
+			return nil, err
+		}
+		if raw, ok := value.(sql.RawBytes); ok {
+			value = []byte(raw)
+		}
+		if bytes, ok := value.([]byte); ok && len(bytes) == len(uuid.UUID{}) {
+			return uuid.FromBytes(bytes)
+		}
+		return uuidConv.Convert(value)
+	})
+}
+
+// makeJSONConverter makes a converter of JSON text, that converts it like
+// AnyToTTConvFactory converts strings.
+func makeJSONConverter(anyConv Converter[any, any]) Converter[string, any] {
+	return MakeFuncConverter(func(value string) (any, error) {
+		return anyConv.Convert(value)
+	})
+}
+
+// GetMapConverter returns a converter to map. Values in the text form are parsed
+// as JSON, numbers are converted like numbers of number fields.
+func (fac SQLToTTConvFactory) GetMapConverter() Converter[any, any] {
+	anyFac := MakeAnyToTTConvFactory(fac.strFac)
+	return makeSQLConverter(makeJSONConverter(anyFac.GetMapConverter()),
+		fac.nativeFac.GetMapConverter())
+}
+
+// GetArrayConverter returns a converter to array. Values in the text form are
+// parsed as JSON, numbers are converted like numbers of number fields.
+func (fac SQLToTTConvFactory) GetArrayConverter() Converter[any, any] {
+	anyFac := MakeAnyToTTConvFactory(fac.strFac)
+	return makeSQLConverter(makeJSONConverter(anyFac.GetArrayConverter()),
+		fac.nativeFac.GetArrayConverter())
+}
+
+// GetVarbinaryConverter returns a converter to varbinary. Byte slices are copied,
+// because drivers may reuse them.
+func (fac SQLToTTConvFactory) GetVarbinaryConverter() Converter[any, any] {
+	return MakeFuncConverter(func(value any) (any, error) {
+		value, err := sqlValue(value)
+		if err != nil {
+			return nil, err
+		}
+		switch value := value.(type) {
+		case []byte:
+			return append([]byte{}, value...), nil
+		case sql.RawBytes:
+			return append([]byte{}, value...), nil
+		case string:
+			return []byte(value), nil
+		}
+		return fac.nativeFac.GetVarbinaryConverter().Convert(value)
+	})
+}
+
+func (fac SQLToTTConvFactory) GetDoubleConverter() Converter[any, any] {
+	return makeSQLConverter(fac.strFac.GetDoubleConverter(), fac.nativeFac.GetDoubleConverter())
+}
+
+func (fac SQLToTTConvFactory) GetDecimalConverter() Converter[any, any] {
+	return makeSQLConverter(fac.strFac.GetDecimalConverter(),
+		fac.nativeFac.GetDecimalConverter())
+}
+
+func (fac SQLToTTConvFactory) GetIntegerConverter() Converter[any, any] {
+	return makeSQLConverter(fac.strFac.GetIntegerConverter(),
+		fac.nativeFac.GetIntegerConverter())
+}
+
+func (fac SQLToTTConvFactory) GetNumberConverter() Converter[any, any] {
+	return makeSQLConverter(fac.strFac.GetNumberConverter(), fac.nativeFac.GetNumberConverter())
+}
+
+// GetAnyConverter returns a converter to any. Values in the text form are parsed
+// as JSON maps or arrays, if they are, or like values of scalar fields.
+func (fac SQLToTTConvFactory) GetAnyConverter() Converter[any, any] {
+	anyFac := MakeAnyToTTConvFactory(fac.strFac)
+	return makeSQLConverter(MakeSequenceConverter([]Converter[string, any]{
+		makeJSONConverter(anyFac.GetMapConverter()),
+		makeJSONConverter(anyFac.GetArrayConverter()),
+		fac.strFac.GetAnyConverter(),
+	}), fac.nativeFac.GetAnyConverter())
+}
+
+func (fac SQLToTTConvFactory) GetScalarConverter() Converter[any, any] {
+	return makeSQLConverter(fac.strFac.GetScalarConverter(), fac.nativeFac.GetScalarConverter())
+}
+
+func (fac SQLToTTConvFactory) GetIntervalConverter() Converter[any, any] {
+	return makeSQLConverter(fac.strFac.GetIntervalConverter(),
+		fac.nativeFac.GetIntervalConverter())
+}
+
+// MakeNullableConverter extends the converter to a converter, that converts NULL
+// to nil. Null values of the string factory are not used, NULL is never passed in
+// the text form.
+func (SQLToTTConvFactory) MakeNullableConverter(
+	converter Converter[any, any]) Converter[any, any] {
+	return MakeFuncContextConverter(func(ctx context.Context, value any) (any, error) {
+		if _, ok := derefNative(value); !ok {
+			return nil, nil
+		}
+		if valuer, ok := value.(driver.Valuer); ok {
+			if result, err := valuer.Value(); err == nil && result == nil {
+				return nil, nil
+			}
+		}
+		return convertContext(ctx, converter, value)
+	})
+}
+
+// sqlTypes are tarantool types of SQL types by the database type names.
+var sqlTypes = map[string]TypeName{
+	"BOOL":    TypeBoolean,
+	"BOOLEAN": TypeBoolean,
+
+	"TINYINT":   TypeInteger,
+	"SMALLINT":  TypeInteger,
+	"MEDIUMINT": TypeInteger,
+	"INT":       TypeInteger,
+	"INTEGER":   TypeInteger,
+	"BIGINT":    TypeInteger,
+	"INT2":      TypeInteger,
+	"INT4":      TypeInteger,
+	"INT8":      TypeInteger,
+	"SERIAL":    TypeInteger,
+	"BIGSERIAL": TypeInteger,
+
+	"REAL":             TypeDouble,
+	"FLOAT":            TypeDouble,
+	"FLOAT4":           TypeDouble,
+	"FLOAT8":           TypeDouble,
+	"DOUBLE":           TypeDouble,
+	"DOUBLE PRECISION": TypeDouble,
+
+	"NUMERIC": TypeDecimal,
+	"DECIMAL": TypeDecimal,
+
+	"CHAR":       TypeString,
+	"VARCHAR":    TypeString,
+	"NCHAR":      TypeString,
+	"NVARCHAR":   TypeString,
+	"BPCHAR":     TypeString,
+	"TEXT":       TypeString,
+	"TINYTEXT":   TypeString,
+	"MEDIUMTEXT": TypeString,
+	"LONGTEXT":   TypeString,
+	"CITEXT":     TypeString,
+	"NAME":       TypeString,
+	"ENUM":       TypeString,
+
+	"BYTEA":      TypeVarbinary,
+	"BINARY":     TypeVarbinary,
+	"VARBINARY":  TypeVarbinary,
+	"BLOB":       TypeVarbinary,
+	"TINYBLOB":   TypeVarbinary,
+	"MEDIUMBLOB": TypeVarbinary,
+	"LONGBLOB":   TypeVarbinary,
+
+	"DATE":        TypeDatetime,
+	"DATETIME":    TypeDatetime,
+	"TIMESTAMP":   TypeDatetime,
+	"TIMESTAMPTZ": TypeDatetime,
+
+	"UUID": TypeUUID,
+
+	"JSON":  TypeAny,
+	"JSONB": TypeAny,
+}
+
+// sqlScanTypes are tarantool types by the kinds of the scan types.
+var sqlScanTypes = map[reflect.Kind]TypeName{
+	reflect.Bool:    TypeBoolean,
+	reflect.Int:     TypeInteger,
+	reflect.Int8:    TypeInteger,
+	reflect.Int16:   TypeInteger,
+	reflect.Int32:   TypeInteger,
+	reflect.Int64:   TypeInteger,
+	reflect.Uint:    TypeUnsigned,
+	reflect.Uint8:   TypeUnsigned,
+	reflect.Uint16:  TypeUnsigned,
+	reflect.Uint32:  TypeUnsigned,
+	reflect.Uint64:  TypeUnsigned,
+	reflect.Float32: TypeDouble,
+	reflect.Float64: TypeDouble,
+}
+
+// sqlColumnType returns the proposed tarantool type of the SQL column.
+func sqlColumnType(column *sql.ColumnType) TypeName {
+	name := strings.ToUpper(strings.TrimSpace(column.DatabaseTypeName()))
+	if unsigned := strings.TrimPrefix(name, "UNSIGNED "); unsigned != name {
+		if typ := sqlTypes[unsigned]; typ == TypeInteger {
+			return TypeUnsigned
+		}
+	}
+	if typ, ok := sqlTypes[name]; ok {
+		return typ
+	}
+	// Array types of PostgreSQL are prefixed with "_". Drivers return them as
+	// array literals like {1,2,3}, that are not parsed, so they are kept as
+	// strings.
+	if strings.HasPrefix(name, "_") {
+		return TypeString
+	}
+	if scanType := column.ScanType(); scanType != nil {
+		if scanType == reflect.TypeOf(time.Time{}) {
+			return TypeDatetime
+		}
+		if typ, ok := sqlScanTypes[scanType.Kind()]; ok {
+			return typ
+		}
+	}
+	return TypeString
+}
+
+// SpaceFormatFromSQL proposes a space format for the SQL columns. Field types are
+// chosen by the database type names and, if they are unknown, by the scan types
+// of the columns, the string type is proposed for unknown columns. Columns are
+// nullable, unless the driver reports they are not.
+func SpaceFormatFromSQL(columns []*sql.ColumnType) []SpaceField {
+	spaceFmt := make([]SpaceField, len(columns))
+	for i, column := range columns {
+		nullable, ok := column.Nullable()
+		spaceFmt[i] = SpaceField{
+			Name:       column.Name(),
+			Type:       sqlColumnType(column),
+			IsNullable: nullable || !ok,
+		}
+	}
+	return spaceFmt
+}
+
+// SQLImporter reads rows of sql.Rows and converts them to tuples by the space
+// format.
+type SQLImporter struct {
+	rows       *sql.Rows
+	names      []string
+	converters []Converter[any, any]
+	// values are the scanned values of the current row.
+	values []any
+	// dest are pointers to values.
+	dest []any
+	// row is the number of the current row, starting from 1.
+	row int
+}
+
+// MakeSQLImporter creates SQLImporter with the converters, made by the factory for
+// the space format. The number of columns must be equal to the number of fields.
+func MakeSQLImporter(rows *sql.Rows, fac TTConvFactory[any],
+	spaceFmt []SpaceField) (*SQLImporter, error) {
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	if len(columns) != len(spaceFmt) {
+		return nil, fmt.Errorf("%d columns, but %d fields in the space format",
+			len(columns), len(spaceFmt))
+	}
+	converters, err := MakeTypeToTTConverters(fac, spaceFmt)
+	if err != nil {
+		return nil, err
+	}
+	importer := &SQLImporter{
+		rows:       rows,
+		names:      columns,
+		converters: converters,
+		values:     make([]any, len(columns)),
+		dest:       make([]any, len(columns)),
+	}
+	for i := range importer.values {
+		importer.dest[i] = &importer.values[i]
+	}
+	return importer, nil
+}
+
+// ReadTuple reads the next row and converts it to a tuple. io.EOF is returned,
+// when there are no more rows. Conversion errors are returned as *RecordError.
+func (importer *SQLImporter) ReadTuple() ([]any, error) {
+	if !importer.rows.Next() {
+		if err := importer.rows.Err(); err != nil {
+			return nil, err
+		}
+		return nil, io.EOF
+	}
+	importer.row++
+	if err := importer.rows.Scan(importer.dest...); err != nil {
+		return nil, &RecordError{Record: importer.row, Err: err}
+	}
+	tuple := make([]any, len(importer.values))
+	for i, value := range importer.values {
+		var err error
+		if tuple[i], err = importer.converters[i].Convert(value); err != nil {
+			return nil, &RecordError{
+				Record: importer.row,
+				Err:    fmt.Errorf("column %q: %w", importer.names[i], err),
+			}
+		}
+	}
+	return tuple, nil
+}
+
+// ReadAll reads and converts all remaining rows.
+func (importer *SQLImporter) ReadAll() ([][]any, error) {
+	var tuples [][]any
+	for {
+		tuple, err := importer.ReadTuple()
+		if err == io.EOF {
+			return tuples, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		tuples = append(tuples, tuple)
+	}
+}
@@ -0,0 +1,366 @@
+package tupleconv_test
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tarantool/go-tarantool/v2/datetime"
+
+	"github.com/tarantool/go-tupleconv"
+)
+
+// fakeColumn is a column of the fake SQL driver.
+type fakeColumn struct {
+	name     string
+	dbType   string
+	nullable bool
+	scanType reflect.Type
+}
+
+// fakeTable is a result of a query of the fake SQL driver.
+type fakeTable struct {
+	columns []fakeColumn
+	rows    [][]driver.Value
+}
+
+// fakeTables are results of the queries of the fake SQL driver.
+var fakeTables = map[string]fakeTable{}
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(string) (driver.Conn, error) {
+	return fakeConn{}, nil
+}
+
+type fakeConn struct{}
+
+func (fakeConn) Prepare(query string) (driver.Stmt, error) {
+	table, ok := fakeTables[query]
+	if !ok {
+		return nil, errors.New("unknown query")
+	}
+	return fakeStmt{table: table}, nil
+}
+
+func (fakeConn) Close() error {
+	return nil
+}
+
+func (fakeConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("not supported")
+}
+
+type fakeStmt struct {
+	table fakeTable
+}
+
+func (fakeStmt) Close() error {
+	return nil
+}
+
+func (fakeStmt) NumInput() int {
+	return 0
+}
+
+func (fakeStmt) Exec([]driver.Value) (driver.Result, error) {
+	return nil, errors.New("not supported")
+}
+
+func (stmt fakeStmt) Query([]driver.Value) (driver.Rows, error) {
+	return &fakeRows{table: stmt.table}, nil
+}
+
+type fakeRows struct {
+	table fakeTable
+	pos   int
+}
+
+func (rows *fakeRows) Columns() []string {
+	names := make([]string, len(rows.table.columns))
+	for i, column := range rows.table.columns {
+		names[i] = column.name
+	}
+	return names
+}
+
+func (*fakeRows) Close() error {
+	return nil
+}
+
+func (rows *fakeRows) Next(dest []driver.Value) error {
+	if rows.pos == len(rows.table.rows) {
+		return io.EOF
+	}
+	copy(dest, rows.table.rows[rows.pos])
+	rows.pos++
+	return nil
+}
+
+func (rows *fakeRows) ColumnTypeDatabaseTypeName(index int) string {
+	return rows.table.columns[index].dbType
+}
+
+func (rows *fakeRows) ColumnTypeNullable(index int) (bool, bool) {
+	return rows.table.columns[index].nullable, true
+}
+
+func (rows *fakeRows) ColumnTypeScanType(index int) reflect.Type {
+	if scanType := rows.table.columns[index].scanType; scanType != nil {
+		return scanType
+	}
+	return reflect.TypeOf([]byte{})
+}
+
+func init() {
+	sql.Register("tupleconv_fake", fakeDriver{})
+}
+
+func queryFake(t *testing.T, table fakeTable) *sql.Rows {
+	t.Helper()
+	fakeTables[t.Name()] = table
+	db, err := sql.Open("tupleconv_fake", "")
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	rows, err := db.Query(t.Name())
+	require.NoError(t, err)
+	t.Cleanup(func() { rows.Close() })
+	return rows
+}
+
+var usersTable = fakeTable{
+	columns: []fakeColumn{
+		{name: "id", dbType: "BIGINT", scanType: reflect.TypeOf(int64(0))},
+		{name: "name", dbType: "VARCHAR"},
+		{name: "balance", dbType: "NUMERIC", nullable: true},
+		{name: "created", dbType: "TIMESTAMP", scanType: reflect.TypeOf(time.Time{})},
+		{name: "avatar", dbType: "BYTEA", nullable: true},
+		{name: "verified", dbType: "TINYINT"},
+		{name: "key", dbType: "uuid"},
+	},
+	rows: [][]driver.Value{
+		{
+			int64(1), []byte("Alice"), []byte("10.5"),
+			time.Date(2023, 8, 30, 12, 6, 5, 0, time.UTC), []byte{0, 1}, int64(1),
+			[]byte("09b56913-11f0-4fa4-b5d0-901b5efa532a"),
+		},
+		{
+			int64(2), "Bob", nil, []byte("2023-08-30 12:06:05"), nil, int64(0),
+			[]byte(uuid.MustParse("09b56913-11f0-4fa4-b5d0-901b5efa532a").String()),
+		},
+	},
+}
+
+func TestSpaceFormatFromSQL(t *testing.T) {
+	rows := queryFake(t, fakeTable{
+		columns: []fakeColumn{
+			{name: "a", dbType: "INT4"},
+			{name: "b", dbType: "UNSIGNED BIGINT"},
+			{name: "c", dbType: "double precision", nullable: true},
+			{name: "d", dbType: "DECIMAL"},
+			{name: "e", dbType: "TEXT"},
+			{name: "f", dbType: "BLOB"},
+			{name: "g", dbType: "TIMESTAMPTZ"},
+			{name: "h", dbType: "UUID"},
+			{name: "i", dbType: "JSONB"},
+			{name: "j", dbType: "_INT4"},
+			{name: "k", dbType: "BOOL"},
+			{name: "l", dbType: "MYINT", scanType: reflect.TypeOf(uint32(0))},
+			{name: "m", dbType: "MYTIME", scanType: reflect.TypeOf(time.Time{})},
+			{name: "n", dbType: "MYTYPE"},
+		},
+	})
+	columns, err := rows.ColumnTypes()
+	require.NoError(t, err)
+	assert.Equal(t, []tupleconv.SpaceField{
+		{Name: "a", Type: tupleconv.TypeInteger},
+		{Name: "b", Type: tupleconv.TypeUnsigned},
+		{Name: "c", Type: tupleconv.TypeDouble, IsNullable: true},
+		{Name: "d", Type: tupleconv.TypeDecimal},
+		{Name: "e", Type: tupleconv.TypeString},
+		{Name: "f", Type: tupleconv.TypeVarbinary},
+		{Name: "g", Type: tupleconv.TypeDatetime},
+		{Name: "h", Type: tupleconv.TypeUUID},
+		{Name: "i", Type: tupleconv.TypeAny},
+		{Name: "j", Type: tupleconv.TypeString},
+		{Name: "k", Type: tupleconv.TypeBoolean},
+		{Name: "l", Type: tupleconv.TypeUnsigned},
+		{Name: "m", Type: tupleconv.TypeDatetime},
+		{Name: "n", Type: tupleconv.TypeString},
+	}, tupleconv.SpaceFormatFromSQL(columns))
+}
+
+func TestSQLImporter(t *testing.T) {
+	rows := queryFake(t, usersTable)
+	columns, err := rows.ColumnTypes()
+	require.NoError(t, err)
+	spaceFmt := tupleconv.SpaceFormatFromSQL(columns)
+	spaceFmt[5].Type = tupleconv.TypeBoolean
+
+	importer, err := tupleconv.MakeSQLImporter(rows, tupleconv.MakeSQLToTTConvFactory(),
+		spaceFmt)
+	require.NoError(t, err)
+	tuples, err := importer.ReadAll()
+	require.NoError(t, err)
+	require.Len(t, tuples, 2)
+
+	dt, err := datetime.MakeDatetime(time.Date(2023, 8, 30, 12, 6, 5, 0, time.UTC))
+	require.NoError(t, err)
+	id := uuid.MustParse("09b56913-11f0-4fa4-b5d0-901b5efa532a")
+	assert.Equal(t, []any{
+		uint64(1), "Alice", mustDecimal(t, "10.5"), dt, []byte{0, 1}, true, id,
+	}, tuples[0])
+	assert.Equal(t, []any{uint64(2), "Bob", nil, dt, nil, false, id}, tuples[1])
+
+	_, err = importer.ReadTuple()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestSQLImporter_pgArray(t *testing.T) {
+	rows := queryFake(t, fakeTable{
+		columns: []fakeColumn{{name: "ids", dbType: "_INT4"}},
+		rows:    [][]driver.Value{{[]byte("{1,2,3}")}},
+	})
+	columns, err := rows.ColumnTypes()
+	require.NoError(t, err)
+	importer, err := tupleconv.MakeSQLImporter(rows, tupleconv.MakeSQLToTTConvFactory(),
+		tupleconv.SpaceFormatFromSQL(columns))
+	require.NoError(t, err)
+	tuples, err := importer.ReadAll()
+	require.NoError(t, err)
+	assert.Equal(t, [][]any{{"{1,2,3}"}}, tuples)
+}
+
+func TestSQLImporter_errors(t *testing.T) {
+	spaceFmt := []tupleconv.SpaceField{
+		{Name: "id", Type: tupleconv.TypeUnsigned},
+		{Name: "name", Type: tupleconv.TypeString},
+	}
+	table := fakeTable{
+		columns: []fakeColumn{{name: "id"}, {name: "name"}},
+		rows: [][]driver.Value{
+			{int64(1), "Alice"},
+			{int64(-1), "Bob"},
+			{int64(3), nil},
+		},
+	}
+	fac := tupleconv.MakeSQLToTTConvFactory()
+
+	_, err := tupleconv.MakeSQLImporter(queryFake(t, table), fac, spaceFmt[:1])
+	assert.EqualError(t, err, "2 columns, but 1 fields in the space format")
+
+	importer, err := tupleconv.MakeSQLImporter(queryFake(t, table), fac, spaceFmt)
+	require.NoError(t, err)
+	tuple, err := importer.ReadTuple()
+	require.NoError(t, err)
+	assert.Equal(t, []any{uint64(1), "Alice"}, tuple)
+
+	_, err = importer.ReadTuple()
+	var recordErr *tupleconv.RecordError
+	require.ErrorAs(t, err, &recordErr)
+	assert.Equal(t, 2, recordErr.Record)
+	assert.EqualError(t, err, `record 2: column "id": unexpected value -1 for type "unsigned"`)
+
+	_, err = importer.ReadTuple()
+	assert.EqualError(t, err, `record 3: column "name": unexpected value <nil> for type "string"`)
+}
+
+func TestSQLToTTConvFactory(t *testing.T) {
+	fac := tupleconv.MakeSQLToTTConvFactory()
+	id := uuid.MustParse("09b56913-11f0-4fa4-b5d0-901b5efa532a")
+	dt, err := datetime.MakeDatetime(time.Date(2023, 8, 30, 12, 6, 5, 500, time.UTC))
+	require.NoError(t, err)
+
+	tests := []struct {
+		typ   tupleconv.TypeName
+		cases []convCase[any, any]
+	}{
+		{
+			typ: tupleconv.TypeString,
+			cases: []convCase[any, any]{
+				{value: sql.NullString{String: "a", Valid: true}, expected: "a"},
+				{value: sql.RawBytes("b"), expected: "b"},
+				{value: int64(1), isErr: true},
+			},
+		},
+		{
+			typ: tupleconv.TypeInteger,
+			cases: []convCase[any, any]{
+				{value: int64(-1), expected: int64(-1)},
+				{value: []byte("-2"), expected: int64(-2)},
+				{value: sql.NullInt64{Int64: 3, Valid: true}, expected: uint64(3)},
+				{value: sql.NullInt64{}, isErr: true},
+			},
+		},
+		{
+			typ: tupleconv.TypeBoolean,
+			cases: []convCase[any, any]{
+				{value: true, expected: true},
+				{value: []byte("t"), expected: true},
+				{value: sql.NullInt64{Int64: 0, Valid: true}, expected: false},
+				{value: int64(2), isErr: true},
+			},
+		},
+		{
+			typ: tupleconv.TypeDatetime,
+			cases: []convCase[any, any]{
+				{value: time.Date(2023, 8, 30, 12, 6, 5, 500, time.UTC), expected: dt},
+				{value: []byte("2023-08-30 12:06:05.0000005Z"), expected: dt},
+				{value: sql.NullTime{}, isErr: true},
+			},
+		},
+		{
+			typ: tupleconv.TypeUUID,
+			cases: []convCase[any, any]{
+				{value: id[:], expected: id},
+				{value: id.String(), expected: id},
+				{value: []byte{1}, isErr: true},
+			},
+		},
+		{
+			typ: tupleconv.TypeVarbinary,
+			cases: []convCase[any, any]{
+				{value: sql.RawBytes{1, 2}, expected: []byte{1, 2}},
+				{value: "a", expected: []byte("a")},
+				{value: int64(1), isErr: true},
+			},
+		},
+		{
+			typ: tupleconv.TypeMap,
+			cases: []convCase[any, any]{
+				{value: []byte(`{"a": 1}`), expected: map[string]any{"a": uint64(1)}},
+				{value: []byte(`[1]`), isErr: true},
+			},
+		},
+		{
+			typ: tupleconv.TypeAny,
+			cases: []convCase[any, any]{
+				{value: []byte(`[-1]`), expected: []any{int64(-1)}},
+				{value: []byte(`a`), expected: "a"},
+				{value: int64(1), expected: uint64(1)},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(string(test.typ), func(t *testing.T) {
+			conv, err := tupleconv.GetConverterByType[any](fac, test.typ)
+			require.NoError(t, err)
+			HelperTestConverter(t, conv, test.cases)
+		})
+	}
+
+	nullable := fac.MakeNullableConverter(fac.GetStringConverter())
+	for _, value := range []any{nil, sql.NullString{}, (*string)(nil)} {
+		result, err := nullable.Convert(value)
+		require.NoError(t, err)
+		assert.Nil(t, result)
+	}
+}
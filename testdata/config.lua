@@ -1,3 +1,8 @@
+-- vshard is optional: it is needed only to check bucket ids by
+-- TestBucketID_vshard. It is loaded before box.cfg changes
+-- the working directory, so the rocks of the repository are found.
+local has_vshard, vshard = pcall(require, 'vshard')
+
 -- Do not set listen for now so connector won't be
 -- able to send requests until everything is configured.
 
@@ -47,6 +52,37 @@ function get_test_space_fmt()
     return box.space.test_space:format()
 end
 
+local vshard_routers = {}
+
+-- vshard_bucket_id returns the bucket id of the key, calculated by
+-- the sharding function of a vshard router with the bucket count.
+-- The router isn't connected to any storage: bucket ids are calculated
+-- locally.
+function vshard_bucket_id(func, key, bucket_count)
+    if not has_vshard then
+        error('vshard is not installed')
+    end
+    local router = vshard_routers[bucket_count]
+    if router == nil then
+        router = vshard.router.new('router_' .. bucket_count, {
+            bucket_count = bucket_count,
+            sharding = {
+                ['cbf06940-0790-498b-948d-042b62cf3d29'] = {
+                    replicas = {
+                        ['8a274925-a26d-47fc-9e1b-af88ce939412'] = {
+                            uri = 'storage:storage@127.0.0.1:3301',
+                            name = 'storage',
+                            master = true,
+                        },
+                    },
+                },
+            },
+        })
+        vshard_routers[bucket_count] = router
+    end
+    return router['bucket_id_' .. func](router, key)
+end
+
 -- Set listen only when every other thing is configured.
 box.cfg {
     listen = os.getenv("TEST_TNT_LISTEN"),
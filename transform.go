@@ -0,0 +1,169 @@
+package tupleconv
+
+import (
+	"fmt"
+	"strings"
+)
+
+// checkPositions checks, that the positions are unique and in range of the tuple.
+func checkPositions(tupleLen int, positions []int) error {
+	for i, pos := range positions {
+		if pos < 0 || pos >= tupleLen {
+			return fmt.Errorf("position %d is out of range of the tuple of length %d",
+				pos, tupleLen)
+		}
+		for _, prev := range positions[:i] {
+			if prev == pos {
+				return fmt.Errorf("duplicate position %d", pos)
+			}
+		}
+	}
+	return nil
+}
+
+// MakeMergeTransform creates a tuple transform, that merges the fields at
+// the positions into one field by the function, for example, date, time and time
+// zone columns into one datetime column. The merged field replaces the field at
+// the first position, the other fields are removed.
+func MakeMergeTransform[S any](positions []int,
+	merge func(fields []S) (S, error)) Converter[[]S, []S] {
+	return MakeFuncConverter(func(tuple []S) ([]S, error) {
+		if len(positions) == 0 {
+			return tuple, nil
+		}
+		if err := checkPositions(len(tuple), positions); err != nil {
+			return nil, err
+		}
+		fields := make([]S, len(positions))
+		for i, pos := range positions {
+			fields[i] = tuple[pos]
+		}
+		merged, err := merge(fields)
+		if err != nil {
+			return nil, err
+		}
+		result := make([]S, 0, len(tuple)-len(positions)+1)
+		for i, field := range tuple {
+			if i == positions[0] {
+				result = append(result, merged)
+			} else if !containsPosition(positions, i) {
+				result = append(result, field)
+			}
+		}
+		return result, nil
+	})
+}
+
+// containsPosition returns true, if the position is in the list.
+func containsPosition(positions []int, pos int) bool {
+	for _, item := range positions {
+		if item == pos {
+			return true
+		}
+	}
+	return false
+}
+
+// MakeSplitTransform creates a tuple transform, that replaces the field at
+// the position by the fields, returned by the function.
+func MakeSplitTransform[S any](pos int, split func(field S) ([]S, error)) Converter[[]S, []S] {
+	return MakeFuncConverter(func(tuple []S) ([]S, error) {
+		if err := checkPositions(len(tuple), []int{pos}); err != nil {
+			return nil, err
+		}
+		fields, err := split(tuple[pos])
+		if err != nil {
+			return nil, err
+		}
+		result := make([]S, 0, len(tuple)-1+len(fields))
+		result = append(result, tuple[:pos]...)
+		result = append(result, fields...)
+		return append(result, tuple[pos+1:]...), nil
+	})
+}
+
+// MakeSplitStringTransform creates a tuple transform, that splits the string
+// field at the position by the separator into exactly count fields, for example,
+// `lat;lon` into two fields.
+func MakeSplitStringTransform(pos int, sep string, count int) Converter[[]string, []string] {
+	return MakeSplitTransform(pos, func(field string) ([]string, error) {
+		fields := strings.Split(field, sep)
+		if len(fields) != count {
+			return nil, fmt.Errorf("%q is split into %d fields, expected %d",
+				field, len(fields), count)
+		}
+		return fields, nil
+	})
+}
+
+// MakeDropTransform creates a tuple transform, that removes the fields at
+// the positions.
+func MakeDropTransform[S any](positions ...int) Converter[[]S, []S] {
+	return MakeFuncConverter(func(tuple []S) ([]S, error) {
+		if err := checkPositions(len(tuple), positions); err != nil {
+			return nil, err
+		}
+		result := make([]S, 0, len(tuple)-len(positions))
+		for i, field := range tuple {
+			if !containsPosition(positions, i) {
+				result = append(result, field)
+			}
+		}
+		return result, nil
+	})
+}
+
+// MakeReorderTransform creates a tuple transform, that builds the tuple from
+// the fields at the positions of the order. Fields, that are absent in
+// the order, are removed.
+func MakeReorderTransform[S any](order ...int) Converter[[]S, []S] {
+	return MakeFuncConverter(func(tuple []S) ([]S, error) {
+		if err := checkPositions(len(tuple), order); err != nil {
+			return nil, err
+		}
+		result := make([]S, len(order))
+		for i, pos := range order {
+			result[i] = tuple[pos]
+		}
+		return result, nil
+	})
+}
+
+// MakeFormatValidator creates a tuple transform, that checks, that the converted
+// tuple matches the space format: the types of the values and the nullability of
+// the fields. Missing trailing fields must be nullable, extra fields are allowed.
+// The tuple is not changed. It is useful as the last post-transform.
+func MakeFormatValidator(spaceFmt []SpaceField) Converter[[]any, []any] {
+	return makeFormatValidator(spaceFmt, make([][]subField, len(spaceFmt)))
+}
+
+// MakeNestedFormatValidator creates a tuple transform, that checks, that
+// the converted tuple matches the space format, like MakeFormatValidator, and
+// the sub-fields of the nested format.
+func MakeNestedFormatValidator(spaceFmt []SpaceField,
+	nested NestedFormat) (Converter[[]any, []any], error) {
+	subFields, err := parseNestedFormat(spaceFmt, nested)
+	if err != nil {
+		return nil, err
+	}
+	return makeFormatValidator(spaceFmt, subFields), nil
+}
+
+// makeFormatValidator creates the validator of tuples by the space format and
+// the sub-fields of its fields.
+func makeFormatValidator(spaceFmt []SpaceField, subFields [][]subField) Converter[[]any, []any] {
+	return MakeFuncConverter(func(tuple []any) ([]any, error) {
+		for i, field := range spaceFmt {
+			if i >= len(tuple) {
+				if !field.IsNullable {
+					return nil, fmt.Errorf("field #%d (%q) is missing", i+1, field.Name)
+				}
+				continue
+			}
+			if err := validateNestedValue(field, subFields[i], tuple[i]); err != nil {
+				return nil, fmt.Errorf("field #%d (%q): %w", i+1, field.Name, err)
+			}
+		}
+		return tuple, nil
+	})
+}
@@ -0,0 +1,231 @@
+package tupleconv_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tarantool/go-tarantool/v2/datetime"
+	"github.com/vmihailenco/msgpack/v5"
+
+	"github.com/tarantool/go-tupleconv"
+)
+
+func TestTransforms(t *testing.T) {
+	tuple := []string{"a", "b", "c", "d"}
+	join := func(fields []string) (string, error) {
+		return strings.Join(fields, "+"), nil
+	}
+	tests := []struct {
+		name      string
+		transform tupleconv.Converter[[]string, []string]
+		expected  []string
+		err       string
+	}{
+		{
+			name:      "merge",
+			transform: tupleconv.MakeMergeTransform([]int{1, 3}, join),
+			expected:  []string{"a", "b+d", "c"},
+		},
+		{
+			name:      "merge_reversed",
+			transform: tupleconv.MakeMergeTransform([]int{3, 0}, join),
+			expected:  []string{"b", "c", "d+a"},
+		},
+		{
+			name:      "merge_empty",
+			transform: tupleconv.MakeMergeTransform(nil, join),
+			expected:  tuple,
+		},
+		{
+			name:      "merge_duplicate",
+			transform: tupleconv.MakeMergeTransform([]int{1, 1}, join),
+			err:       "duplicate position 1",
+		},
+		{
+			name: "merge_error",
+			transform: tupleconv.MakeMergeTransform([]int{0, 1},
+				func([]string) (string, error) { return "", errors.New("merge error") }),
+			err: "merge error",
+		},
+		{
+			name: "split",
+			transform: tupleconv.MakeSplitTransform(1, func(field string) ([]string, error) {
+				return []string{field + "1", field + "2"}, nil
+			}),
+			expected: []string{"a", "b1", "b2", "c", "d"},
+		},
+		{
+			name:      "split_string",
+			transform: tupleconv.MakeSplitStringTransform(3, ";", 1),
+			expected:  tuple,
+		},
+		{
+			name:      "split_out_of_range",
+			transform: tupleconv.MakeSplitStringTransform(4, ";", 2),
+			err:       "position 4 is out of range of the tuple of length 4",
+		},
+		{
+			name:      "drop",
+			transform: tupleconv.MakeDropTransform[string](0, 2),
+			expected:  []string{"b", "d"},
+		},
+		{
+			name:      "drop_negative",
+			transform: tupleconv.MakeDropTransform[string](-1),
+			err:       "position -1 is out of range of the tuple of length 4",
+		},
+		{
+			name:      "reorder",
+			transform: tupleconv.MakeReorderTransform[string](3, 0, 1),
+			expected:  []string{"d", "a", "b"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := test.transform.Convert(tuple)
+			if test.err != "" {
+				assert.EqualError(t, err, test.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, result)
+			assert.Equal(t, []string{"a", "b", "c", "d"}, tuple)
+		})
+	}
+
+	_, err := tupleconv.MakeSplitStringTransform(0, ";", 2).Convert([]string{"1;2;3"})
+	assert.EqualError(t, err, `"1;2;3" is split into 3 fields, expected 2`)
+}
+
+func TestMakeFormatValidator(t *testing.T) {
+	validator := tupleconv.MakeFormatValidator([]tupleconv.SpaceField{
+		{Name: "id", Type: tupleconv.TypeUnsigned},
+		{Name: "value", Type: tupleconv.TypeNumber},
+		{Name: "comment", Type: tupleconv.TypeString, IsNullable: true},
+	})
+	cases := []struct {
+		tuple []any
+		err   string
+	}{
+		{tuple: []any{uint64(1), 1.5, "a"}},
+		{tuple: []any{uint64(1), int64(-1)}},
+		{tuple: []any{uint64(1), uint64(1), nil, "extra"}},
+		{tuple: []any{uint64(1)}, err: `field #2 ("value") is missing`},
+		{
+			tuple: []any{int64(-1), 1.5},
+			err:   `field #1 ("id"): unexpected integer value for unsigned field`,
+		},
+		{
+			tuple: []any{nil, 1.5},
+			err:   `field #1 ("id"): unexpected null value for non-nullable field`,
+		},
+	}
+	for _, tc := range cases {
+		result, err := validator.Convert(tc.tuple)
+		if tc.err != "" {
+			assert.EqualError(t, err, tc.err)
+			continue
+		}
+		require.NoError(t, err)
+		assert.Equal(t, tc.tuple, result)
+	}
+}
+
+func TestMapper_transforms(t *testing.T) {
+	spaceFmt := []tupleconv.SpaceField{
+		{Name: "id", Type: tupleconv.TypeUnsigned},
+		{Name: "created", Type: tupleconv.TypeDatetime},
+		{Name: "lat", Type: tupleconv.TypeDouble},
+		{Name: "lon", Type: tupleconv.TypeDouble},
+		{Name: "price", Type: tupleconv.TypeMap},
+	}
+	// Columns: id, date, time, timezone, position, amount, currency, ignored.
+	fac := tupleconv.MakeStringToTTConvFactory()
+	converters, err := tupleconv.MakeTypeToTTConverters[string](fac, []tupleconv.SpaceField{
+		{Type: tupleconv.TypeUnsigned},
+		{Type: tupleconv.TypeDatetime},
+		{Type: tupleconv.TypeDouble},
+		{Type: tupleconv.TypeDouble},
+		{Type: tupleconv.TypeDecimal},
+		{Type: tupleconv.TypeString},
+	})
+	require.NoError(t, err)
+	mapper := tupleconv.MakeMapper(converters).
+		WithPreTransform(tupleconv.MakeDropTransform[string](7)).
+		WithPreTransform(tupleconv.MakeMergeTransform([]int{1, 2, 3},
+			func(fields []string) (string, error) {
+				return fields[0] + "T" + fields[1] + " " + fields[2], nil
+			})).
+		WithPreTransform(tupleconv.MakeSplitStringTransform(2, ";", 2)).
+		WithPostTransform(tupleconv.MakeMergeTransform([]int{4, 5},
+			func(fields []any) (any, error) {
+				return map[string]any{"amount": fields[0], "currency": fields[1]}, nil
+			})).
+		WithPostTransform(tupleconv.MakeFormatValidator(spaceFmt))
+
+	row := []string{"1", "2023-08-30", "12:06:05", "Europe/Moscow", "55.75;37.62", "10.5",
+		"RUB", "x"}
+	moscow, err := time.LoadLocation("Europe/Moscow")
+	require.NoError(t, err)
+	expected := []any{
+		uint64(1),
+		mustDatetime(t, time.Date(2023, 8, 30, 12, 6, 5, 0, moscow)),
+		55.75,
+		37.62,
+		map[string]any{"amount": mustDecimal(t, "10.5"), "currency": "RUB"},
+	}
+
+	tuple, err := mapper.Map(row)
+	require.NoError(t, err)
+	assert.Equal(t, expected, tuple)
+
+	tuple, err = mapper.MapTo(make([]any, 0, 8), row)
+	require.NoError(t, err)
+	assert.Equal(t, expected, tuple)
+
+	tuple, err = mapper.MapContext(context.Background(), row)
+	require.NoError(t, err)
+	assert.Equal(t, expected, tuple)
+
+	data, err := tupleconv.MakeMsgpackMapper(mapper).AppendTuple(nil, row)
+	require.NoError(t, err)
+	var decoded []any
+	require.NoError(t, msgpack.Unmarshal(data, &decoded))
+	assert.Len(t, decoded, len(spaceFmt))
+	assert.IsType(t, datetime.Datetime{}, decoded[1])
+
+	_, err = mapper.Map(row[:7])
+	assert.EqualError(t, err, "position 7 is out of range of the tuple of length 7")
+
+	_, err = mapper.Map(append(row[:4:4], "55.75", "10.5", "RUB", "x"))
+	assert.EqualError(t, err, `"55.75" is split into 1 fields, expected 2`)
+}
+
+func TestNamedMapper_transforms(t *testing.T) {
+	spaceFmt := []tupleconv.SpaceField{
+		{Name: "id", Type: tupleconv.TypeUnsigned},
+		{Name: "name", Type: tupleconv.TypeString},
+	}
+	converters, err := tupleconv.MakeTypeToTTConverters[string](
+		tupleconv.MakeStringToTTConvFactory(), spaceFmt)
+	require.NoError(t, err)
+	mapper := tupleconv.MakeMapper(converters).
+		WithPostTransform(tupleconv.MakeReorderTransform[any](1, 0))
+
+	named, err := tupleconv.MakeNamedMapper(mapper, spaceFmt)
+	require.NoError(t, err)
+	tuple, err := named.MapNamed(map[string]string{"id": "1", "name": "Alice"})
+	require.NoError(t, err)
+	assert.Equal(t, []any{"Alice", uint64(1)}, tuple)
+
+	named, err = tupleconv.MakeNamedMapper(
+		mapper.WithPreTransform(tupleconv.MakeDropTransform[string]()), spaceFmt)
+	require.NoError(t, err)
+	_, err = named.MapNamed(map[string]string{"id": "1", "name": "Alice"})
+	assert.EqualError(t, err, "pre-transforms are not supported for named tuples")
+}
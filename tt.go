@@ -1,6 +1,7 @@
 package tupleconv
 
 import (
+	"context"
 	"fmt"
 )
 
@@ -26,6 +27,35 @@ const (
 	TypeInterval  TypeName = "interval"
 )
 
+// isKnownType checks if the type is one of the supported tarantool types.
+func isKnownType(typ TypeName) bool {
+	switch typ {
+	case TypeBoolean, TypeString, TypeInteger, TypeUnsigned, TypeDouble, TypeNumber,
+		TypeDecimal, TypeDatetime, TypeUUID, TypeArray, TypeMap, TypeVarbinary,
+		TypeScalar, TypeAny, TypeInterval:
+		return true
+	}
+	return false
+}
+
+// isTypeAssignable checks if values of the source type can be stored in a field
+// of the target type.
+func isTypeAssignable(target, source TypeName) bool {
+	if target == source || target == TypeAny {
+		return true
+	}
+	switch target {
+	case TypeScalar:
+		return source != TypeArray && source != TypeMap && source != TypeAny
+	case TypeNumber:
+		return source == TypeUnsigned || source == TypeInteger ||
+			source == TypeDouble || source == TypeDecimal
+	case TypeInteger:
+		return source == TypeUnsigned
+	}
+	return false
+}
+
 const (
 	defaultThousandSeparators = ""
 	defaultDecimalSeparators  = "."
@@ -99,8 +129,27 @@ type StringToTTConvFactory struct {
 	// decimalSeparators = ",#": 12,3 -> 12.3, 100.500 -> 100.500, 12#13 -> 12.13.
 	decimalSeparators string
 
-	// nullValue is a value that is interpreted as null.
-	nullValue string
+	// nullValues are values that are interpreted as null.
+	nullValues []string
+
+	// typeNullValues are per-type null values. They override nullValues.
+	typeNullValues map[TypeName][]string
+
+	// datetimeLayouts are additional time layouts for datetime, that are tried
+	// after the default ones.
+	datetimeLayouts []string
+
+	// fieldNullValues are per-field null values by field name.
+	// They override typeNullValues and nullValues.
+	fieldNullValues map[string][]string
+
+	// normalization are options of the string normalization, that is applied
+	// to each field before the conversion.
+	normalization NormalizeOptions
+
+	// fieldNormalization are per-field normalization options by field name.
+	// They override normalization.
+	fieldNormalization map[string]NormalizeOptions
 }
 
 // MakeStringToTTConvFactory creates StringToTTConvFactory.
@@ -108,7 +157,7 @@ func MakeStringToTTConvFactory() StringToTTConvFactory {
 	return StringToTTConvFactory{
 		thousandSeparators: defaultThousandSeparators,
 		decimalSeparators:  defaultDecimalSeparators,
-		nullValue:          defaultNullValue,
+		nullValues:         []string{defaultNullValue},
 	}
 }
 
@@ -124,8 +173,14 @@ func (fac StringToTTConvFactory) GetUnsignedConverter() Converter[string, any] {
 	return MakeStringToUIntConverter(fac.thousandSeparators)
 }
 
-func (StringToTTConvFactory) GetDatetimeConverter() Converter[string, any] {
-	return MakeStringToDatetimeConverter()
+func (fac StringToTTConvFactory) GetDatetimeConverter() Converter[string, any] {
+	if len(fac.datetimeLayouts) == 0 {
+		return MakeStringToDatetimeConverter()
+	}
+	return MakeSequenceConverter([]Converter[string, any]{
+		MakeStringToDatetimeConverter(),
+		MakeStringToDatetimeLayoutsConverter(fac.datetimeLayouts),
+	})
 }
 
 func (StringToTTConvFactory) GetUUIDConverter() Converter[string, any] {
@@ -153,16 +208,13 @@ func (fac StringToTTConvFactory) GetDecimalConverter() Converter[string, any] {
 }
 
 func (fac StringToTTConvFactory) GetIntegerConverter() Converter[string, any] {
-	return MakeSequenceConverter([]Converter[string, any]{
-		MakeStringToUIntConverter(fac.thousandSeparators),
-		MakeStringToIntConverter(fac.thousandSeparators),
-	})
+	return makeIntegerConverter[string](MakeStringToUIntConverter(fac.thousandSeparators),
+		MakeStringToIntConverter(fac.thousandSeparators), fac.thousandSeparators)
 }
 
 func (fac StringToTTConvFactory) GetNumberConverter() Converter[string, any] {
 	return MakeSequenceConverter([]Converter[string, any]{
-		MakeStringToUIntConverter(fac.thousandSeparators),
-		MakeStringToIntConverter(fac.thousandSeparators),
+		fac.GetIntegerConverter(),
 		MakeStringToFloatConverter(fac.thousandSeparators, fac.decimalSeparators),
 	})
 }
@@ -197,18 +249,134 @@ func (fac StringToTTConvFactory) GetScalarConverter() Converter[string, any] {
 
 func (fac StringToTTConvFactory) MakeNullableConverter(
 	converter Converter[string, any]) Converter[string, any] {
+	return makeStringToNullableConverter(fac.nullValues, converter)
+}
+
+// makeStringToNullableConverter extends the converter to a nullable converter
+// with the null values.
+func makeStringToNullableConverter(
+	nullValues []string, converter Converter[string, any]) Converter[string, any] {
 	return MakeSequenceConverter([]Converter[string, any]{
-		MakeStringToNullConverter(fac.nullValue),
+		MakeStringToNullValuesConverter(nullValues),
 		converter,
 	})
 }
 
-// WithNullValue sets nullValue.
+// WithNullValue sets the only value, that is interpreted as null.
 func (fac StringToTTConvFactory) WithNullValue(nullValue string) StringToTTConvFactory {
-	fac.nullValue = nullValue
+	fac.nullValues = []string{nullValue}
+	return fac
+}
+
+// WithNullValues sets values, that are interpreted as null. The values are copied.
+func (fac StringToTTConvFactory) WithNullValues(nullValues ...string) StringToTTConvFactory {
+	fac.nullValues = append([]string{}, nullValues...)
+	return fac
+}
+
+// WithTypeNullValues sets values, that are interpreted as null for fields of the type.
+func (fac StringToTTConvFactory) WithTypeNullValues(
+	typ TypeName, nullValues ...string) StringToTTConvFactory {
+	typeNullValues := make(map[TypeName][]string, len(fac.typeNullValues)+1)
+	for fieldType, values := range fac.typeNullValues {
+		typeNullValues[fieldType] = values
+	}
+	typeNullValues[typ] = append([]string{}, nullValues...)
+	fac.typeNullValues = typeNullValues
+	return fac
+}
+
+// WithFieldNullValues sets values, that are interpreted as null for the field
+// with the name.
+func (fac StringToTTConvFactory) WithFieldNullValues(
+	name string, nullValues ...string) StringToTTConvFactory {
+	fieldNullValues := make(map[string][]string, len(fac.fieldNullValues)+1)
+	for fieldName, values := range fac.fieldNullValues {
+		fieldNullValues[fieldName] = values
+	}
+	fieldNullValues[name] = append([]string{}, nullValues...)
+	fac.fieldNullValues = fieldNullValues
+	return fac
+}
+
+// getFieldNullValues returns values, that are interpreted as null for the field.
+func (fac StringToTTConvFactory) getFieldNullValues(field SpaceField) []string {
+	if nullValues, ok := fac.fieldNullValues[field.Name]; ok {
+		return nullValues
+	}
+	if nullValues, ok := fac.typeNullValues[field.Type]; ok {
+		return nullValues
+	}
+	return fac.nullValues
+}
+
+// WithDatetimeLayouts sets additional time layouts for datetime, in the format
+// of the time package. They are tried after the default formats. The layouts
+// are copied.
+func (fac StringToTTConvFactory) WithDatetimeLayouts(layouts ...string) StringToTTConvFactory {
+	fac.datetimeLayouts = append([]string{}, layouts...)
+	return fac
+}
+
+// WithNormalization sets normalization options for all fields.
+func (fac StringToTTConvFactory) WithNormalization(opts NormalizeOptions) StringToTTConvFactory {
+	fac.normalization = opts
+	return fac
+}
+
+// WithFieldNormalization sets normalization options for the field with the name.
+func (fac StringToTTConvFactory) WithFieldNormalization(
+	name string, opts NormalizeOptions) StringToTTConvFactory {
+	fieldNormalization := make(map[string]NormalizeOptions, len(fac.fieldNormalization)+1)
+	for fieldName, fieldOpts := range fac.fieldNormalization {
+		fieldNormalization[fieldName] = fieldOpts
+	}
+	fieldNormalization[name] = opts
+	fac.fieldNormalization = fieldNormalization
 	return fac
 }
 
+// getFieldNormalization returns normalization options for the field.
+func (fac StringToTTConvFactory) getFieldNormalization(field SpaceField) NormalizeOptions {
+	opts, ok := fac.fieldNormalization[field.Name]
+	if !ok {
+		opts = fac.normalization
+	}
+	if field.Type != TypeString || field.Collation != unicodeCICollation {
+		opts.FoldCase = false
+	}
+	return opts
+}
+
+// makeFieldConverter is the implementation of fieldConvFactory[string] for
+// StringToTTConvFactory. The value is normalized before the nullability check and
+// the conversion to the field type. Per-field and per-type null values are used for
+// nullable fields, unless the outer factory embeds StringToTTConvFactory: then its
+// MakeNullableConverter is used, so an override of it is respected.
+func (fac StringToTTConvFactory) makeFieldConverter(field SpaceField,
+	converter Converter[string, any], outer TTConvFactory[string]) Converter[string, any] {
+	if field.IsNullable {
+		switch outer.(type) {
+		case StringToTTConvFactory, *StringToTTConvFactory:
+			converter = makeStringToNullableConverter(fac.getFieldNullValues(field), converter)
+		default:
+			converter = outer.MakeNullableConverter(converter)
+		}
+	}
+	opts := fac.getFieldNormalization(field)
+	if opts.isEmpty() {
+		return converter
+	}
+	normalizer := MakeStringNormalizer(opts)
+	return MakeFuncContextConverter(func(ctx context.Context, src string) (any, error) {
+		normalized, err := normalizer.Convert(src)
+		if err != nil {
+			return nil, err
+		}
+		return convertContext(ctx, converter, normalized)
+	})
+}
+
 // WithThousandSeparators sets thousandSeparators.
 func (fac StringToTTConvFactory) WithThousandSeparators(
 	separators string) StringToTTConvFactory {
@@ -222,7 +390,30 @@ func (fac StringToTTConvFactory) WithDecimalSeparators(separators string) String
 	return fac
 }
 
-var _ TTConvFactory[string] = (*StringToTTConvFactory)(nil)
+// anyFactory is the implementation of anyConvFactory for StringToTTConvFactory.
+func (fac StringToTTConvFactory) anyFactory() TTConvFactory[any] {
+	return MakeAnyToTTConvFactory(fac)
+}
+
+var (
+	_ TTConvFactory[string]    = (*StringToTTConvFactory)(nil)
+	_ fieldConvFactory[string] = (*StringToTTConvFactory)(nil)
+	_ anyConvFactory           = (*StringToTTConvFactory)(nil)
+)
+
+// fieldConvFactory is implemented by the factories, that customize the converter
+// of a particular space field: per-field null values and normalization. The outer
+// factory is the one passed to MakeTypeToTTConverters, it may embed the factory.
+type fieldConvFactory[Type any] interface {
+	makeFieldConverter(field SpaceField, converter Converter[Type, any],
+		outer TTConvFactory[Type]) Converter[Type, any]
+}
+
+// anyConvFactory is implemented by the factories, that convert decoded values,
+// for example, nested values of maps and arrays, by their configuration.
+type anyConvFactory interface {
+	anyFactory() TTConvFactory[any]
+}
 
 // GetConverterByType returns a converter by TTConvFactory and typename.
 func GetConverterByType[Type any](
@@ -270,6 +461,7 @@ type SpaceField struct {
 	Name       string   `msgpack:"name"`
 	Type       TypeName `msgpack:"type"`
 	IsNullable bool     `msgpack:"is_nullable,omitempty"`
+	Collation  string   `msgpack:"collation,omitempty"`
 }
 
 // MakeTypeToTTConverters creates list of the converters
@@ -277,23 +469,70 @@ type SpaceField struct {
 func MakeTypeToTTConverters[Type any](
 	fac TTConvFactory[Type],
 	spaceFmt []SpaceField) ([]Converter[Type, any], error) {
+	return MakeNestedTypeToTTConverters(fac, spaceFmt, nil)
+}
+
+// MakeNestedTypeToTTConverters creates list of the converters from Type to tt
+// type by the factory, the space format and the sub-fields of its fields. Values
+// of sub-fields are checked and converted to the sub-field types.
+func MakeNestedTypeToTTConverters[Type any](fac TTConvFactory[Type], spaceFmt []SpaceField,
+	nested NestedFormat) ([]Converter[Type, any], error) {
+	subFields, err := parseNestedFormat(spaceFmt, nested)
+	if err != nil {
+		return nil, err
+	}
 	converters := make([]Converter[Type, any], len(spaceFmt))
 	for i, fieldFmt := range spaceFmt {
-		typ := fieldFmt.Type
-		conv, err := GetConverterByType(fac, typ)
+		conv, err := GetConverterByType(fac, fieldFmt.Type)
 		if err != nil {
 			return nil, err
 		}
-		if fieldFmt.IsNullable {
-			conv = fac.MakeNullableConverter(conv)
+		converters[i] = makeFieldConverter(fac, fieldFmt, conv)
+		if len(subFields[i]) == 0 {
+			continue
 		}
-		converters[i] = MakeFuncConverter(func(s Type) (any, error) {
-			result, err := conv.Convert(s)
-			if err != nil {
-				return nil, fmt.Errorf("unexpected value %v for type %q", s, typ)
-			}
-			return result, nil
-		})
+		if err = setSubFieldConverters(fac, subFields[i]); err != nil {
+			return nil, err
+		}
+		converters[i] = makeSubFieldsConverter(converters[i], subFields[i])
 	}
 	return converters, nil
 }
+
+// makeSubFieldsConverter makes the converter of the field, that checks and
+// converts values of the sub-fields.
+func makeSubFieldsConverter[Type any](conv Converter[Type, any],
+	subFields []subField) Converter[Type, any] {
+	return MakeFuncContextConverter(func(ctx context.Context, s Type) (any, error) {
+		result, err := convertContext(ctx, conv, s)
+		if err != nil || result == nil {
+			return result, err
+		}
+		if result, err = applySubFields(result, subFields); err != nil {
+			return nil, fmt.Errorf("unexpected value %v: %w", printableValue(s), err)
+		}
+		return result, nil
+	})
+}
+
+// makeFieldConverter makes the converter of the field from the converter to the field type.
+// Errors of the done context are returned as is.
+func makeFieldConverter[Type any](
+	fac TTConvFactory[Type], field SpaceField, conv Converter[Type, any]) Converter[Type, any] {
+	if fieldFac, ok := fac.(fieldConvFactory[Type]); ok {
+		conv = fieldFac.makeFieldConverter(field, conv, fac)
+	} else if field.IsNullable {
+		conv = fac.MakeNullableConverter(conv)
+	}
+	typ := field.Type
+	return MakeFuncContextConverter(func(ctx context.Context, s Type) (any, error) {
+		result, err := convertContext(ctx, conv, s)
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return nil, ctxErr
+			}
+			return nil, fmt.Errorf("unexpected value %v for type %q", printableValue(s), typ)
+		}
+		return result, nil
+	})
+}
@@ -602,3 +602,268 @@ func TestMakeStringToTTConvFactory_convError(t *testing.T) {
 	assert.Error(t, err)
 	assert.Equal(t, `unexpected value fakeboolean for type "boolean"`, err.Error())
 }
+
+func TestStringToTTConvFactory_normalization(t *testing.T) {
+	spaceFmt := []tupleconv.SpaceField{
+		{Name: "id", Type: tupleconv.TypeUnsigned},
+		{Name: "price", Type: tupleconv.TypeDouble, IsNullable: true},
+		{Name: "name", Type: tupleconv.TypeString, Collation: "unicode_ci"},
+		{Name: "raw", Type: tupleconv.TypeString},
+		{Name: "code", Type: tupleconv.TypeString, Collation: "binary"},
+	}
+	fac := tupleconv.MakeStringToTTConvFactory().
+		WithNormalization(tupleconv.NormalizeOptions{
+			StripBOM:       true,
+			CollapseSpaces: true,
+			Trim:           true,
+			Form:           tupleconv.NormalizationNFC,
+			FoldCase:       true,
+		}).
+		WithFieldNormalization("raw", tupleconv.NormalizeOptions{})
+
+	converters, err := tupleconv.MakeTypeToTTConverters[string](fac, spaceFmt)
+	require.NoError(t, err)
+	mapper := tupleconv.MakeMapper(converters)
+
+	cases := []struct {
+		name     string
+		tuple    []string
+		expected []any
+		isErr    bool
+	}{
+		{
+			name:     "basic",
+			tuple:    []string{"\uFEFF 42 ", "\u00a01.5 ", " Cafe\u0301  DE ", " raw ", " ABC "},
+			expected: []any{uint64(42), 1.5, "caf\u00e9 de", " raw ", "ABC"},
+		},
+		{
+			name:     "trimmed to null",
+			tuple:    []string{"1", "  ", "x", "", ""},
+			expected: []any{uint64(1), nil, "x", "", ""},
+		},
+		{
+			name:     "numbers",
+			tuple:    []string{"\t7\n", " 2.5 "},
+			expected: []any{uint64(7), 2.5},
+		},
+		{
+			name:  "bad number",
+			tuple: []string{"4 2"},
+			isErr: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := mapper.Map(tc.tuple)
+			if tc.isErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expected, result)
+			}
+		})
+	}
+}
+
+func TestStringToTTConvFactory_fieldNormalizationIsolated(t *testing.T) {
+	spaceFmt := []tupleconv.SpaceField{{Name: "id", Type: tupleconv.TypeUnsigned}}
+	base := tupleconv.MakeStringToTTConvFactory()
+	trimming := base.WithFieldNormalization("id", tupleconv.NormalizeOptions{Trim: true})
+	_ = trimming.WithFieldNormalization("id", tupleconv.NormalizeOptions{})
+
+	converters, err := tupleconv.MakeTypeToTTConverters[string](trimming, spaceFmt)
+	require.NoError(t, err)
+	result, err := converters[0].Convert(" 1 ")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), result)
+
+	converters, err = tupleconv.MakeTypeToTTConverters[string](base, spaceFmt)
+	require.NoError(t, err)
+	_, err = converters[0].Convert(" 1 ")
+	assert.Error(t, err)
+}
+
+func TestStringToTTConvFactory_nullValues(t *testing.T) {
+	spaceFmt := []tupleconv.SpaceField{
+		{Name: "id", Type: tupleconv.TypeUnsigned},
+		{Name: "amount", Type: tupleconv.TypeDouble, IsNullable: true},
+		{Name: "name", Type: tupleconv.TypeString, IsNullable: true},
+		{Name: "title", Type: tupleconv.TypeString},
+		{Name: "comment", Type: tupleconv.TypeString, IsNullable: true},
+		{Name: "count", Type: tupleconv.TypeInteger, IsNullable: true},
+	}
+	fac := tupleconv.MakeStringToTTConvFactory().
+		WithNullValues("", "NULL").
+		WithTypeNullValues(tupleconv.TypeString, `\N`).
+		WithFieldNullValues("comment", "n/a", "-")
+
+	converters, err := tupleconv.MakeTypeToTTConverters[string](fac, spaceFmt)
+	require.NoError(t, err)
+	mapper := tupleconv.MakeMapper(converters)
+
+	cases := []struct {
+		name     string
+		tuple    []string
+		expected []any
+		isErr    bool
+	}{
+		{
+			name:     "values",
+			tuple:    []string{"1", "2.5", "a", "b", "c", "-3"},
+			expected: []any{uint64(1), 2.5, "a", "b", "c", int64(-3)},
+		},
+		{
+			name:     "empty",
+			tuple:    []string{"1", "", "", "", "", ""},
+			expected: []any{uint64(1), nil, "", "", "", nil},
+		},
+		{
+			name:     "tokens",
+			tuple:    []string{"1", "NULL", `\N`, `\N`, "n/a", "NULL"},
+			expected: []any{uint64(1), nil, nil, `\N`, nil, nil},
+		},
+		{
+			name:     "field tokens override type tokens",
+			tuple:    []string{"1", "", "NULL", "NULL", `\N`, ""},
+			expected: []any{uint64(1), nil, "NULL", "NULL", `\N`, nil},
+		},
+		{
+			name:     "field tokens",
+			tuple:    []string{"1", "", "", "", "-", ""},
+			expected: []any{uint64(1), nil, "", "", nil, nil},
+		},
+		{
+			name:  "type token on numeric field",
+			tuple: []string{"1", `\N`},
+			isErr: true,
+		},
+		{
+			name:  "not nullable",
+			tuple: []string{"NULL"},
+			isErr: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := mapper.Map(tc.tuple)
+			if tc.isErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expected, result)
+			}
+		})
+	}
+}
+
+func TestStringToTTConvFactory_nullValuesCopied(t *testing.T) {
+	spaceFmt := []tupleconv.SpaceField{
+		{Name: "a", Type: tupleconv.TypeString, IsNullable: true},
+		{Name: "b", Type: tupleconv.TypeUnsigned, IsNullable: true},
+		{Name: "c", Type: tupleconv.TypeDouble, IsNullable: true},
+	}
+	nullValues, typeNullValues, fieldNullValues := []string{"N"}, []string{"T"}, []string{"F"}
+	fac := tupleconv.MakeStringToTTConvFactory().
+		WithNullValues(nullValues...).
+		WithTypeNullValues(tupleconv.TypeString, typeNullValues...).
+		WithFieldNullValues("b", fieldNullValues...)
+	nullValues[0], typeNullValues[0], fieldNullValues[0] = "x", "x", "x"
+
+	converters, err := tupleconv.MakeTypeToTTConverters[string](fac, spaceFmt)
+	require.NoError(t, err)
+	result, err := tupleconv.MakeMapper(converters).Map([]string{"T", "F", "N"})
+	require.NoError(t, err)
+	assert.Equal(t, []any{nil, nil, nil}, result)
+}
+
+func TestStringToTTConvFactory_emptyString(t *testing.T) {
+	spaceFmt := []tupleconv.SpaceField{
+		{Type: tupleconv.TypeString},
+		{Type: tupleconv.TypeString, IsNullable: true},
+	}
+	fac := tupleconv.MakeStringToTTConvFactory()
+	converters, err := tupleconv.MakeTypeToTTConverters[string](fac, spaceFmt)
+	require.NoError(t, err)
+	result, err := tupleconv.MakeMapper(converters).Map([]string{"", ""})
+	assert.NoError(t, err)
+	assert.Equal(t, []any{"", nil}, result)
+}
+
+// nilFactory is a factory, that overrides MakeNullableConverter.
+type nilFactory struct {
+	tupleconv.StringToTTConvFactory
+}
+
+func (fac nilFactory) MakeNullableConverter(
+	converter tupleconv.Converter[string, any]) tupleconv.Converter[string, any] {
+	return tupleconv.MakeFuncConverter(func(src string) (any, error) {
+		if src == "NIL" {
+			return nil, nil
+		}
+		return converter.Convert(src)
+	})
+}
+
+func TestStringToTTConvFactory_embeddedNullable(t *testing.T) {
+	spaceFmt := []tupleconv.SpaceField{
+		{Name: "id", Type: tupleconv.TypeUnsigned, IsNullable: true},
+		{Name: "name", Type: tupleconv.TypeString, IsNullable: true},
+	}
+	normalized := tupleconv.MakeStringToTTConvFactory().
+		WithNormalization(tupleconv.NormalizeOptions{Trim: true})
+	for _, fac := range []tupleconv.TTConvFactory[string]{
+		nilFactory{},
+		&nilFactory{},
+		nilFactory{normalized},
+	} {
+		converters, err := tupleconv.MakeTypeToTTConverters[string](fac, spaceFmt)
+		require.NoError(t, err)
+		mapper := tupleconv.MakeMapper(converters)
+
+		result, err := mapper.Map([]string{"NIL", "NIL"})
+		require.NoError(t, err)
+		assert.Equal(t, []any{nil, nil}, result)
+
+		result, err = mapper.Map([]string{"1", ""})
+		require.NoError(t, err)
+		assert.Equal(t, []any{uint64(1), ""}, result)
+	}
+
+	// Normalization of the embedded factory is applied.
+	converters, err := tupleconv.MakeTypeToTTConverters[string](nilFactory{normalized},
+		spaceFmt)
+	require.NoError(t, err)
+	result, err := tupleconv.MakeMapper(converters).Map([]string{" NIL ", " a "})
+	require.NoError(t, err)
+	assert.Equal(t, []any{nil, "a"}, result)
+}
+
+func TestStringToTTConvFactory_datetimeLayouts(t *testing.T) {
+	fac := tupleconv.MakeStringToTTConvFactory().WithDatetimeLayouts("02.01.2006")
+	conv := fac.GetDatetimeConverter()
+
+	result, err := conv.Convert("30.08.2023")
+	require.NoError(t, err)
+	assert.Equal(t,
+		getDatetimeWithValidate(t, time.Date(2023, 8, 30, 0, 0, 0, 0, time.UTC)), result)
+
+	result, err = conv.Convert("2023-08-30T12:06:05.000-0000")
+	require.NoError(t, err)
+	assert.Equal(t,
+		getDatetimeWithValidate(t,
+			time.Date(2023, 8, 30, 12, 6, 5, 0, time.FixedZone("", 0))), result)
+
+	_, err = conv.Convert("08/30/2023")
+	assert.Error(t, err)
+
+	_, err = tupleconv.MakeStringToTTConvFactory().GetDatetimeConverter().Convert("30.08.2023")
+	assert.Error(t, err)
+	// Layouts are copied.
+	layouts := []string{"02.01.2006"}
+	fac = tupleconv.MakeStringToTTConvFactory().WithDatetimeLayouts(layouts...)
+	layouts[0] = "01/02/2006"
+	_, err = fac.GetDatetimeConverter().Convert("30.08.2023")
+	assert.NoError(t, err)
+}